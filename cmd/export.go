@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export notes to other formats",
+	Long: `Exports the notes of the current notebook for publishing or other tools.
+
+Use a subcommand for the output format.`,
+}
+
+var exportHTMLCmd = &cobra.Command{
+	Use:   "html <outdir>",
+	Short: "Export the notebook as a static website",
+	Long: `Renders the notebook as a static HTML site in <outdir>, ready for any
+static host:
+  - a page per note, at the note's path with .html instead of .md, listing
+    its tags and the published notes linking to it
+  - markdown links and [[wikilinks]] to notes pointing to their pages
+  - index.html listing every note, with a search box backed by
+    search-index.json
+  - a page per tag under _tags/ and a page per saved view under _views/
+  - the images and files notes link to
+
+Use --where to publish only the notes matching a filter query. Links to
+notes left out are shown as plain text and they don't appear in tag
+pages, views or backlinks. Encrypted notes are never published.
+
+Pages are rendered with Go html/template. .gohtml files in --templates
+(default .jot/html in the notebook directory) replace the built-in
+templates of the same name: layout.gohtml (the "header", "footer" and
+"notelist" blocks), index.gohtml, note.gohtml, tag.gohtml, tags.gohtml and
+view.gohtml. Other files there, such as style.css, are copied to the site
+root.
+
+Existing files in <outdir> are overwritten; others are left alone. The
+export fails when a note's page would be replaced by a generated page,
+such as the page of index.md by the site index.
+
+Examples:
+  # Publish the notebook
+  jot export html site/
+
+  # Leave out private notes
+  jot export html site/ --where '-tag:private'
+
+  # Use a custom look
+  jot export html site/ --templates theme/`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		where, _ := cmd.Flags().GetString("where")
+		templateDir, _ := cmd.Flags().GetString("templates")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		var opts services.HTMLExportOptions
+		if where != "" {
+			opts.Notes, err = queryNotes(cmd.Context(), nb, where, "", "")
+			if err != nil {
+				return err
+			}
+			if len(opts.Notes) == 0 {
+				return fmt.Errorf("no notes match the selection")
+			}
+		}
+		opts.TemplateDir = templateDir
+		if opts.TemplateDir == "" {
+			dir := filepath.Join(filepath.Dir(nb.Config.Path), services.HTMLTemplateDir)
+			if info, err := os.Stat(dir); err == nil && info.IsDir() {
+				opts.TemplateDir = dir
+			}
+		}
+		opts.Views, err = exportViews(cmd, nb)
+		if err != nil {
+			return err
+		}
+
+		result, err := nb.ExportHTML(cmd.Context(), args[0], opts)
+		if err != nil {
+			return err
+		}
+		if format == "json" {
+			return printJSON(result)
+		}
+		fmt.Printf("Exported %d notes, %d tags and %d views with %d attachments to %s\n",
+			result.Notes, result.Tags, result.Views, result.Attachments, result.OutDir)
+		if n := len(result.Encrypted); n > 0 {
+			fmt.Printf("Skipped %d encrypted notes\n", n)
+		}
+		return nil
+	},
+}
+
+// newRecordExportCmd builds "export jsonl" and "export csv", which differ
+// only in format.
+func newRecordExportCmd(format, about, example string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   format,
+		Short: "Export notes and their metadata as " + about,
+		Long: `Writes a record per note to stdout as ` + about + `, in path order, for
+jq, DuckDB, spreadsheets and other tools. Notes are read from the index in
+batches, so large notebooks don't have to fit in memory.
+
+--fields picks the fields and their order (default
+path,title,tags,created,modified). Besides frontmatter fields, these come
+from the index: path, id, title, tags, created, modified, lead and body.
+--body adds the note body. Use --where to export only the notes matching a
+filter query.
+
+Every record has the same fields with the same types: tags are arrays,
+created and modified are RFC3339 timestamps, and missing fields are
+null (empty in CSV). Frontmatter fields are typed by the notebook schema,
+so declare date and list fields there to get RFC3339 dates and arrays in
+every record. CSV cells hold arrays and objects as JSON.
+
+Examples:
+` + example,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			where, _ := cmd.Flags().GetString("where")
+			fields, _ := cmd.Flags().GetStringSlice("fields")
+			body, _ := cmd.Flags().GetBool("body")
+
+			nb, err := requireNotebook(cmd)
+			if err != nil {
+				return err
+			}
+
+			out := bufio.NewWriter(os.Stdout)
+			_, err = nb.ExportRecords(cmd.Context(), out, format, services.RecordExportOptions{
+				Where:  where,
+				Fields: fields,
+				Body:   body,
+			})
+			if flushErr := out.Flush(); err == nil {
+				err = flushErr
+			}
+			return err
+		},
+	}
+	cmd.Flags().String("where", "", "Export only the notes matching a filter query (e.g. 'tag:work')")
+	cmd.Flags().StringSlice("fields", nil, "Comma-separated fields to export (default path,title,tags,created,modified)")
+	cmd.Flags().Bool("body", false, "Include the note body")
+	return cmd
+}
+
+// exportViews runs the notebook's saved views for publishing, in name
+// order. Views that need parameters without defaults are left out with a
+// warning.
+func exportViews(cmd *cobra.Command, nb *services.Notebook) ([]services.HTMLView, error) {
+	vs := services.NewViewService(cfgService, filepath.Dir(nb.Config.Path))
+	vs.SetExecutionContext(nb.Notes.GetIndex(), nb.Notes)
+	infos, err := vs.LoadAllNotebookViews()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+	var views []services.HTMLView
+	for _, info := range infos {
+		view, err := vs.GetView(info.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get view '%s': %w", info.Name, err)
+		}
+		results, err := vs.ExecuteView(cmd.Context(), view, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: view '%s' was not exported: %v\n", info.Name, err)
+			continue
+		}
+
+		notes := results.Notes
+		groups := make([]string, 0, len(results.Groups))
+		for group := range results.Groups {
+			groups = append(groups, group)
+		}
+		sort.Strings(groups)
+		for _, group := range groups {
+			notes = append(notes, results.Groups[group]...)
+		}
+		views = append(views, services.HTMLView{Name: view.Name, Description: view.Description, Notes: notes})
+	}
+	return views, nil
+}
+
+func init() {
+	exportHTMLCmd.Flags().String("where", "", "Publish only the notes matching a filter query (e.g. '-tag:private')")
+	exportHTMLCmd.Flags().String("templates", "", "Directory of templates and files overriding the built-in ones")
+	exportHTMLCmd.Flags().String("format", "text", "Output format: text or json")
+
+	exportCmd.AddCommand(exportHTMLCmd)
+	exportCmd.AddCommand(newRecordExportCmd(services.RecordFormatJSONL, "JSON Lines", `  # Load open tasks into jq
+  jot export jsonl --where 'status:open' | jq -r .title
+
+  # Query with DuckDB
+  jot export jsonl --fields path,title,status,tags,created > notes.jsonl
+  duckdb -c "SELECT status, count(*) FROM 'notes.jsonl' GROUP BY status"`))
+	exportCmd.AddCommand(newRecordExportCmd(services.RecordFormatCSV, "CSV", `  # Open in a spreadsheet
+  jot export csv --fields title,status,tags,created > notes.csv
+
+  # Include the note bodies
+  jot export csv --where 'tag:meeting' --body > meetings.csv`))
+	rootCmd.AddCommand(exportCmd)
+}
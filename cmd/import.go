@@ -0,0 +1,370 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import notes from other tools",
+	Long: `Imports notes from other note-taking tools into a jot notebook.
+
+Use a subcommand for the source tool.`,
+}
+
+var importObsidianCmd = &cobra.Command{
+	Use:   "obsidian <vault>",
+	Short: "Import an Obsidian vault",
+	Long: `Converts an Obsidian vault into a jot notebook.
+
+Without --to the vault itself becomes the notebook: a .jot.json is created
+next to .obsidian and notes are rewritten in place. With --to a new
+notebook is created there and notes and attachments are copied into it,
+leaving the vault untouched.
+
+Each note is converted so jot reads it the way Obsidian did:
+  - inline #tags are added to the frontmatter "tags" list
+  - "alias" and comma separated "aliases" become an "aliases" list
+  - "title" is set from the file name, "created"/"modified" from the file
+    time, unless the frontmatter has them
+  - [[wikilinks]] jot would resolve to another note of the same name are
+    rewritten to the note's path, keeping the text shown
+  - ![[embeds]] of images and other attachments become markdown links,
+    found through the attachment folder set in .obsidian/app.json
+
+Hidden folders such as .obsidian and .trash are skipped. Use --dry-run to
+see the report without writing anything.
+
+Examples:
+  # Preview the conversion
+  jot import obsidian ~/vault --dry-run
+
+  # Convert the vault in place
+  jot import obsidian ~/vault
+
+  # Copy into a new notebook and register it
+  jot import obsidian ~/vault --to ~/notes --name "Team Notes" --register`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		target, _ := cmd.Flags().GetString("to")
+		name, _ := cmd.Flags().GetString("name")
+		register, _ := cmd.Flags().GetBool("register")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		plan, err := services.PlanObsidianImport(args[0])
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			if !dryRun {
+				if _, err := notebookService.ImportObsidian(plan, target, name, register); err != nil {
+					return err
+				}
+			}
+			return printJSON(plan)
+		}
+
+		printObsidianImport(plan)
+		if dryRun {
+			fmt.Println("\nDry run: nothing was written.")
+			return nil
+		}
+
+		nb, err := notebookService.ImportObsidian(plan, target, name, register)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nImported into notebook '%s'\n", nb.Config.Name)
+		fmt.Printf("  Config: %s\n", nb.Config.Path)
+		fmt.Printf("  Notes:  %s\n", nb.Config.Root)
+		return nil
+	},
+}
+
+// printObsidianImport prints the changes an import makes to each note and
+// a summary.
+func printObsidianImport(plan *services.ObsidianImport) {
+	folder := plan.AttachmentFolder
+	if folder == "" {
+		folder = "vault root"
+	}
+	fmt.Printf("Obsidian vault %s\n", plan.Vault)
+	fmt.Printf("  %d notes, %d attachments (attachment folder: %s)\n\n", len(plan.Notes), len(plan.Attachments), folder)
+
+	changed, links, unresolved := 0, 0, 0
+	for i := range plan.Notes {
+		note := &plan.Notes[i]
+		if !note.Changed() {
+			continue
+		}
+		changed++
+		links += note.Links
+		unresolved += len(note.Unresolved)
+
+		var parts []string
+		if len(note.Fields) > 0 {
+			parts = append(parts, "set "+strings.Join(note.Fields, ", "))
+		}
+		if len(note.Tags) > 0 {
+			parts = append(parts, "tags #"+strings.Join(note.Tags, " #"))
+		}
+		if note.Links > 0 {
+			parts = append(parts, fmt.Sprintf("%d links rewritten", note.Links))
+		}
+		if note.Embeds > 0 {
+			parts = append(parts, fmt.Sprintf("%d attachment links", note.Embeds))
+		}
+		fmt.Printf("%s: %s\n", note.Path, strings.Join(parts, "; "))
+		for _, target := range note.Unresolved {
+			fmt.Printf("  ⚠️  unresolved link [[%s]]\n", target)
+		}
+	}
+	for _, warning := range plan.Warnings {
+		fmt.Printf("⚠️  %s\n", warning)
+	}
+	if changed == 0 {
+		fmt.Println("No notes need converting.")
+	}
+	fmt.Printf("\n%d of %d notes converted, %d links rewritten, %d unresolved\n", changed, len(plan.Notes), links, unresolved)
+}
+
+var importENEXCmd = &cobra.Command{
+	Use:   "enex <file>",
+	Short: "Import an Evernote export (.enex)",
+	Long: `Imports the notes of an Evernote export into the current notebook.
+
+Each note is converted from Evernote's XHTML to markdown, with:
+  - its Evernote tags in the frontmatter "tags" list
+  - "created" and "modified" from the Evernote timestamps
+  - "source_url" and "author" when Evernote recorded them
+  - embedded images and files extracted to a "<note>.resources" folder
+    next to the note and linked from where they appeared
+  - a "source_id" field identifying the Evernote note
+
+Importing the same export again is safe: notes whose source_id is already
+in the notebook are left alone, or rewritten with --update.
+
+Note paths come from a text/template pattern, --path or "import.enex_path"
+in .jot.json, defaulting to:
+  evernote/{{ slug .Notebook }}/{{ .Slug }}.md
+
+Available fields: .Title, .Slug, .Notebook (the export file name without
+.enex), .Date (creation date, YYYY-MM-DD), .Year, .Month, .Day. The "slug"
+function slugifies any of them. Paths already taken get a -2, -3 suffix.
+
+Examples:
+  # Preview the import
+  jot import enex Work.enex --dry-run
+
+  # Import, grouping notes by year
+  jot import enex Work.enex --path "evernote/{{ .Year }}/{{ .Slug }}.md"
+
+  # Import a newer export of the same notebook
+  jot import enex Work.enex --update`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pattern, _ := cmd.Flags().GetString("path")
+		update, _ := cmd.Flags().GetBool("update")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		plan, err := nb.PlanENEXImport(cmd.Context(), args[0], services.ENEXImportOptions{PathPattern: pattern, Update: update})
+		if err != nil {
+			return err
+		}
+		if !dryRun {
+			if err := nb.ApplyENEXImport(cmd.Context(), plan); err != nil {
+				return err
+			}
+		}
+
+		if format == "json" {
+			return printJSON(plan)
+		}
+		printENEXImport(plan, dryRun)
+		return nil
+	},
+}
+
+// printENEXImport prints what an Evernote import did, or would do.
+func printENEXImport(plan *services.ENEXImport, dryRun bool) {
+	counts := make(map[string]int)
+	for _, note := range plan.Notes {
+		counts[note.Status]++
+		line := fmt.Sprintf("%-9s %s", note.Status, note.Path)
+		if n := len(note.Attachments); n > 0 && (note.Status == services.ImportNew || note.Status == services.ImportUpdated) {
+			line += fmt.Sprintf(" (%d attachments)", n)
+		}
+		fmt.Println(line)
+		for _, warning := range note.Warnings {
+			fmt.Printf("  ⚠️  %s\n", warning)
+		}
+	}
+
+	fmt.Printf("\n%d notes in %s: %d new, %d updated, %d unchanged, %d skipped\n", len(plan.Notes), plan.File,
+		counts[services.ImportNew], counts[services.ImportUpdated], counts[services.ImportUnchanged], counts[services.ImportSkipped])
+	if counts[services.ImportSkipped] > 0 {
+		fmt.Println("Skipped notes were imported before and have changed; use --update to overwrite them.")
+	}
+	if dryRun {
+		fmt.Println("Dry run: nothing was written.")
+	}
+}
+
+// newRowImportCmd builds "import csv" and "import json", which differ only
+// in how rows are read.
+func newRowImportCmd(format, about, example string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   format + " <file>",
+		Short: fmt.Sprintf("Create a note per %s row", strings.ToUpper(format)),
+		Long: `Creates a note in the current notebook for each row of ` + about + `
+
+Every non-empty value becomes a frontmatter field. Text cells are typed
+like --data values: "2" is stored as a number and "true" as a boolean,
+except numbers with leading zeros such as zip codes. The --title-column
+(default "title") gives the note title.
+
+The body of new notes comes from --template, rendered like "notes add"
+with the row's values in {{ .Data }}, or from the template of the groups
+matching the path.
+
+--path is a template for the path of new notes, with every column by name
+plus .slug (the slugified title), .title and .row (the row number), and a
+"slug" function. Columns whose names aren't identifiers are reached with
+{{ index . "First Name" }}. Rows whose path already exists are skipped.
+
+With --key, a column identifies notes across imports: a row whose value is
+already in that frontmatter field of a note updates the note's fields in
+place, keeping its body and path, and other rows create notes. Empty cells
+keep the note's value.
+
+Use --dry-run to see the plan and the changes to existing notes.
+
+` + example,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts services.RowImportOptions
+			opts.Template, _ = cmd.Flags().GetString("template")
+			opts.PathPattern, _ = cmd.Flags().GetString("path")
+			opts.TitleColumn, _ = cmd.Flags().GetString("title-column")
+			opts.Key, _ = cmd.Flags().GetString("key")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			output, _ := cmd.Flags().GetString("format")
+			if output != "text" && output != "json" {
+				return fmt.Errorf("unknown format %q (expected text or json)", output)
+			}
+
+			nb, err := requireNotebook(cmd)
+			if err != nil {
+				return err
+			}
+
+			rows, err := services.ReadRows(args[0], format)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", args[0], err)
+			}
+			plan, err := nb.PlanRowImport(cmd.Context(), args[0], rows, opts)
+			if err != nil {
+				return err
+			}
+			for _, note := range plan.Notes {
+				if len(note.Violations) > 0 {
+					return fmt.Errorf("row %d: %w", note.Row, schemaError(note.Path, note.Violations))
+				}
+			}
+
+			if !dryRun {
+				if err := nb.ApplyRowImport(cmd.Context(), plan); err != nil {
+					return err
+				}
+			}
+			if output == "json" {
+				return printJSON(plan)
+			}
+			printRowImport(plan, dryRun)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("template", "t", "", "Note template for the body of new notes")
+	cmd.Flags().String("path", services.DefaultRowPath, "Path pattern for new notes")
+	cmd.Flags().String("title-column", "title", "Column holding the note title")
+	cmd.Flags().String("key", "", "Column identifying notes, to update them in place on re-import")
+	cmd.Flags().Bool("dry-run", false, "Report the import without writing anything")
+	cmd.Flags().String("format", "text", "Output format: text or json")
+	return cmd
+}
+
+// printRowImport prints what a row import did, or would do. Dry runs show
+// the changes to existing notes.
+func printRowImport(plan *services.RowImport, dryRun bool) {
+	counts := make(map[string]int)
+	var changes []*services.NoteChange
+	for _, note := range plan.Notes {
+		counts[note.Status]++
+		line := fmt.Sprintf("row %-4d %-9s %s", note.Row, note.Status, note.Path)
+		if note.Reason != "" {
+			line += " (" + note.Reason + ")"
+		}
+		fmt.Println(line)
+		if note.Status == services.ImportUpdated {
+			changes = append(changes, note.Change)
+		}
+	}
+	if dryRun {
+		printChanges(changes)
+	}
+
+	fmt.Printf("\n%d rows in %s: %d new, %d updated, %d unchanged, %d skipped\n", len(plan.Notes), plan.File,
+		counts[services.ImportNew], counts[services.ImportUpdated], counts[services.ImportUnchanged], counts[services.ImportSkipped])
+	if dryRun {
+		fmt.Println("Dry run: nothing was written.")
+	}
+}
+
+func init() {
+	importObsidianCmd.Flags().String("to", "", "Create a new notebook in this directory instead of converting the vault in place")
+	importObsidianCmd.Flags().StringP("name", "n", "", "Notebook name (default: the vault folder name)")
+	importObsidianCmd.Flags().BoolP("register", "r", false, "Register the notebook globally")
+	importObsidianCmd.Flags().Bool("dry-run", false, "Report the conversion without writing anything")
+	importObsidianCmd.Flags().String("format", "text", "Output format: text or json")
+
+	importENEXCmd.Flags().String("path", "", "Note path pattern (default: import.enex_path in .jot.json, else "+services.DefaultENEXPath+")")
+	importENEXCmd.Flags().Bool("update", false, "Rewrite notes imported before whose content changed")
+	importENEXCmd.Flags().Bool("dry-run", false, "Report the import without writing anything")
+	importENEXCmd.Flags().String("format", "text", "Output format: text or json")
+
+	importCmd.AddCommand(importObsidianCmd)
+	importCmd.AddCommand(importENEXCmd)
+	importCmd.AddCommand(newRowImportCmd(services.RowFormatCSV,
+		"a CSV file. The first line names the columns.", `Examples:
+  # One note per customer, keyed on the customer id for later updates
+  jot import csv customers.csv --template customer \
+    --path "customers/{{ .slug }}.md" --title-column name --key customer_id
+
+  # Preview
+  jot import csv customers.csv --key customer_id --dry-run`))
+	importCmd.AddCommand(newRowImportCmd(services.RowFormatJSON,
+		"a JSON file holding an array of objects. Nested values are stored as they are.", `Examples:
+  # One note per incident, grouped by year
+  jot import json incidents.json --template incident \
+    --path "incidents/{{ .year }}/{{ .id }}-{{ .slug }}.md" --key id`))
+	rootCmd.AddCommand(importCmd)
+}
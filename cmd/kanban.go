@@ -0,0 +1,216 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+	"golang.org/x/term"
+)
+
+var kanbanCmd = &cobra.Command{
+	Use:   "kanban",
+	Short: "Show a grouped view as a kanban board",
+	Long: `Shows the notes of a grouped view as kanban columns, one per group. The
+"kanban" view (has:status | group:status) is used unless --view names
+another view with a group: directive.
+
+In a terminal the board is interactive; moving a card rewrites the
+grouped field of its note. Elsewhere, or with --format, it is printed.
+
+Keys:
+  left/right, h/l            Select a column
+  up/down, k/j               Select a card
+  shift+left/right, </>      Move the card to the previous/next column
+  enter                      Open the note in $VISUAL or $EDITOR
+  r                          Reload
+  q, esc, ctrl+c             Quit
+
+Column order and WIP limits come from the "board" setting of the view or
+of .jot.json; a view's columns replace the notebook's and its limits
+override the notebook's per column:
+
+  "board": {
+    "columns": ["todo", "doing", "done"],
+    "wip_limits": {"doing": 3}
+  }
+
+Without configured columns, the enum of the field's schema orders them.
+Groups not listed follow, sorted by name.
+
+Examples:
+  jot kanban
+  jot kanban --view sprint --param sprint=41
+  jot kanban --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		switch format {
+		case "", "list", "json":
+		default:
+			return fmt.Errorf("unknown format %q (expected list or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		source, err := newNotebookBoardSource(cmd, nb)
+		if err != nil {
+			return err
+		}
+
+		interactive := term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+		if format != "" || !interactive {
+			board, err := source.Load(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if format == "json" {
+				return printJSON(board)
+			}
+			printBoard(board)
+			return nil
+		}
+
+		model := newBoardModel(cmd.Context(), source, func(relPath string) tea.Cmd {
+			return tea.Exec(&browseEditor{run: func() error { return editNote(cmd, nb, relPath) }},
+				func(err error) tea.Msg { return browseEditedMsg{path: relPath, err: err} })
+		})
+		_, err = tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+		return err
+	},
+}
+
+var kanbanMoveCmd = &cobra.Command{
+	Use:   "move <note> <column>",
+	Short: "Move a note to another column of the board",
+	Long: `Moves a note to a column of the kanban board by setting the board's
+grouped field ("status" for the kanban view) and stamping "modified".
+
+The note is resolved like "jot notes edit". The column is matched
+case-insensitively against the board; a new value starts a new column.
+Moving into a column at its WIP limit fails unless --force is given.
+
+Examples:
+  jot kanban move "Launch plan" doing
+  jot kanban move projects/launch.md done --dry-run
+  jot kanban move launch.md review --view sprint --force`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		source, err := newNotebookBoardSource(cmd, nb)
+		if err != nil {
+			return err
+		}
+		board, err := source.Load(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		note, err := resolveNoteArg(cmd, nb, args[0])
+		if err != nil {
+			return err
+		}
+
+		return updateNotes(cmd, nb, []services.Note{note}, func(note services.Note) (*services.NoteChange, error) {
+			return nb.Notes.PlanBoardMove(board, note.File.Relative, args[1], force)
+		})
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{kanbanCmd, kanbanMoveCmd} {
+		c.Flags().String("view", services.DefaultBoardView, "View to show as a board (needs a group: directive)")
+		c.Flags().String("param", "", "View parameters (key=value,key2=value2)")
+	}
+	kanbanCmd.Flags().String("format", "", "Print the board instead: list or json")
+	kanbanMoveCmd.Flags().Bool("force", false, "Move even when the column is at its WIP limit")
+	kanbanMoveCmd.Flags().Bool("dry-run", false, "Show the change without writing it")
+
+	kanbanCmd.AddCommand(kanbanMoveCmd)
+	rootCmd.AddCommand(kanbanCmd)
+}
+
+// printBoard prints the columns of a board with their cards.
+func printBoard(board *services.Board) {
+	fmt.Printf("Board '%s' (grouped by %s):\n", board.View, board.Field)
+	for _, column := range board.Columns {
+		count := fmt.Sprint(len(column.Notes))
+		if column.Limit > 0 {
+			count = fmt.Sprintf("%d/%d", len(column.Notes), column.Limit)
+		}
+		if column.OverLimit() {
+			count += ", over WIP limit"
+		}
+		fmt.Printf("\n## %s (%s)\n", column.Name, count)
+		for _, note := range column.Notes {
+			fmt.Printf("  - %s\n", note.DisplayName())
+			fmt.Printf("    Path: %s\n", note.File.Relative)
+		}
+	}
+}
+
+// notebookBoardSource loads a view of a notebook as a board and writes
+// card moves back to the notes.
+type notebookBoardSource struct {
+	nb     *services.Notebook
+	views  *services.ViewService
+	view   string
+	params map[string]string
+}
+
+func newNotebookBoardSource(cmd *cobra.Command, nb *services.Notebook) (*notebookBoardSource, error) {
+	viewName, _ := cmd.Flags().GetString("view")
+	paramStr, _ := cmd.Flags().GetString("param")
+
+	views := services.NewViewService(cfgService, filepath.Dir(nb.Config.Path))
+	views.SetExecutionContext(nb.Notes.GetIndex(), nb.Notes)
+
+	params, err := views.ParseViewParameters(paramStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+	return &notebookBoardSource{nb: nb, views: views, view: viewName, params: params}, nil
+}
+
+// Load runs the view and lays out its groups as columns.
+func (s *notebookBoardSource) Load(ctx context.Context) (*services.Board, error) {
+	view, err := s.views.GetView(s.view)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get view '%s': %w", s.view, err)
+	}
+	results, err := s.views.ExecuteView(ctx, view, s.params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute view '%s': %w", s.view, err)
+	}
+	return services.BuildBoard(&s.nb.Config.StoredNotebookConfig, view, results)
+}
+
+// Move sets the board field of the note at relPath to column, refusing
+// moves past a WIP limit or against the notebook schema.
+func (s *notebookBoardSource) Move(ctx context.Context, board *services.Board, relPath, column string) error {
+	change, err := s.nb.Notes.PlanBoardMove(board, relPath, column, false)
+	if err != nil || change == nil {
+		return err
+	}
+	violations := services.NewViolations(
+		s.nb.Config.ValidateContent(change.OldPath, []byte(change.Before)),
+		s.nb.Config.ValidateContent(change.Path, []byte(change.After)),
+	)
+	if len(violations) > 0 {
+		return schemaError(change.Path, violations)
+	}
+	return s.nb.Notes.ApplyChange(ctx, change)
+}
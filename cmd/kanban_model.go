@@ -0,0 +1,273 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+// boardSource loads the board shown by "jot kanban" and writes card moves
+// back. The notebook implements it; tests use a stub.
+type boardSource interface {
+	// Load builds the board from the current notes.
+	Load(ctx context.Context) (*services.Board, error)
+	// Move moves the note at relPath to column.
+	Move(ctx context.Context, board *services.Board, relPath, column string) error
+}
+
+// Messages produced by the board's commands.
+type (
+	boardLoadedMsg struct {
+		board *services.Board
+		focus string // path of the card to select, if any
+		err   error
+	}
+	boardMovedMsg struct {
+		path   string
+		column string
+		err    error
+	}
+)
+
+// boardMinColumnWidth is the narrowest a column is drawn; columns that do
+// not fit scroll horizontally.
+const boardMinColumnWidth = 24
+
+// boardModel is the bubbletea model of the interactive kanban board.
+type boardModel struct {
+	ctx    context.Context
+	source boardSource
+	// edit opens a note in the editor and reports a browseEditedMsg.
+	edit func(relPath string) tea.Cmd
+
+	board    *services.Board
+	col, row int
+
+	width, height int
+	status        string
+}
+
+func newBoardModel(ctx context.Context, source boardSource, edit func(string) tea.Cmd) *boardModel {
+	return &boardModel{ctx: ctx, source: source, edit: edit, width: 80, height: 24}
+}
+
+// Init loads the board.
+func (m *boardModel) Init() tea.Cmd {
+	return m.load("")
+}
+
+// Update handles a message and returns the commands it triggers.
+func (m *boardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case boardLoadedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.board = msg.board
+		m.focus(msg.focus)
+		return m, nil
+
+	case boardMovedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.status = fmt.Sprintf("Moved %s to %s", msg.path, msg.column)
+		return m, m.load(msg.path)
+
+	case browseEditedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		} else {
+			m.status = "Saved " + msg.path
+		}
+		return m, m.load(msg.path)
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *boardModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		return m, tea.Quit
+	case "left", "h":
+		m.selectColumn(m.col - 1)
+	case "right", "l":
+		m.selectColumn(m.col + 1)
+	case "up", "k":
+		m.row = max(m.row-1, 0)
+	case "down", "j":
+		if column := m.column(); column != nil {
+			m.row = min(m.row+1, max(len(column.Notes)-1, 0))
+		}
+	case "shift+left", "<", "H":
+		return m, m.moveCard(-1)
+	case "shift+right", ">", "L":
+		return m, m.moveCard(1)
+	case "enter":
+		if note := m.selected(); note != nil {
+			return m, m.edit(note.File.Relative)
+		}
+	case "r":
+		if note := m.selected(); note != nil {
+			return m, m.load(note.File.Relative)
+		}
+		return m, m.load("")
+	}
+	return m, nil
+}
+
+// load rebuilds the board in the background, then selects focus.
+func (m *boardModel) load(focus string) tea.Cmd {
+	return func() tea.Msg {
+		board, err := m.source.Load(m.ctx)
+		return boardLoadedMsg{board: board, focus: focus, err: err}
+	}
+}
+
+// moveCard moves the selected card delta columns to the left or right.
+func (m *boardModel) moveCard(delta int) tea.Cmd {
+	note := m.selected()
+	target := m.col + delta
+	if note == nil || target < 0 || target >= len(m.board.Columns) {
+		return nil
+	}
+
+	board, path, column := m.board, note.File.Relative, m.board.Columns[target].Name
+	return func() tea.Msg {
+		err := m.source.Move(m.ctx, board, path, column)
+		return boardMovedMsg{path: path, column: column, err: err}
+	}
+}
+
+// focus selects the card of the note at relPath, or keeps the cursor in
+// range when it is not on the board.
+func (m *boardModel) focus(relPath string) {
+	if relPath != "" {
+		for c, column := range m.board.Columns {
+			for r, note := range column.Notes {
+				if note.File.Relative == relPath {
+					m.col, m.row = c, r
+					return
+				}
+			}
+		}
+	}
+	m.selectColumn(m.col)
+}
+
+func (m *boardModel) selectColumn(col int) {
+	if m.board == nil || len(m.board.Columns) == 0 {
+		m.col, m.row = 0, 0
+		return
+	}
+	m.col = min(max(col, 0), len(m.board.Columns)-1)
+	m.row = min(m.row, max(len(m.board.Columns[m.col].Notes)-1, 0))
+}
+
+func (m *boardModel) column() *services.BoardColumn {
+	if m.board == nil || m.col >= len(m.board.Columns) {
+		return nil
+	}
+	return &m.board.Columns[m.col]
+}
+
+func (m *boardModel) selected() *services.Note {
+	column := m.column()
+	if column == nil || m.row >= len(column.Notes) {
+		return nil
+	}
+	return &column.Notes[m.row]
+}
+
+var boardOverLimitStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("1"))
+
+// View renders the visible columns side by side and a status line.
+func (m *boardModel) View() string {
+	if m.board == nil {
+		if m.status != "" {
+			return m.status
+		}
+		return "Loading…"
+	}
+
+	var b strings.Builder
+	b.WriteString(browseHeaderStyle.Render(fmt.Sprintf("Board '%s'", m.board.View)))
+	b.WriteString("  " + browseMutedStyle.Render("grouped by "+m.board.Field) + "\n")
+
+	first, count := m.visibleColumns()
+	width := max(m.width/max(count, 1)-3, 1)
+	height := max(m.height-3, 2)
+
+	var panes []string
+	for i := first; i < first+count; i++ {
+		if i > first {
+			panes = append(panes, strings.TrimSuffix(strings.Repeat(" │ \n", height), "\n"))
+		}
+		panes = append(panes, lipgloss.NewStyle().Width(width).MaxWidth(width).Height(height).MaxHeight(height).
+			Render(m.renderColumn(i, width, height)))
+	}
+	if len(panes) == 0 {
+		panes = append(panes, browseMutedStyle.Render("No columns"))
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, panes...) + "\n")
+
+	status := m.status
+	if status == "" {
+		status = "←/→ column · ↑/↓ card · shift+←/→ move · enter edit · r reload · q quit"
+	}
+	b.WriteString(browseMutedStyle.Render(status))
+	return b.String()
+}
+
+// visibleColumns returns the first column drawn and how many fit, keeping
+// the selected column in view.
+func (m *boardModel) visibleColumns() (first, count int) {
+	total := len(m.board.Columns)
+	count = min(max(m.width/boardMinColumnWidth, 1), total)
+	first = min(max(m.col-count+1, 0), total-count)
+	return first, count
+}
+
+func (m *boardModel) renderColumn(i, width, height int) string {
+	column := m.board.Columns[i]
+
+	header := fmt.Sprintf("%s (%d)", column.Name, len(column.Notes))
+	if column.Limit > 0 {
+		header = fmt.Sprintf("%s (%d/%d)", column.Name, len(column.Notes), column.Limit)
+	}
+	header = truncateRunes(header, width)
+	if column.OverLimit() {
+		header = boardOverLimitStyle.Render(header)
+	} else {
+		header = browseHeaderStyle.Render(header)
+	}
+
+	lines := []string{header}
+	rows := height - 1
+	start := 0
+	if i == m.col {
+		start = max(m.row-rows+1, 0)
+	}
+	for r := start; r < min(start+rows, len(column.Notes)); r++ {
+		line := truncateRunes(column.Notes[r].DisplayName(), width)
+		if i == m.col && r == m.row {
+			line = browseSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+// stubBoardSource keeps each note's column in memory and rebuilds the
+// board from it on every load.
+type stubBoardSource struct {
+	order   []string
+	columns map[string]string // path -> column
+	limits  map[string]int
+	moves   []string
+}
+
+func (s *stubBoardSource) Load(context.Context) (*services.Board, error) {
+	board := &services.Board{View: "kanban", Field: "status"}
+	for _, name := range []string{"todo", "doing", "done"} {
+		column := services.BoardColumn{Name: name, Limit: s.limits[name]}
+		for _, path := range s.order {
+			if s.columns[path] == name {
+				column.Notes = append(column.Notes, boardCard(path))
+			}
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	return board, nil
+}
+
+func (s *stubBoardSource) Move(_ context.Context, board *services.Board, relPath, column string) error {
+	name, err := board.CheckMove(relPath, column, false)
+	if err != nil {
+		return err
+	}
+	s.moves = append(s.moves, relPath+"->"+name)
+	s.columns[relPath] = name
+	return nil
+}
+
+func boardCard(path string) services.Note {
+	var note services.Note
+	note.File.Relative = path
+	note.Metadata = map[string]any{"title": "Card " + path}
+	return note
+}
+
+func newStubBoard() (*boardModel, *stubBoardSource) {
+	source := &stubBoardSource{
+		order:   []string{"a.md", "b.md", "c.md"},
+		columns: map[string]string{"a.md": "todo", "b.md": "todo", "c.md": "doing"},
+		limits:  map[string]int{"doing": 2},
+	}
+	return newBoardModel(context.Background(), source, func(relPath string) tea.Cmd {
+		return func() tea.Msg { return browseEditedMsg{path: relPath} }
+	}), source
+}
+
+// runBoard feeds msg to the board and then every message its commands
+// produce, like the bubbletea event loop.
+func runBoard(t *testing.T, m *boardModel, msg tea.Msg) {
+	t.Helper()
+	_, cmd := m.Update(msg)
+	for cmd != nil {
+		_, cmd = m.Update(cmd())
+	}
+}
+
+func boardKey(s string) tea.KeyMsg {
+	switch s {
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "right":
+		return tea.KeyMsg{Type: tea.KeyRight}
+	case "shift+right":
+		return tea.KeyMsg{Type: tea.KeyShiftRight}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestBoardModel_MoveCards(t *testing.T) {
+	m, source := newStubBoard()
+	runBoard(t, m, m.Init()())
+
+	require.NotNil(t, m.board)
+	assert.Equal(t, "a.md", m.selected().File.Relative)
+
+	// Move the second todo card right; the cursor follows it
+	runBoard(t, m, boardKey("down"))
+	runBoard(t, m, boardKey("shift+right"))
+	assert.Equal(t, []string{"b.md->doing"}, source.moves)
+	assert.Equal(t, 1, m.col)
+	assert.Equal(t, "b.md", m.selected().File.Relative)
+	assert.Equal(t, "Moved b.md to doing", m.status)
+
+	// "doing" is now at its WIP limit of 2
+	runBoard(t, m, boardKey("h"))
+	runBoard(t, m, boardKey(">"))
+	assert.Equal(t, `column "doing" is at its WIP limit of 2`, m.status)
+	assert.Len(t, source.moves, 1)
+
+	// Moving left from the first column does nothing
+	runBoard(t, m, boardKey("<"))
+	assert.Len(t, source.moves, 1)
+
+	runBoard(t, m, boardKey("l"))
+	runBoard(t, m, boardKey("right"))
+	assert.Equal(t, 2, m.col)
+	assert.Nil(t, m.selected(), "the done column is empty")
+
+	runBoard(t, m, boardKey("h"))
+	runBoard(t, m, boardKey("enter"))
+	assert.Equal(t, "Saved b.md", m.status)
+
+	_, cmd := m.Update(boardKey("q"))
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
+
+func TestBoardModel_View(t *testing.T) {
+	m, _ := newStubBoard()
+	assert.Equal(t, "Loading…", m.View())
+
+	runBoard(t, m, m.Init()())
+	runBoard(t, m, tea.WindowSizeMsg{Width: 90, Height: 10})
+
+	out := m.View()
+	assert.Contains(t, out, "Board 'kanban'")
+	assert.Contains(t, out, "todo (2)")
+	assert.Contains(t, out, "doing (1/2)")
+	assert.Contains(t, out, "done (0)")
+	assert.Contains(t, out, "Card a.md")
+	assert.Contains(t, out, "shift+←/→ move")
+
+	// Only two 24-column panes fit in 50 columns; the selection stays in view
+	runBoard(t, m, tea.WindowSizeMsg{Width: 50, Height: 10})
+	runBoard(t, m, boardKey("l"))
+	runBoard(t, m, boardKey("l"))
+	out = m.View()
+	assert.NotContains(t, out, "todo (2)")
+	assert.Contains(t, out, "done (0)")
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the notebook for broken links and frontmatter problems",
+	Long: `Checks every note and reports findings with a rule, severity, file and
+line. Exits with an error when any finding is at least as severe as
+--fail-on, so lint can gate a docs repository in CI.
+
+Rules:
+` + lintRulesHelp() + `
+Rules marked (fixable) are resolved by --fix, which rewrites frontmatter
+without stamping "modified". Preview fixes with --fix --dry-run.
+
+Formats:
+  text   path:line: severity rule: message
+  json   {"findings": [...]} with rule, severity, path, line and message
+  sarif  SARIF 2.1.0 for code scanning, paths relative to the notebook directory
+
+Examples:
+  jot lint
+  jot lint --rule broken-link --rule missing-title
+  jot lint --fix
+  jot lint --format sarif > jot.sarif
+  jot lint --fail-on error`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		rules, _ := cmd.Flags().GetStringArray("rule")
+		fix, _ := cmd.Flags().GetBool("fix")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		failOn, _ := cmd.Flags().GetString("fail-on")
+
+		if format != "text" && format != "json" && format != "sarif" {
+			return fmt.Errorf("unknown format %q (expected text, json or sarif)", format)
+		}
+		if failOn != "never" && services.LintSeverityRank(failOn) == 0 {
+			return fmt.Errorf("unknown --fail-on %q (expected error, warning, info or never)", failOn)
+		}
+		if dryRun && !fix {
+			return fmt.Errorf("--dry-run requires --fix")
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		findings, err := nb.Lint(cmd.Context(), rules)
+		if err != nil {
+			return err
+		}
+
+		if fix {
+			changes, err := nb.PlanLintFixes(findings)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Printf("Would fix %d note(s)\n", len(changes))
+				printChanges(changes)
+				return nil
+			}
+			for _, change := range changes {
+				if err := nb.Notes.ApplyChange(cmd.Context(), change); err != nil {
+					return err
+				}
+			}
+			if len(changes) > 0 {
+				fmt.Fprintf(os.Stderr, "Fixed %d note(s)\n", len(changes))
+				if findings, err = nb.Lint(cmd.Context(), rules); err != nil {
+					return err
+				}
+			}
+		}
+
+		switch format {
+		case "json":
+			if findings == nil {
+				findings = []services.LintFinding{}
+			}
+			if err := printJSON(map[string]any{"findings": findings}); err != nil {
+				return err
+			}
+		case "sarif":
+			if err := printJSON(lintSARIF(nb, findings)); err != nil {
+				return err
+			}
+		default:
+			printLintText(findings)
+		}
+
+		failing := 0
+		for _, finding := range findings {
+			if failOn != "never" && services.LintSeverityRank(finding.Severity) >= services.LintSeverityRank(failOn) {
+				failing++
+			}
+		}
+		if failing > 0 {
+			return fmt.Errorf("%d lint finding(s) at or above %s", failing, failOn)
+		}
+		return nil
+	},
+}
+
+func init() {
+	lintCmd.Flags().String("format", "text", "Output format: text, json or sarif")
+	lintCmd.Flags().StringArray("rule", nil, "Only run this rule (repeatable)")
+	lintCmd.Flags().Bool("fix", false, "Fix findings that can be fixed mechanically")
+	lintCmd.Flags().Bool("dry-run", false, "With --fix, show the fixes without writing anything")
+	lintCmd.Flags().String("fail-on", services.LintWarning, "Exit with an error for findings at or above this severity: error, warning, info or never")
+	rootCmd.AddCommand(lintCmd)
+}
+
+// lintRulesHelp lists the lint rules for the command help.
+func lintRulesHelp() string {
+	var b strings.Builder
+	for _, rule := range services.LintRules {
+		fixable := ""
+		if rule.Fixable {
+			fixable = " (fixable)"
+		}
+		fmt.Fprintf(&b, "  %-20s %-8s %s%s\n", rule.ID, rule.Severity, rule.Description, fixable)
+	}
+	return b.String()
+}
+
+// printLintText prints findings one per line followed by a summary.
+func printLintText(findings []services.LintFinding) {
+	counts := map[string]int{}
+	fixable := 0
+	for _, f := range findings {
+		fmt.Printf("%s:%d: %s %s: %s\n", f.Path, f.Line, f.Severity, f.Rule, f.Message)
+		counts[f.Severity]++
+		if f.Fixable {
+			fixable++
+		}
+	}
+
+	if len(findings) == 0 {
+		fmt.Println("No problems found")
+		return
+	}
+	fmt.Printf("\n%d problem(s): %d error(s), %d warning(s), %d info\n",
+		len(findings), counts[services.LintError], counts[services.LintWarning], counts[services.LintInfo])
+	if fixable > 0 {
+		fmt.Printf("%d can be fixed with --fix\n", fixable)
+	}
+}
+
+func printJSON(v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// lintSARIF converts findings to a SARIF 2.1.0 log. Locations are relative
+// to the notebook directory, which is usually the repository being checked.
+func lintSARIF(nb *services.Notebook, findings []services.LintFinding) map[string]any {
+	notebookDir := filepath.Dir(nb.Config.Path)
+
+	rules := make([]map[string]any, 0, len(services.LintRules))
+	for _, rule := range services.LintRules {
+		rules = append(rules, map[string]any{
+			"id":                   rule.ID,
+			"shortDescription":     map[string]any{"text": rule.Description},
+			"defaultConfiguration": map[string]any{"level": sarifLevel(rule.Severity)},
+		})
+	}
+
+	results := make([]map[string]any, 0, len(findings))
+	for _, f := range findings {
+		uri := f.Path
+		if rel, err := filepath.Rel(notebookDir, filepath.Join(nb.Config.Root, f.Path)); err == nil {
+			uri = filepath.ToSlash(rel)
+		}
+		results = append(results, map[string]any{
+			"ruleId":  f.Rule,
+			"level":   sarifLevel(f.Severity),
+			"message": map[string]any{"text": f.Message},
+			"locations": []map[string]any{{
+				"physicalLocation": map[string]any{
+					"artifactLocation": map[string]any{"uri": uri},
+					"region":           map[string]any{"startLine": f.Line},
+				},
+			}},
+		})
+	}
+
+	return map[string]any{
+		"$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+		"version": "2.1.0",
+		"runs": []map[string]any{{
+			"tool": map[string]any{
+				"driver": map[string]any{
+					"name":           "jot",
+					"version":        Version,
+					"informationUri": "https://github.com/zenobi-us/jot",
+					"rules":          rules,
+				},
+			},
+			"results": results,
+		}},
+	}
+}
+
+// sarifLevel maps a lint severity to a SARIF result level.
+func sarifLevel(severity string) string {
+	if severity == services.LintInfo {
+		return "note"
+	}
+	return severity
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+// maxPickerChoices limits how many candidates the picker lists.
+const maxPickerChoices = 20
+
+// resolveNoteArg resolves a note reference (path, title or fuzzy query) to a
+// single note, asking the user to pick when several notes match.
+func resolveNoteArg(cmd *cobra.Command, nb *services.Notebook, query string) (services.Note, error) {
+	notes, err := nb.Notes.ResolveNote(cmd.Context(), query)
+	if err != nil {
+		return services.Note{}, err
+	}
+
+	switch len(notes) {
+	case 0:
+		return services.Note{}, fmt.Errorf("no note matches %q", query)
+	case 1:
+		return notes[0], nil
+	default:
+		return pickNote(notes, query, os.Stdin, cmd.ErrOrStderr())
+	}
+}
+
+// pickNote shows a numbered list of candidates on out and reads the choice from in.
+func pickNote(notes []services.Note, query string, in io.Reader, out io.Writer) (services.Note, error) {
+	if len(notes) > maxPickerChoices {
+		notes = notes[:maxPickerChoices]
+	}
+
+	_, _ = fmt.Fprintf(out, "Several notes match %q:\n", query)
+	for i, note := range notes {
+		_, _ = fmt.Fprintf(out, "  %2d) %s  (%s)\n", i+1, note.DisplayName(), note.File.Relative)
+	}
+	_, _ = fmt.Fprintf(out, "Select a note [1-%d]: ", len(notes))
+
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return services.Note{}, fmt.Errorf("no note selected")
+	}
+
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(notes) {
+		return services.Note{}, fmt.Errorf("invalid selection: %q", strings.TrimSpace(line))
+	}
+
+	return notes[choice-1], nil
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+func pickerNotes() []services.Note {
+	notes := make([]services.Note, 2)
+	notes[0].File.Relative = "retro.md"
+	notes[0].Metadata = map[string]any{"title": "Sprint Retro"}
+	notes[1].File.Relative = "archive/retro.md"
+	notes[1].Metadata = map[string]any{"title": "Sprint Retro"}
+	return notes
+}
+
+func TestPickNote_SelectsChoice(t *testing.T) {
+	var out bytes.Buffer
+
+	note, err := pickNote(pickerNotes(), "retro", strings.NewReader("2\n"), &out)
+	require.NoError(t, err)
+	assert.Equal(t, "archive/retro.md", note.File.Relative)
+	assert.Contains(t, out.String(), "1) Sprint Retro  (retro.md)")
+	assert.Contains(t, out.String(), "2) Sprint Retro  (archive/retro.md)")
+}
+
+func TestPickNote_InvalidChoice(t *testing.T) {
+	var out bytes.Buffer
+
+	_, err := pickNote(pickerNotes(), "retro", strings.NewReader("3\n"), &out)
+	assert.ErrorContains(t, err, "invalid selection")
+
+	_, err = pickNote(pickerNotes(), "retro", strings.NewReader("abc\n"), &out)
+	assert.ErrorContains(t, err, "invalid selection")
+}
+
+func TestPickNote_NoInput(t *testing.T) {
+	var out bytes.Buffer
+
+	_, err := pickNote(pickerNotes(), "retro", strings.NewReader(""), &out)
+	assert.ErrorContains(t, err, "no note selected")
+}
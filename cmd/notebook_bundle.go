@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notebookExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Bundle the notebook into a .jotpack file",
+	Long: `Bundles the current notebook into a single .jotpack file (a zip archive)
+that "jot notebook import" restores on any machine:
+  - every file under the notes root, including attachments and the trash,
+    with their modification times
+  - .jot.json with views, templates, groups and schema
+  - templates and other files in the notebook's .jot directory
+
+Encrypted notes are bundled encrypted, and the notebook's key file and
+any other age identity files are left out. Contexts outside the notebook
+directory are left out, as they only make sense on this machine. Notes
+kept in remote storage are bundled as local files.
+
+Use --index to include a prebuilt search index of the notes, for tools
+that read it. jot itself rebuilds its index when the notebook is opened.
+
+Examples:
+  # Bundle the notebook
+  jot notebook export team.jotpack
+
+  # Restore it elsewhere
+  jot notebook import team.jotpack ~/notes/team --register`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		withIndex, _ := cmd.Flags().GetBool("index")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		file := args[0]
+		if filepath.Ext(file) == "" {
+			file += services.JotpackExtension
+		}
+		result, err := nb.ExportJotpack(cmd.Context(), file, services.JotpackExportOptions{Index: withIndex})
+		if err != nil {
+			return fmt.Errorf("failed to export notebook: %w", err)
+		}
+		for _, c := range result.DroppedContexts {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: context %s is outside the notebook and was not bundled\n", c)
+		}
+		for _, key := range result.SkippedKeys {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: %s holds age secret keys and was not bundled\n", key)
+		}
+		if result.Storage != "" {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: notes in %s storage were bundled as local files\n", result.Storage)
+		}
+		if format == "json" {
+			return printJSON(result)
+		}
+
+		fmt.Printf("Exported notebook '%s' to %s\n", result.Manifest.Name, result.File)
+		fmt.Printf("  Notes: %d\n", result.Manifest.Notes)
+		fmt.Printf("  Files: %d\n", result.Manifest.Files)
+		if result.Manifest.Index {
+			fmt.Println("  Search index included")
+		}
+		return nil
+	},
+}
+
+var notebookImportCmd = &cobra.Command{
+	Use:   "import <file> [dir]",
+	Short: "Restore a notebook from a .jotpack file",
+	Long: `Restores a notebook bundled by "jot notebook export" into dir, which must
+be empty or not exist. dir defaults to a directory named after the
+notebook in the current directory.
+
+The notebook's contexts are resolved against dir; a notebook without
+contexts gets dir as its context. Use --register to add the notebook to
+your global config.
+
+Examples:
+  # Restore into ./<notebook name>
+  jot notebook import team.jotpack
+
+  # Restore, rename and register
+  jot notebook import team.jotpack ~/notes/team --name "Team" --register`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("name")
+		register, _ := cmd.Flags().GetBool("register")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		dir := ""
+		if len(args) > 1 {
+			dir = args[1]
+		} else {
+			manifest, err := services.ReadJotpackManifest(args[0])
+			if err != nil {
+				return err
+			}
+			dir = core.Slugify(manifest.Name)
+			if name != "" {
+				dir = core.Slugify(name)
+			}
+			if strings.Trim(dir, "-") == "" {
+				dir = "notebook"
+			}
+		}
+
+		nb, manifest, err := notebookService.ImportJotpack(args[0], dir, name, register)
+		if err != nil {
+			return fmt.Errorf("failed to import notebook: %w", err)
+		}
+		if format == "json" {
+			return printJSON(map[string]any{
+				"name":       nb.Config.Name,
+				"path":       filepath.Dir(nb.Config.Path),
+				"root":       nb.Config.Root,
+				"contexts":   nb.Config.Contexts,
+				"manifest":   manifest,
+				"registered": register,
+			})
+		}
+
+		fmt.Printf("Imported notebook '%s'\n", nb.Config.Name)
+		fmt.Printf("  Config: %s\n", nb.Config.Path)
+		fmt.Printf("  Notes:  %s (%d notes, %d files)\n", nb.Config.Root, manifest.Notes, manifest.Files)
+		if register {
+			fmt.Println("  Registered globally")
+		}
+		return nil
+	},
+}
+
+func init() {
+	notebookExportCmd.Flags().Bool("index", false, "Include a prebuilt search index")
+	notebookExportCmd.Flags().String("format", "text", "Output format: text or json")
+	notebookImportCmd.Flags().StringP("name", "n", "", "Rename the imported notebook")
+	notebookImportCmd.Flags().BoolP("register", "r", false, "Register the imported notebook globally")
+	notebookImportCmd.Flags().String("format", "text", "Output format: text or json")
+
+	notebookCmd.AddCommand(notebookExportCmd)
+	notebookCmd.AddCommand(notebookImportCmd)
+}
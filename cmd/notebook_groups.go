@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notebookGroupsCmd = &cobra.Command{
+	Use:   "groups [path]",
+	Short: "Show notebook groups and the defaults they apply",
+	Long: `Lists the groups defined in .jot.json. Groups give new notes default
+frontmatter metadata and a template based on where they are created.
+
+When a path is given, marks the groups matching it and shows the merged
+defaults "jot notes add" would apply there. Groups apply in the order they
+are listed, later groups overriding earlier ones; --data and --template
+on "jot notes add" override groups.
+
+Examples:
+  # List groups
+  jot notebook groups
+
+  # What would a new meeting note get?
+  jot notebook groups meetings/standup.md
+
+  # As JSON
+  jot notebook groups meetings/standup.md --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		var relPath string
+		if len(args) > 0 {
+			relPath = filepath.ToSlash(args[0])
+			if !strings.HasSuffix(relPath, ".md") {
+				relPath += ".md"
+			}
+		}
+
+		groups := nb.Config.Groups
+		if groups == nil {
+			groups = []services.NotebookGroup{}
+		}
+
+		var defaults *services.GroupDefaults
+		if relPath != "" {
+			d := nb.Config.GroupDefaults(relPath)
+			defaults = &d
+		}
+
+		if asJSON {
+			out := map[string]any{"groups": groups}
+			if defaults != nil {
+				out["path"] = relPath
+				out["defaults"] = defaults
+			}
+			data, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		matched := map[string]bool{}
+		if defaults != nil {
+			for _, name := range defaults.Groups {
+				matched[name] = true
+			}
+		}
+
+		output, err := services.TuiRender("notebook-groups", map[string]any{
+			"Groups":   groups,
+			"Path":     relPath,
+			"Defaults": defaults,
+			"Matched":  func(name string) bool { return matched[name] },
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Print(output)
+		return nil
+	},
+}
+
+func init() {
+	notebookGroupsCmd.Flags().Bool("json", false, "Output as JSON")
+	notebookCmd.AddCommand(notebookGroupsCmd)
+}
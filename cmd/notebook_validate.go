@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+var notebookValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check note frontmatter against the notebook schema",
+	Long: `Checks the frontmatter of every note against the field schema declared
+in .jot.json and lists the violations by note. Exits with an error when
+any note is invalid.
+
+The schema applies to every note, and groups add to it for the notes
+matching their globs:
+
+  "schema": {
+    "status": { "type": "string", "enum": ["todo", "doing", "done"] },
+    "due": { "type": "date" }
+  },
+  "groups": [
+    { "name": "Projects", "globs": ["projects/**"], "metadata": {},
+      "schema": { "owner": { "type": "string", "required": true } } }
+  ]
+
+Types are string, number, integer, boolean, date, datetime and list.
+"enum" and "pattern" apply to every item of a list. "jot notes add",
+"set", "unset" and "tag" refuse changes that break the schema.
+
+Examples:
+  jot notebook validate
+  jot notebook validate --json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		violations, checked, err := nb.ValidateNotes(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		invalid := map[string]bool{}
+		for _, v := range violations {
+			invalid[v.Path] = true
+		}
+
+		if asJSON {
+			if violations == nil {
+				violations = core.ValidationErrors{}
+			}
+			data, err := json.MarshalIndent(map[string]any{
+				"checked":    checked,
+				"invalid":    len(invalid),
+				"violations": violations,
+			}, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		} else if len(violations) > 0 {
+			fmt.Println(violations.PrettyPrint())
+			fmt.Println()
+		}
+
+		if len(violations) > 0 {
+			return fmt.Errorf("%d violation(s) in %d of %d note(s)", len(violations), len(invalid), checked)
+		}
+		if !asJSON {
+			fmt.Printf("All %d note(s) match the schema\n", checked)
+		}
+		return nil
+	},
+}
+
+func init() {
+	notebookValidateCmd.Flags().Bool("json", false, "Output as JSON")
+	notebookCmd.AddCommand(notebookValidateCmd)
+}
+
+// schemaError reports content of a note that breaks the notebook schema.
+func schemaError(relPath string, violations core.ValidationErrors) error {
+	return fmt.Errorf("%s does not match the notebook schema:\n%s", relPath, violations.PrettyPrint())
+}
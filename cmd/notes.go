@@ -35,8 +35,23 @@ Examples:
   # Query with boolean filters
   jot notes search query --and path=**/*.md --not path=archive/*
 
-  # Remove a note
-  jot notes remove my-note.md`,
+  # Show a note with backlinks and related notes
+  jot notes show "Meeting Notes"
+
+  # Edit a note in $EDITOR
+  jot notes edit "Meeting Notes"
+
+  # Set a frontmatter field
+  jot notes set todo.md status=done
+
+  # Move a note and update links to it
+  jot notes move todo.md tasks/todo.md
+
+  # Remove a note (moves it to the trash)
+  jot notes remove my-note.md
+
+  # Restore it
+  jot notes restore my-note.md`,
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"io"
 	"os"
@@ -11,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/zenobi-us/jot/internal/core"
 	"github.com/zenobi-us/jot/internal/services"
+	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,7 +40,22 @@ EXAMPLES:
   echo "# Content" | jot notes add "My Note"
   
   # Use template
-  jot notes add "Bug Report" bugs/ --template bug`,
+  jot notes add "Bug Report" bugs/ --template bug
+
+  # Encrypt the note at rest (requires an "encryption" key in .jot.json)
+  jot notes add "Prod Credentials" secrets/ --encrypt
+
+TEMPLATES:
+  Templates are Go text/template files defined in .jot.json "templates" or
+  stored as .jot/templates/<name>.md. They can use .Title, .Notebook,
+  .Path, .Data.<key>, date variables such as {{today+1}}, {{include "name"}}
+  and {{prompt "Owner"}}, which asks for values not given with --data.
+
+GROUPS:
+  Groups in .jot.json whose globs match the new note's path add their
+  metadata to the frontmatter and provide a template when --template is
+  not given. Later groups override earlier ones and --data wins over all.
+  Inspect them with "jot notebook groups".`,
 	Args: cobra.MaximumNArgs(2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		nb, err := requireNotebook(cmd)
@@ -50,6 +67,7 @@ EXAMPLES:
 		titleFlag, _ := cmd.Flags().GetString("title")
 		titleFlagProvided := cmd.Flags().Changed("title")
 		dataFlags, _ := cmd.Flags().GetStringArray("data")
+		encryptNote, _ := cmd.Flags().GetBool("encrypt")
 
 		// Parse arguments (title and optional path)
 		title, pathArg, err := parseArguments(args, titleFlag, titleFlagProvided)
@@ -90,15 +108,42 @@ EXAMPLES:
 			notePath = filepath.Join(nb.Config.Root, timestamp+".md")
 		}
 
+		// Storage paths are relative to the notebook root
+		relPath, err := filepath.Rel(nb.Config.Root, notePath)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			return fmt.Errorf("note path is outside the notebook: %s", notePath)
+		}
+
 		// Check if file already exists
-		if _, err := os.Stat(notePath); err == nil {
+		if nb.Storage.Exists(relPath) {
 			return fmt.Errorf("note already exists: %s", notePath)
 		}
 
-		// Create directories if needed
-		noteDir := filepath.Dir(notePath)
-		if err := os.MkdirAll(noteDir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory: %w", err)
+		// Apply notebook groups matching the path: their metadata comes
+		// first so explicit --data wins, and their template is used when
+		// --template is not given
+		groupDefaults := nb.Config.GroupDefaults(relPath)
+		metadata := make(map[string]interface{}, len(groupDefaults.Metadata)+len(customData))
+		for k, v := range groupDefaults.Metadata {
+			if k == "title" && title != "" {
+				continue
+			}
+			metadata[k] = v
+		}
+		nb.Config.CoerceFields(relPath, customData)
+		for k, v := range customData {
+			metadata[k] = v
+		}
+		if err := assignNoteID(cmd, nb, metadata); err != nil {
+			return err
+		}
+		templates := nb.NoteTemplates()
+		if template == "" && groupDefaults.Template != "" {
+			if templates.Has(groupDefaults.Template) {
+				template = groupDefaults.Template
+			} else {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: group template %q is not defined in the notebook templates\n", groupDefaults.Template)
+			}
 		}
 
 		// Check for stdin content
@@ -112,7 +157,12 @@ EXAMPLES:
 		if stdinContent != "" {
 			content = stdinContent
 		} else if template != "" {
-			content = generateNoteContent(title, template, nb.Config.Templates)
+			templates.Prompt = terminalPrompt()
+			data := services.NewTemplateData(nb.Config.Name, title, relPath, metadata)
+			content, err = templates.Render(template, data)
+			if err != nil {
+				return err
+			}
 		} else {
 			if title != "" {
 				content = fmt.Sprintf("# %s\n\n", title)
@@ -121,14 +171,34 @@ EXAMPLES:
 			}
 		}
 
-		// Generate frontmatter with custom data
-		frontmatter := generateFrontmatter(title, customData)
+		// Construct final content with frontmatter. A template that brings
+		// its own frontmatter keeps it, with title, created and --data
+		// values set on top
+		var finalContent string
+		if stdinContent == "" {
+			finalContent, err = withFrontmatter(content, noteFrontmatter(title, metadata))
+			if err != nil {
+				return fmt.Errorf("template %q: %w", template, err)
+			}
+		} else {
+			finalContent = fmt.Sprintf("---\n%s---\n\n%s", generateFrontmatter(title, metadata), content)
+		}
 
-		// Construct final content with frontmatter
-		finalContent := fmt.Sprintf("---\n%s---\n\n%s", frontmatter, content)
+		if violations := nb.Config.ValidateContent(relPath, []byte(finalContent)); len(violations) > 0 {
+			return schemaError(relPath, violations)
+		}
 
-		// Write the file
-		if err := os.WriteFile(notePath, []byte(finalContent), 0644); err != nil {
+		// Write the file (parent directories are created by the storage)
+		if encryptNote {
+			enc := nb.Encryption()
+			if enc == nil || !enc.CanEncrypt() {
+				return fmt.Errorf("cannot encrypt note: no encryption key configured for this notebook")
+			}
+			err = enc.WriteEncrypted(relPath, []byte(finalContent))
+		} else {
+			err = nb.Storage.Write(relPath, []byte(finalContent))
+		}
+		if err != nil {
 			return fmt.Errorf("failed to create note: %w", err)
 		}
 
@@ -141,37 +211,30 @@ func init() {
 	notesAddCmd.Flags().StringP("template", "t", "", "Template to use")
 	notesAddCmd.Flags().String("title", "", "Note title (DEPRECATED: use positional argument)")
 	notesAddCmd.Flags().StringArray("data", []string{}, "Set frontmatter field (repeatable, format: field=value)")
+	notesAddCmd.Flags().Bool("encrypt", false, "Encrypt the note at rest")
 	notesCmd.AddCommand(notesAddCmd)
 }
 
-// generateNoteContent creates the initial note content.
-func generateNoteContent(title, templateName string, templates map[string]string) string {
-	var content strings.Builder
-
-	// If a template is specified and exists, use it
-	if templateName != "" && templates != nil {
-		if tmplContent, ok := templates[templateName]; ok {
-			// Replace {{title}} placeholder if present
-			if title != "" {
-				return strings.ReplaceAll(tmplContent, "{{title}}", title)
-			}
-			return tmplContent
-		}
-	}
-
-	// Default content with frontmatter
-	content.WriteString("---\n")
-	if title != "" {
-		content.WriteString(fmt.Sprintf("title: %s\n", title))
+// terminalPrompt returns a prompt that asks on the terminal for values
+// templates need, or nil when stdin is not a terminal.
+func terminalPrompt() services.PromptFunc {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
 	}
-	content.WriteString(fmt.Sprintf("created: %s\n", time.Now().Format(time.RFC3339)))
-	content.WriteString("---\n\n")
 
-	if title != "" {
-		content.WriteString(fmt.Sprintf("# %s\n\n", title))
+	reader := bufio.NewReader(os.Stdin)
+	return func(label, def string) (string, error) {
+		if def != "" {
+			fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", label)
+		}
+		answer, err := reader.ReadString('\n')
+		if err != nil && answer == "" {
+			return "", fmt.Errorf("failed to read %s: %w", label, err)
+		}
+		return strings.TrimSpace(answer), nil
 	}
-
-	return content.String()
 }
 
 // parseArguments parses command arguments to extract title and path
@@ -225,6 +288,41 @@ func readStdin() (string, error) {
 
 // generateFrontmatter creates frontmatter with title and custom data
 func generateFrontmatter(title string, customData map[string]interface{}) string {
+	fm := noteFrontmatter(title, customData)
+
+	// Serialize to YAML
+	fmBytes, err := yaml.Marshal(fm)
+	if err != nil {
+		// Fallback to simple format if YAML fails
+		if title != "" {
+			return fmt.Sprintf("title: %s\ncreated: %s\n", title, time.Now().Format(time.RFC3339))
+		}
+		return fmt.Sprintf("created: %s\n", time.Now().Format(time.RFC3339))
+	}
+
+	return string(fmBytes)
+}
+
+// withFrontmatter adds fields to the frontmatter of content, keeping
+// frontmatter content already has, or prepends a new frontmatter block.
+func withFrontmatter(content string, fields map[string]interface{}) (string, error) {
+	if strings.HasPrefix(content, "---\n") {
+		merged, err := services.UpdateFrontmatter([]byte(content), fields, nil)
+		if err != nil {
+			return "", err
+		}
+		return string(merged), nil
+	}
+
+	fmBytes, err := yaml.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("---\n%s---\n\n%s", fmBytes, content), nil
+}
+
+// noteFrontmatter returns the frontmatter fields of a new note.
+func noteFrontmatter(title string, customData map[string]interface{}) map[string]interface{} {
 	fm := map[string]interface{}{
 		"created": time.Now().Format(time.RFC3339),
 	}
@@ -242,15 +340,5 @@ func generateFrontmatter(title string, customData map[string]interface{}) string
 		fm[k] = v
 	}
 
-	// Serialize to YAML
-	fmBytes, err := yaml.Marshal(fm)
-	if err != nil {
-		// Fallback to simple format if YAML fails
-		if title != "" {
-			return fmt.Sprintf("title: %s\ncreated: %s\n", title, time.Now().Format(time.RFC3339))
-		}
-		return fmt.Sprintf("created: %s\n", time.Now().Format(time.RFC3339))
-	}
-
-	return string(fmBytes)
+	return fm
 }
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+// addSelectionFlags lets a mutation command act on every note returned by a
+// filter query or saved view instead of a single <note> argument.
+func addSelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("where", "", "Apply to every note matching a filter query (e.g. 'tag:sprint-41')")
+	cmd.Flags().String("view", "", "Apply to every note returned by a saved view")
+	cmd.Flags().String("param", "", "View parameters for --view (key=value,key2=value2)")
+	cmd.Flags().Bool("dry-run", false, "Show the affected notes and diffs without writing anything")
+}
+
+// hasSelection reports whether --where or --view was given.
+func hasSelection(cmd *cobra.Command) bool {
+	where, _ := cmd.Flags().GetString("where")
+	view, _ := cmd.Flags().GetString("view")
+	return where != "" || view != ""
+}
+
+// noteArgs validates positional arguments for commands taking a leading
+// <note>, which is dropped when notes are selected with --where or --view.
+func noteArgs(min int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		where, _ := cmd.Flags().GetString("where")
+		view, _ := cmd.Flags().GetString("view")
+		if where != "" && view != "" {
+			return fmt.Errorf("--where and --view cannot be used together")
+		}
+		if hasSelection(cmd) {
+			min--
+		}
+		return cobra.MinimumNArgs(min)(cmd, args)
+	}
+}
+
+// targetNotes returns the notes a mutation command applies to and the
+// remaining arguments. Without --where/--view the first argument names
+// a single note.
+func targetNotes(cmd *cobra.Command, nb *services.Notebook, args []string) ([]services.Note, []string, error) {
+	if !hasSelection(cmd) {
+		note, err := resolveNoteArg(cmd, nb, args[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		return []services.Note{note}, args[1:], nil
+	}
+
+	notes, err := selectNotes(cmd, nb)
+	if err != nil {
+		return nil, nil, err
+	}
+	return notes, args, nil
+}
+
+// selectNotes runs the --where query or --view and returns the matching
+// notes ordered by path. Grouped views are flattened.
+func selectNotes(cmd *cobra.Command, nb *services.Notebook) ([]services.Note, error) {
+	where, _ := cmd.Flags().GetString("where")
+	viewName, _ := cmd.Flags().GetString("view")
+	paramStr, _ := cmd.Flags().GetString("param")
+
+	notes, err := queryNotes(cmd.Context(), nb, where, viewName, paramStr)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("no notes match the selection")
+	}
+	return notes, nil
+}
+
+// queryNotes runs a filter query, or the saved view viewName with its
+// parameters, and returns the matching notes ordered by path.
+func queryNotes(ctx context.Context, nb *services.Notebook, where, viewName, paramStr string) ([]services.Note, error) {
+	vs := services.NewViewService(cfgService, filepath.Dir(nb.Config.Path))
+	vs.SetExecutionContext(nb.Notes.GetIndex(), nb.Notes)
+
+	view := &core.ViewDefinition{Name: "where", Query: where}
+	if viewName != "" {
+		var err error
+		view, err = vs.GetView(viewName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get view '%s': %w", viewName, err)
+		}
+	}
+
+	params, err := vs.ParseViewParameters(paramStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse parameters: %w", err)
+	}
+
+	results, err := vs.ExecuteView(ctx, view, params)
+	if err != nil {
+		if viewName != "" {
+			return nil, fmt.Errorf("failed to execute view '%s': %w", viewName, err)
+		}
+		return nil, fmt.Errorf("failed to run query: %w", err)
+	}
+
+	notes := results.Notes
+	for _, group := range results.Groups {
+		notes = append(notes, group...)
+	}
+
+	seen := make(map[string]bool, len(notes))
+	unique := notes[:0]
+	for _, note := range notes {
+		if !seen[note.File.Relative] {
+			seen[note.File.Relative] = true
+			unique = append(unique, note)
+		}
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		return unique[i].File.Relative < unique[j].File.Relative
+	})
+	return unique, nil
+}
+
+// printChanges prints planned changes as unified diffs.
+func printChanges(changes []*services.NoteChange) {
+	for _, change := range changes {
+		fmt.Println()
+		fmt.Print(core.UnifiedDiff(change.OldPath, change.Path, change.Before, change.After, 3))
+	}
+}
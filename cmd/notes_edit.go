@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesEditCmd = &cobra.Command{
+	Use:   "edit <note|query>",
+	Short: "Open a note in your editor",
+	Long: `Opens a note in $VISUAL or $EDITOR (falling back to vi).
+
+The note is resolved by path (the .md extension is optional), then by
+exact title, then by fuzzy match on title and path. When several notes
+match you are asked to pick one.
+
+After the editor exits, jot stamps the "modified" frontmatter field,
+re-indexes the note and reports what changed. Nothing is written if the
+note was not changed.
+
+Examples:
+  # Edit by path
+  jot notes edit meetings/standup.md
+
+  # Edit by title
+  jot notes edit "Sprint Planning"
+
+  # Fuzzy match
+  jot notes edit sprplan
+
+  # Use a specific editor for one invocation
+  EDITOR="code --wait" jot notes edit todo`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		note, err := resolveNoteArg(cmd, nb, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+		return editNote(cmd, nb, note.File.Relative)
+	},
+}
+
+func init() {
+	notesCmd.AddCommand(notesEditCmd)
+}
+
+// editNote opens the note at relPath in the user's editor, then stamps the
+// modified field and re-indexes it when it changed.
+func editNote(cmd *cobra.Command, nb *services.Notebook, relPath string) error {
+	original, err := nb.Storage.Read(relPath)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+
+	edited, err := editInEditor(original, filepath.Base(relPath))
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(original, edited) {
+		fmt.Printf("No changes: %s\n", relPath)
+		return nil
+	}
+
+	stamped, err := services.UpdateFrontmatter(edited, map[string]any{
+		"modified": time.Now().Format(time.RFC3339),
+	}, nil)
+	if err != nil {
+		// Never lose the user's edits because of broken frontmatter
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: could not update modified date: %v\n", err)
+		stamped = edited
+	}
+
+	if err := nb.Storage.Write(relPath, stamped); err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+
+	if err := nb.Notes.IndexFile(cmd.Context(), relPath); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to re-index note: %v\n", err)
+	}
+
+	added, removed := core.DiffStat(string(original), string(edited))
+	fmt.Printf("Updated note: %s (+%d -%d lines)\n", relPath, added, removed)
+	return nil
+}
+
+// editorCommand returns the user's preferred editor.
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
+
+// editInEditor writes content to a private temp file, opens it in the
+// user's editor and returns the edited content. Going through a temp file
+// keeps editing working for encrypted and remote storage backends.
+func editInEditor(content []byte, name string) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "jot-edit-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	tmpPath := filepath.Join(dir, name)
+	if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	editor := editorCommand()
+
+	// Run through the shell so editors with arguments ("code --wait") work
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		fields := strings.Fields(editor)
+		c = exec.Command(fields[0], append(fields[1:], tmpPath)...)
+	} else {
+		c = exec.Command("sh", "-c", editor+` "$@"`, editor, tmpPath)
+	}
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited note: %w", err)
+	}
+
+	return edited, nil
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesEncryptCmd = &cobra.Command{
+	Use:   "encrypt <note>...",
+	Short: "Encrypt existing notes at rest",
+	Long: `Encrypts one or more notes in place using the notebook's age key.
+
+Keys are configured in the "encryption" section of .jot.json, either as
+an age identity file (key_file, overridable with JOT_KEY_FILE) and
+recipient public keys, or as a passphrase read from an environment
+variable (passphrase_env). Notes that are already encrypted are skipped.
+
+Once encrypted, a note stays encrypted when it is rewritten.
+
+Examples:
+  # Encrypt a single note
+  jot notes encrypt secrets/aws.md
+
+  # Encrypt several notes
+  jot notes encrypt incidents/2024-01.md incidents/2024-02`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		enc := nb.Encryption()
+		if enc == nil || !enc.CanEncrypt() {
+			return fmt.Errorf("no encryption key configured for this notebook")
+		}
+
+		return convertNotes(nb, args, "encrypt", "Encrypted", enc.Encrypt)
+	},
+}
+
+var notesDecryptCmd = &cobra.Command{
+	Use:   "decrypt <note>...",
+	Short: "Decrypt encrypted notes back to plain text",
+	Long: `Decrypts one or more notes in place, storing them as plain markdown.
+
+Requires a key able to decrypt the notes. Plain text notes are skipped.
+Notes matching one of the encryption globs in .jot.json are encrypted
+again the next time they are written.
+
+Examples:
+  jot notes decrypt secrets/aws.md`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		enc := nb.Encryption()
+		if enc == nil || !enc.CanDecrypt() {
+			return fmt.Errorf("no decryption key configured for this notebook")
+		}
+
+		return convertNotes(nb, args, "decrypt", "Decrypted", enc.Decrypt)
+	},
+}
+
+// convertNotes applies convert to each named note and reports the result.
+func convertNotes(nb *services.Notebook, names []string, action, done string, convert func(string) error) error {
+	enc := nb.Encryption()
+
+	for _, name := range names {
+		if !strings.HasSuffix(name, ".md") {
+			name += ".md"
+		}
+
+		if !nb.Storage.Exists(name) {
+			return fmt.Errorf("note not found: %s", name)
+		}
+
+		wasEncrypted, err := enc.IsEncryptedPath(name)
+		if err != nil {
+			return fmt.Errorf("failed to read note %s: %w", name, err)
+		}
+
+		if err := convert(name); err != nil {
+			return fmt.Errorf("failed to %s note %s: %w", action, name, err)
+		}
+
+		isEncrypted, err := enc.IsEncryptedPath(name)
+		if err != nil {
+			return fmt.Errorf("failed to read note %s: %w", name, err)
+		}
+
+		if wasEncrypted == isEncrypted {
+			fmt.Printf("Skipped note (already %s): %s\n", encryptionState(isEncrypted), name)
+			continue
+		}
+		fmt.Printf("%s note: %s\n", done, name)
+	}
+
+	return nil
+}
+
+// encryptionState describes whether a note is encrypted.
+func encryptionState(encrypted bool) string {
+	if encrypted {
+		return "encrypted"
+	}
+	return "plain text"
+}
+
+func init() {
+	notesCmd.AddCommand(notesEncryptCmd)
+	notesCmd.AddCommand(notesDecryptCmd)
+}
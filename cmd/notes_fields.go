@@ -0,0 +1,215 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesSetCmd = &cobra.Command{
+	Use:   "set <note> <field=value>...",
+	Short: "Set frontmatter fields of a note",
+	Long: `Sets frontmatter fields using the same field=value syntax as
+"jot notes add --data". Repeating a field stores a list.
+
+Values are stored with the YAML type they read as: numbers, booleans and
+flow lists ("[a, b]") keep their type, everything else is a string.
+Existing keys keep their position and comments, new keys are appended,
+and the "modified" field is stamped.
+
+The note is resolved like "jot notes edit". Use --where or --view instead
+of <note> to update every matching note, and --dry-run to preview.
+
+Examples:
+  jot notes set todo.md status=done priority=2
+  jot notes set "Sprint Planning" owner=alice owner=bob
+  jot notes set todo.md tags=[work,urgent]
+  jot notes set --where 'tag:sprint-41' status=archived --dry-run`,
+	Args: noteArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		notes, fields, err := targetNotes(cmd, nb, args)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("no fields given (expected field=value)")
+		}
+
+		data, err := services.ParseDataFlags(fields)
+		if err != nil {
+			return err
+		}
+		set := services.ParseFieldValues(data)
+
+		return updateNotes(cmd, nb, notes, func(note services.Note) (*services.NoteChange, error) {
+			return nb.Notes.PlanFieldUpdate(note.File.Relative, set, nil)
+		})
+	},
+}
+
+var notesUnsetCmd = &cobra.Command{
+	Use:   "unset <note> <field>...",
+	Short: "Remove frontmatter fields from a note",
+	Long: `Removes frontmatter fields from a note and stamps the "modified" field.
+Fields that are not present are ignored.
+
+Examples:
+  jot notes unset todo.md priority
+  jot notes unset "Sprint Planning" owner due
+  jot notes unset --view done sprint`,
+	Args: noteArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		notes, fields, err := targetNotes(cmd, nb, args)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("no fields given")
+		}
+
+		return updateNotes(cmd, nb, notes, func(note services.Note) (*services.NoteChange, error) {
+			return nb.Notes.PlanFieldUpdate(note.File.Relative, nil, fields)
+		})
+	},
+}
+
+var notesTagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Add or remove tags on a note",
+	Long: `Edits the "tags" frontmatter list of a note. Tags are matched
+case-insensitively and existing tags keep their order.
+
+Use --where or --view instead of <note> to tag every matching note.
+
+Examples:
+  jot notes tag add todo.md work urgent
+  jot notes tag remove todo.md urgent
+  jot notes tag add --where 'path:projects/apollo' apollo`,
+}
+
+var notesTagAddCmd = &cobra.Command{
+	Use:   "add <note> <tag>...",
+	Short: "Add tags to a note",
+	Args:  noteArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagUpdate(cmd, args, true)
+	},
+}
+
+var notesTagRemoveCmd = &cobra.Command{
+	Use:     "remove <note> <tag>...",
+	Aliases: []string{"rm"},
+	Short:   "Remove tags from a note",
+	Args:    noteArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTagUpdate(cmd, args, false)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{notesSetCmd, notesUnsetCmd, notesTagAddCmd, notesTagRemoveCmd} {
+		addSelectionFlags(c)
+	}
+	notesTagCmd.AddCommand(notesTagAddCmd)
+	notesTagCmd.AddCommand(notesTagRemoveCmd)
+	notesCmd.AddCommand(notesSetCmd)
+	notesCmd.AddCommand(notesUnsetCmd)
+	notesCmd.AddCommand(notesTagCmd)
+}
+
+func runTagUpdate(cmd *cobra.Command, args []string, add bool) error {
+	nb, err := requireNotebook(cmd)
+	if err != nil {
+		return err
+	}
+
+	notes, tagArgs, err := targetNotes(cmd, nb, args)
+	if err != nil {
+		return err
+	}
+	tags := trimTags(tagArgs)
+	if len(tags) == 0 {
+		return fmt.Errorf("no tags given")
+	}
+
+	return updateNotes(cmd, nb, notes, func(note services.Note) (*services.NoteChange, error) {
+		var change *services.NoteChange
+		if add {
+			change, _, err = nb.Notes.PlanTagUpdate(note.File.Relative, tags, nil)
+		} else {
+			change, _, err = nb.Notes.PlanTagUpdate(note.File.Relative, nil, tags)
+		}
+		return change, err
+	})
+}
+
+// trimTags strips whitespace and a leading "#" so "#work" and "work" match.
+func trimTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// updateNotes plans a change for every note, then prints the diffs for
+// --dry-run or writes the changes. Nothing is written if any plan fails or
+// breaks the notebook schema in a way the note did not already.
+func updateNotes(cmd *cobra.Command, nb *services.Notebook, notes []services.Note, plan func(services.Note) (*services.NoteChange, error)) error {
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	var changes []*services.NoteChange
+	var unchanged []string
+	for _, note := range notes {
+		change, err := plan(note)
+		if err != nil {
+			return err
+		}
+		if change == nil {
+			unchanged = append(unchanged, note.File.Relative)
+			continue
+		}
+		violations := services.NewViolations(
+			nb.Config.ValidateContent(change.OldPath, []byte(change.Before)),
+			nb.Config.ValidateContent(change.Path, []byte(change.After)),
+		)
+		if len(violations) > 0 {
+			return schemaError(change.Path, violations)
+		}
+		changes = append(changes, change)
+	}
+
+	if dryRun {
+		fmt.Printf("Would update %d of %d note(s)\n", len(changes), len(notes))
+		printChanges(changes)
+		return nil
+	}
+
+	for _, change := range changes {
+		if err := nb.Notes.ApplyChange(cmd.Context(), change); err != nil {
+			return err
+		}
+		fmt.Printf("Updated note: %s\n", change.Path)
+	}
+	for _, p := range unchanged {
+		fmt.Printf("No changes: %s\n", p)
+	}
+	if len(notes) > 1 {
+		fmt.Printf("Updated %d of %d note(s)\n", len(changes), len(notes))
+	}
+	return nil
+}
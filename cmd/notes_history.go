@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesHistoryCmd = &cobra.Command{
+	Use:     "history <note>",
+	Aliases: []string{"log"},
+	Short:   "List the git commits that changed a note",
+	Long: `Lists the commits touching a note, newest first, following renames.
+The notebook must be inside a git repository.
+
+The note is resolved like "jot notes edit". Commits made while the note
+had another path show that path.
+
+Examples:
+  # History of a note
+  jot notes history "Sprint Planning"
+
+  # As JSON
+  jot notes history meetings/standup.md --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format != "list" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected list or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		note, err := resolveNoteArg(cmd, nb, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		history, err := nb.NoteHistory(cmd.Context(), note.File.Relative)
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return printJSON(history)
+		}
+		if len(history) == 0 {
+			fmt.Printf("No commits for %s\n", note.File.Relative)
+			return nil
+		}
+		for _, c := range history {
+			line := fmt.Sprintf("%s  %s  %s  %s", c.Short(), c.Date.Local().Format("2006-01-02 15:04"), c.Author, c.Subject)
+			if c.Path != note.File.Relative {
+				line += fmt.Sprintf("  (as %s)", c.Path)
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var notesDiffCmd = &cobra.Command{
+	Use:   "diff <note> [rev]",
+	Short: "Diff a note against an earlier git version",
+	Long: `Shows a unified diff from an earlier committed version of a note to the
+current file. The notebook must be inside a git repository.
+
+Without a revision the note is compared with its last commit when it has
+uncommitted changes, otherwise with the commit before that. A revision is
+anything git understands: a hash from "jot notes history", HEAD~3, a tag
+or a branch. Renames are followed, and encrypted notes are decrypted when
+the key is available.
+
+Examples:
+  # What changed last
+  jot notes diff "Sprint Planning"
+
+  # Changes since a commit
+  jot notes diff meetings/standup.md 3f2a1c9`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		note, err := resolveNoteArg(cmd, nb, args[0])
+		if err != nil {
+			return err
+		}
+		rev := ""
+		if len(args) > 1 {
+			rev = args[1]
+		}
+
+		before, commit, err := nb.NoteRevision(cmd.Context(), note.File.Relative, rev)
+		if err != nil {
+			return err
+		}
+		after, err := nb.Storage.Read(note.File.Relative)
+		if err != nil {
+			return fmt.Errorf("failed to read note: %w", err)
+		}
+
+		if string(before) == string(after) {
+			fmt.Printf("No changes: %s (since %s)\n", note.File.Relative, revisionLabel(commit))
+			return nil
+		}
+		fmt.Print(core.UnifiedDiff(revisionLabel(commit), note.File.Relative, string(before), string(after), 3))
+		return nil
+	},
+}
+
+// revisionLabel names the old side of a note diff.
+func revisionLabel(commit *services.Commit) string {
+	if commit == nil {
+		return "/dev/null"
+	}
+	return commit.Path + "@" + commit.Short()
+}
+
+func init() {
+	notesHistoryCmd.Flags().String("format", "list", "Output format: list or json")
+
+	notesCmd.AddCommand(notesHistoryCmd)
+	notesCmd.AddCommand(notesDiffCmd)
+}
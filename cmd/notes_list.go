@@ -73,6 +73,16 @@ func displayNoteList(notes []services.Note) error {
 // 4. context match (registered notebooks)
 // 5. ancestor search
 func requireNotebook(cmd *cobra.Command) (*services.Notebook, error) {
+	nb, err := openNotebook(cmd)
+	if err != nil {
+		return nil, err
+	}
+	// Remember it so its changes can be auto-committed when the command ends
+	openedNotebooks = append(openedNotebooks, nb)
+	return nb, nil
+}
+
+func openNotebook(cmd *cobra.Command) (*services.Notebook, error) {
 	// Step 1: Check JOT_NOTEBOOK envvar
 	if envNotebook := os.Getenv("JOT_NOTEBOOK"); envNotebook != "" {
 		return notebookService.Open(envNotebook)
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesMoveCmd = &cobra.Command{
+	Use:     "move <note> <destination>",
+	Aliases: []string{"mv", "rename"},
+	Short:   "Move or rename a note and update links to it",
+	Long: `Moves a note to a new path inside the notebook and rewrites every link
+that pointed to it.
+
+Markdown links, [[wikilinks]] and frontmatter "links" entries are updated
+in the style they were written in: relative links stay relative, a missing
+.md extension stays missing, and #anchors and |labels are kept. Links by
+title or alias keep working on their own and are left untouched.
+
+The note is resolved like "jot notes edit". The destination is relative to
+the notebook root; ".md" is added when missing and a trailing "/" moves the
+note into that directory keeping its file name. With --where or --view
+every matching note is moved into the destination directory.
+
+Examples:
+  # Rename a note
+  jot notes move meetings/standup.md meetings/daily-standup.md
+
+  # Move into a directory
+  jot notes mv "Sprint Planning" archive/
+
+  # Preview the link changes without writing anything
+  jot notes move todo.md tasks/todo.md --dry-run
+
+  # Archive every finished note
+  jot notes move --where 'status:done' archive/`,
+	Args: noteArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		notes, rest, err := targetNotes(cmd, nb, args)
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return fmt.Errorf("expected exactly one destination, got %d", len(rest))
+		}
+		dest := rest[0]
+		if hasSelection(cmd) && !strings.HasSuffix(dest, "/") {
+			// Several notes can only move into a directory
+			dest += "/"
+		}
+
+		// Check every destination before moving anything
+		moves := make(map[string]string, len(notes))
+		var order []string
+		for _, note := range notes {
+			from := note.File.Relative
+			if to := moveDestination(from, dest); from != to {
+				moves[from] = to
+				order = append(order, from)
+			}
+		}
+		if err := nb.Notes.CheckMoves(moves); err != nil {
+			return err
+		}
+
+		for _, from := range order {
+			plan, err := nb.Notes.PlanMove(cmd.Context(), from, moves[from])
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("Would move note: %s -> %s\n", plan.From, plan.To)
+				changes := make([]*services.NoteChange, len(plan.Changes))
+				for i := range plan.Changes {
+					changes[i] = &plan.Changes[i]
+				}
+				printChanges(changes)
+				continue
+			}
+
+			if err := nb.Notes.ApplyMove(cmd.Context(), plan); err != nil {
+				return err
+			}
+			printMove(plan)
+		}
+		return nil
+	},
+}
+
+func init() {
+	addSelectionFlags(notesMoveCmd)
+	notesCmd.AddCommand(notesMoveCmd)
+}
+
+// printMove reports a completed move and the notes whose links changed.
+func printMove(plan *services.MovePlan) {
+	fmt.Printf("Moved note: %s -> %s\n", plan.From, plan.To)
+	var updated []string
+	for _, change := range plan.Changes {
+		if change.OldPath != plan.From {
+			updated = append(updated, change.Path)
+		}
+	}
+	if len(updated) > 0 {
+		fmt.Printf("Updated links in %d note(s):\n", len(updated))
+		for _, p := range updated {
+			fmt.Printf("  %s\n", p)
+		}
+	}
+}
+
+// moveDestination turns the user's destination argument into a note path.
+func moveDestination(from, dest string) string {
+	dest = filepath.ToSlash(dest)
+	if strings.HasSuffix(dest, "/") {
+		return path.Join(dest, path.Base(from))
+	}
+	if !strings.HasSuffix(dest, ".md") {
+		dest += ".md"
+	}
+	return path.Clean(dest)
+}
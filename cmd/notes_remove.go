@@ -16,41 +16,102 @@ var notesRemoveCmd = &cobra.Command{
 	Short:   "Remove a note from the notebook",
 	Long: `Removes a markdown note from the current notebook.
 
+The note is moved to the notebook trash (.jot/trash) and dropped from the
+search index. Use "jot notes restore" to bring it back and
+"jot notes trash purge" to delete trashed notes for good.
+
 Prompts for confirmation unless --force is used. The .md extension
-is optional when specifying the note name.
+is optional when specifying the note name. A warning is shown when other
+notes still link to the note.
 
 Examples:
   # Remove with confirmation
   jot notes remove my-note
 
   # Remove without confirmation
-  jot notes remove my-note.md --force`,
-	Args: cobra.ExactArgs(1),
+  jot notes remove my-note.md --force
+
+  # Remove every note a query matches
+  jot notes remove --where 'status:obsolete' --dry-run
+
+  # Undo
+  jot notes restore my-note.md`,
+	Args: noteArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		nb, err := requireNotebook(cmd)
 		if err != nil {
 			return err
 		}
 
-		noteName := args[0]
 		force, _ := cmd.Flags().GetBool("force")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
 
-		// Ensure .md extension
-		if !strings.HasSuffix(noteName, ".md") {
-			noteName += ".md"
+		var noteNames []string
+		if hasSelection(cmd) {
+			if len(args) > 0 {
+				return fmt.Errorf("cannot combine a note argument with --where or --view")
+			}
+			notes, err := selectNotes(cmd, nb)
+			if err != nil {
+				return err
+			}
+			for _, note := range notes {
+				noteNames = append(noteNames, note.File.Relative)
+			}
+		} else {
+			noteName := args[0]
+
+			// Ensure .md extension
+			if !strings.HasSuffix(noteName, ".md") {
+				noteName += ".md"
+			}
+
+			// Check if file exists
+			if !nb.Storage.Exists(noteName) {
+				return fmt.Errorf("note not found: %s", filepath.Join(nb.Config.Root, noteName))
+			}
+			noteNames = []string{noteName}
 		}
 
-		// Build full path
-		notePath := filepath.Join(nb.Config.Root, noteName)
+		// Warn about links that will break, ignoring links between removed notes
+		removing := make(map[string]bool, len(noteNames))
+		for _, name := range noteNames {
+			removing[name] = true
+		}
+		for _, name := range noteNames {
+			backlinks, err := nb.Notes.Backlinks(cmd.Context(), name)
+			if err != nil {
+				continue
+			}
+			var sources []string
+			for _, ref := range backlinks {
+				if !removing[ref.Path] {
+					sources = append(sources, ref.Path)
+				}
+			}
+			if len(sources) > 0 {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: %d note(s) still link to %s:\n", len(sources), name)
+				for _, source := range sources {
+					fmt.Fprintf(os.Stderr, "  %s\n", source)
+				}
+			}
+		}
 
-		// Check if file exists
-		if _, err := os.Stat(notePath); os.IsNotExist(err) {
-			return fmt.Errorf("note not found: %s", notePath)
+		if dryRun {
+			fmt.Printf("Would remove %d note(s):\n", len(noteNames))
+			for _, name := range noteNames {
+				fmt.Printf("  %s\n", name)
+			}
+			return nil
 		}
 
 		// Confirm deletion unless --force is used
 		if !force {
-			fmt.Printf("Remove note '%s'? [y/N]: ", noteName)
+			if len(noteNames) == 1 {
+				fmt.Printf("Remove note '%s'? [y/N]: ", noteNames[0])
+			} else {
+				fmt.Printf("Remove %d notes? [y/N]: ", len(noteNames))
+			}
 			reader := bufio.NewReader(os.Stdin)
 			response, err := reader.ReadString('\n')
 			if err != nil {
@@ -64,17 +125,19 @@ Examples:
 			}
 		}
 
-		// Remove the file
-		if err := os.Remove(notePath); err != nil {
-			return fmt.Errorf("failed to remove note: %w", err)
+		for _, name := range noteNames {
+			if _, err := nb.Notes.Trash(cmd.Context(), name); err != nil {
+				return fmt.Errorf("failed to remove note: %w", err)
+			}
+			fmt.Printf("Removed note: %s (moved to trash, restore with: jot notes restore %s)\n",
+				filepath.Join(nb.Config.Root, name), name)
 		}
-
-		fmt.Printf("Removed note: %s\n", notePath)
 		return nil
 	},
 }
 
 func init() {
 	notesRemoveCmd.Flags().BoolP("force", "f", false, "Skip confirmation prompt")
+	addSelectionFlags(notesRemoveCmd)
 	notesCmd.AddCommand(notesRemoveCmd)
 }
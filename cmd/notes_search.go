@@ -54,6 +54,9 @@ DSL PIPE SYNTAX EXAMPLES:
   DSL Filter:
   - tag:<value>      Notes with specific tag
   - status:<value>   Notes with status field
+  - id:<value>       Note with a stable id
+  - due:<date>       Notes with a "due" field (supports >, >=, <, <=)
+  - owner:<value>    Notes with an "owner" field
   - title:<text>     Search in title
   - path:<prefix>    Notes in path prefix
   - created:>date    Created after date
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var notesShowCmd = &cobra.Command{
+	Use:     "show <note>",
+	Aliases: []string{"cat"},
+	Short:   "Display a single note",
+	Long: `Displays a note with its frontmatter, body, backlinks and related notes.
+
+The note is resolved by path (the .md extension is optional), then by
+exact title or alias, then by fuzzy match. When several notes match you
+are asked to pick one.
+
+The footer lists notes linking to this one (backlinks) and notes sharing
+its tags (related notes).
+
+OUTPUT MODES:
+  (default)           Rendered markdown
+  --raw               The file exactly as stored (decrypted if needed)
+  --json              Path, title, metadata, body, backlinks and related notes as JSON
+  --frontmatter-only  Only the YAML frontmatter (combine with --json for a JSON object)
+
+Examples:
+  # Show by title
+  jot notes show "Sprint Planning"
+
+  # Show by alias or path
+  jot notes show standup
+  jot notes show meetings/standup.md
+
+  # Pipe frontmatter into yq
+  jot notes show standup --frontmatter-only | yq .tags`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		raw, _ := cmd.Flags().GetBool("raw")
+		asJSON, _ := cmd.Flags().GetBool("json")
+		frontmatterOnly, _ := cmd.Flags().GetBool("frontmatter-only")
+
+		if raw && (asJSON || frontmatterOnly) {
+			return fmt.Errorf("--raw cannot be combined with --json or --frontmatter-only")
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		note, err := resolveNoteArg(cmd, nb, strings.Join(args, " "))
+		if err != nil {
+			return err
+		}
+
+		detail, err := nb.Notes.Detail(cmd.Context(), note.File.Relative)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+
+		switch {
+		case raw:
+			_, err = fmt.Fprint(out, detail.Raw)
+			return err
+
+		case asJSON:
+			var value any = detail
+			if frontmatterOnly {
+				value = detail.Metadata
+			}
+			data, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode note: %w", err)
+			}
+			_, err = fmt.Fprintln(out, string(data))
+			return err
+
+		case frontmatterOnly:
+			_, err = fmt.Fprint(out, detail.RawFrontmatter())
+			return err
+		}
+
+		output, err := services.TuiRender("note-detail", detail)
+		if err != nil {
+			return fmt.Errorf("failed to render note: %w", err)
+		}
+		_, err = fmt.Fprint(out, output)
+		return err
+	},
+}
+
+func init() {
+	notesShowCmd.Flags().Bool("raw", false, "Print the note exactly as stored")
+	notesShowCmd.Flags().Bool("json", false, "Output the note as JSON")
+	notesShowCmd.Flags().Bool("frontmatter-only", false, "Only output the frontmatter")
+	notesCmd.AddCommand(notesShowCmd)
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+var notesTrashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage removed notes",
+	Long: `Removed notes are kept in the notebook trash (.jot/trash) together with
+their original path and removal time until they are purged.
+
+Examples:
+  # Show trashed notes
+  jot notes trash list
+
+  # Delete notes trashed more than 30 days ago
+  jot notes trash purge --older-than 30d
+
+  # Bring a note back
+  jot notes restore meetings/standup.md`,
+}
+
+var notesTrashListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List trashed notes",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		entries, err := nb.Notes.TrashList()
+		if err != nil {
+			return err
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("Trash is empty.")
+			return nil
+		}
+
+		for _, e := range entries {
+			fmt.Printf("%s  %s  (%s)\n", e.RemovedAt.Local().Format("2006-01-02 15:04"), e.Path, e.ID)
+		}
+		return nil
+	},
+}
+
+var notesTrashPurgeCmd = &cobra.Command{
+	Use:   "purge",
+	Short: "Permanently delete trashed notes",
+	Long: `Permanently deletes trashed notes. Without --older-than the whole trash
+is emptied.
+
+Durations accept days and weeks in addition to Go durations: 30d, 2w, 12h.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		olderThanFlag, _ := cmd.Flags().GetString("older-than")
+
+		var olderThan time.Duration
+		if olderThanFlag != "" {
+			d, err := core.ParseDuration(olderThanFlag)
+			if err != nil {
+				return err
+			}
+			olderThan = d
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		purged, err := nb.Notes.PurgeTrash(olderThan, time.Now())
+		for _, e := range purged {
+			fmt.Printf("Purged: %s (%s)\n", e.Path, e.ID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Purged %d note(s) from trash\n", len(purged))
+		return nil
+	},
+}
+
+var notesRestoreCmd = &cobra.Command{
+	Use:   "restore <note|id>",
+	Short: "Restore a note from the trash",
+	Long: `Moves a trashed note back to its original path and re-indexes it.
+
+The note is identified by its original path (the .md extension is optional)
+or by the id shown in "jot notes trash list". When the same path was
+removed several times, the most recent copy is restored.
+
+Examples:
+  jot notes restore meetings/standup.md
+  jot notes restore 20240115-093000-standup`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		entry, err := nb.Notes.Restore(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Restored note: %s\n", entry.Path)
+		return nil
+	},
+}
+
+func init() {
+	notesTrashPurgeCmd.Flags().String("older-than", "", "Only purge notes removed longer ago than this (e.g. 30d)")
+	notesTrashCmd.AddCommand(notesTrashListCmd)
+	notesTrashCmd.AddCommand(notesTrashPurgeCmd)
+	notesCmd.AddCommand(notesTrashCmd)
+	notesCmd.AddCommand(notesRestoreCmd)
+}
@@ -53,6 +53,8 @@ BUILT-IN VIEWS:
   untagged         missing:tag | sort:created:desc
   orphans          (special) Notes with no incoming links
   broken-links     (special) Notes with broken references
+  tasks            (special) Open tasks grouped by note; --group due groups
+                   by due date (see "jot tasks list")
 
 DSL FILTER SYNTAX:
 
@@ -129,6 +131,7 @@ EXAMPLES:
   jot notes view kanban                             # Notes grouped by status
   jot notes view untagged                           # Notes without tags
   jot notes view orphans --format json              # Orphaned notes as JSON
+  jot notes view tasks --group due                  # Open tasks by due date
   jot notes view my-workflow --param sprint=Q1-S3   # Custom view with params
   jot notes view --save work-inbox "tag:work status:todo | sort:created:desc"
   jot notes view --save work-inbox "tag:work | sort:modified:desc" --description "Work queue"
@@ -212,7 +215,10 @@ EXAMPLES:
 			return fmt.Errorf("failed to execute view '%s': %w", viewName, err)
 		}
 
-		// Render results based on whether they are grouped or flat
+		// Render results based on whether they are tasks, grouped or flat
+		if results.TaskGroups != nil {
+			return displayTaskViewResults(viewName, results.TaskGroups, viewFormat)
+		}
 		if len(results.Groups) > 0 {
 			return displayGroupedViewResults(viewName, results.Groups, viewFormat)
 		}
@@ -499,6 +505,27 @@ func displayViewResultsJSON(notes []services.Note) error {
 	return nil
 }
 
+// displayTaskViewResults displays the task groups of the tasks view
+func displayTaskViewResults(viewName string, groups map[string][]services.NoteTask, format string) error {
+	total := 0
+	for _, tasks := range groups {
+		total += len(tasks)
+	}
+
+	if format == "json" {
+		return printJSON(map[string]any{"groups": groups, "count": total})
+	}
+
+	if total == 0 {
+		fmt.Printf("View '%s': No tasks found\n", viewName)
+		return nil
+	}
+
+	fmt.Printf("View '%s' (%d tasks in %d groups):\n\n", viewName, total, len(groups))
+	printTaskGroups(groups)
+	return nil
+}
+
 // displayGroupedViewResults displays grouped view results (e.g., kanban)
 func displayGroupedViewResults(viewName string, groups map[string][]services.Note, format string) error {
 	// Count total notes
@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+	"golang.org/x/term"
+)
+
+// periodOffsetPattern matches offset arguments such as -1, +2 or 3.
+var periodOffsetPattern = regexp.MustCompile(`^[+-]?\d+$`)
+
+// periodUnits names a single period in help text.
+var periodUnits = map[string]string{
+	services.PeriodDaily:   "day",
+	services.PeriodWeekly:  "week",
+	services.PeriodMonthly: "month",
+}
+
+func newPeriodicCmd(period string) *cobra.Command {
+	unit := periodUnits[period]
+	cmd := &cobra.Command{
+		Use:   period + " [offset]",
+		Short: fmt.Sprintf("Open or create the %s note", period),
+		Long: fmt.Sprintf(`Opens the %[1]s note for the current %[2]s, creating it first if needed.
+
+The offset moves by whole %[2]ss from today, or from --date when given.
+--prev and --next jump to the nearest existing %[1]s note instead.
+
+New notes get "title", "period", "date" and "created" frontmatter, with
+"created" set to the start of the %[2]s so views and date queries such as
+created:>=this-month find them by the %[2]s they cover. Notebook groups
+matching the path apply as they do for "jot notes add".
+
+The path and template are configured in .jot.json:
+
+  "periodic": {
+    "%[1]s": { "path": "%[3]s", "template": "%[1]s" }
+  }
+
+The path is a Go template with .Date (YYYY-MM-DD), .Year, .Month, .Day,
+.Week and .WeekYear (ISO week) and a date "layout" function.
+
+The note opens in $VISUAL or $EDITOR when stdin is a terminal; otherwise,
+or with --no-edit, its path is printed.
+
+Examples:
+  jot %[1]s                     # this %[2]s
+  jot %[1]s -1                  # the previous %[2]s
+  jot %[1]s --date 2024-05-01   # the %[2]s containing a date
+  jot %[1]s --prev              # the last %[1]s note before this %[2]s
+  jot %[1]s --no-edit           # create and print the path`, period, unit, defaultPeriodicPath(period)),
+		Args: cobra.ArbitraryArgs,
+		// Offsets like -1 would be read as shorthand flags, so flags are
+		// parsed in RunE once offsets are taken out
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPeriodic(cmd, period, args)
+		},
+	}
+
+	cmd.Flags().String("date", "", "Date within the period (YYYY-MM-DD), defaults to today")
+	cmd.Flags().Bool("prev", false, "Open the nearest existing note before the period")
+	cmd.Flags().Bool("next", false, "Open the nearest existing note after the period")
+	cmd.Flags().Bool("no-edit", false, "Create the note if needed and print its path without opening it")
+	return cmd
+}
+
+func init() {
+	for _, period := range []string{services.PeriodDaily, services.PeriodWeekly, services.PeriodMonthly} {
+		rootCmd.AddCommand(newPeriodicCmd(period))
+	}
+}
+
+// defaultPeriodicPath returns the default path pattern of a period.
+func defaultPeriodicPath(period string) string {
+	var cfg services.StoredNotebookConfig
+	return cfg.Periodic(period).Path
+}
+
+// runPeriodic resolves, creates if needed and opens a periodic note.
+func runPeriodic(cmd *cobra.Command, period string, args []string) error {
+	offset := 0
+	var flagArgs []string
+	for _, arg := range args {
+		if periodOffsetPattern.MatchString(arg) {
+			n, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("invalid offset %q", arg)
+			}
+			offset += n
+			continue
+		}
+		flagArgs = append(flagArgs, arg)
+	}
+
+	// ParseFlags is a no-op with DisableFlagParsing; InheritedFlags merges
+	// the persistent flags such as --notebook first
+	cmd.InheritedFlags()
+	if err := cmd.Flags().Parse(flagArgs); err != nil {
+		return err
+	}
+	if help, _ := cmd.Flags().GetBool("help"); help {
+		return cmd.Help()
+	}
+	if extra := cmd.Flags().Args(); len(extra) > 0 {
+		return fmt.Errorf("unexpected argument %q: expected an offset such as -1 or +2", extra[0])
+	}
+
+	dateFlag, _ := cmd.Flags().GetString("date")
+	prev, _ := cmd.Flags().GetBool("prev")
+	next, _ := cmd.Flags().GetBool("next")
+	noEdit, _ := cmd.Flags().GetBool("no-edit")
+	if prev && next {
+		return fmt.Errorf("--prev and --next cannot be used together")
+	}
+
+	date := time.Now()
+	if dateFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateFlag, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --date %q: expected YYYY-MM-DD", dateFlag)
+		}
+		date = parsed
+	}
+	date = services.ShiftPeriod(period, date, offset)
+
+	nb, err := requireNotebook(cmd)
+	if err != nil {
+		return err
+	}
+
+	var relPath string
+	if prev || next {
+		dir := 1
+		if prev {
+			dir = -1
+		}
+		adjacent, err := nb.Notes.AdjacentPeriodicNote(cmd.Context(), period, date, dir)
+		if err != nil {
+			return err
+		}
+		relPath = adjacent.Path
+	} else {
+		note, err := nb.Config.PeriodicNote(period, date)
+		if err != nil {
+			return err
+		}
+		relPath = note.Path
+		if !nb.Storage.Exists(relPath) {
+			if err := createPeriodicNote(cmd, nb, note); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Created note: %s\n", relPath)
+		}
+	}
+
+	if noEdit || !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Println(relPath)
+		return nil
+	}
+	return editNote(cmd, nb, relPath)
+}
+
+// createPeriodicNote writes a new periodic note. Its content comes from the
+// period's template, else the template of a matching group, else a heading.
+func createPeriodicNote(cmd *cobra.Command, nb *services.Notebook, note services.PeriodicNote) error {
+	groupDefaults := nb.Config.GroupDefaults(note.Path)
+	metadata := make(map[string]interface{}, len(groupDefaults.Metadata)+4)
+	for k, v := range groupDefaults.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range note.Frontmatter() {
+		metadata[k] = v
+	}
+	if err := assignNoteID(cmd, nb, metadata); err != nil {
+		return err
+	}
+
+	templates := nb.NoteTemplates()
+	templateName := nb.Config.Periodic(note.Period).Template
+	if templateName == "" && templates.Has(groupDefaults.Template) {
+		templateName = groupDefaults.Template
+	}
+
+	content := fmt.Sprintf("# %s\n\n", note.Title)
+	if templateName != "" {
+		templates.Prompt = terminalPrompt()
+		data := services.NewTemplateData(nb.Config.Name, note.Title, note.Path, nil)
+		// Date helpers such as {{today}} and {{today-1}} refer to the period
+		data.Now = note.Date
+		rendered, err := templates.Render(templateName, data)
+		if err != nil {
+			return err
+		}
+		content = rendered
+	}
+
+	finalContent, err := withFrontmatter(content, metadata)
+	if err != nil {
+		return fmt.Errorf("template %q: %w", templateName, err)
+	}
+	if violations := nb.Config.ValidateContent(note.Path, []byte(finalContent)); len(violations) > 0 {
+		return schemaError(note.Path, violations)
+	}
+
+	if err := nb.Storage.Write(note.Path, []byte(finalContent)); err != nil {
+		return fmt.Errorf("failed to create note: %w", err)
+	}
+	if err := nb.Notes.IndexFile(cmd.Context(), note.Path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to index note: %v\n", err)
+	}
+	return nil
+}
@@ -4,6 +4,11 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"github.com/zenobi-us/jot/internal/services"
 )
@@ -20,6 +25,9 @@ var (
 	// Services initialized in PersistentPreRunE
 	cfgService      *services.ConfigService
 	notebookService *services.NotebookService
+
+	// openedNotebooks are the notebooks the command opened, for auto-commit
+	openedNotebooks []*services.Notebook
 )
 
 var rootCmd = &cobra.Command{
@@ -71,9 +79,40 @@ Examples:
 
 		// Initialize notebook service
 		notebookService = services.NewNotebookService(cfgService)
+		openedNotebooks = nil
 
 		return nil
 	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		// The command succeeded; a failed commit should not fail it
+		message := autoCommitMessage(cmd, args)
+		for _, nb := range openedNotebooks {
+			if _, err := nb.AutoCommit(cmd.Context(), message); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: auto-commit failed: %v\n", err)
+			}
+		}
+		return nil
+	},
+}
+
+// maxCommitSubject is the longest auto-commit subject line.
+const maxCommitSubject = 72
+
+// autoCommitMessage describes a command run as a commit subject, such as
+// "jot notes set todo.md status=done".
+func autoCommitMessage(cmd *cobra.Command, args []string) string {
+	parts := []string{cmd.CommandPath()}
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	subject := strings.Join(parts, " ")
+	if runes := []rune(subject); len(runes) > maxCommitSubject {
+		subject = string(runes[:maxCommitSubject-1]) + "…"
+	}
+	return subject
 }
 
 // Execute runs the root command.
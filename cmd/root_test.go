@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoCommitMessage(t *testing.T) {
+	assert.Equal(t, `jot notes set todo.md status=done "owner=Sam Smith"`,
+		autoCommitMessage(notesSetCmd, []string{"todo.md", "status=done", "owner=Sam Smith"}))
+	assert.Equal(t, `jot notes add ""`, autoCommitMessage(notesAddCmd, []string{""}))
+
+	long := autoCommitMessage(notesAddCmd, []string{strings.Repeat("é", 100)})
+	assert.Equal(t, maxCommitSubject, len([]rune(long)))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
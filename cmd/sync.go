@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Commit, pull and push a git-backed notebook",
+	Long: `Synchronises a notebook kept in a git repository with its remote.
+
+Sync commits every change under the notes root, pulls the upstream branch
+with rebase and pushes. Notes changed by the pull are re-indexed.
+
+When a note was changed on both sides, the changes are merged instead of
+stopping the rebase: frontmatter field by field and the body line by line.
+Fields changed on one side take that change, lists such as tags combine
+both sides and "modified" keeps the later date. When both sides changed
+the same field or lines differently the rebase is aborted, your commit is
+kept, and the conflicting notes are listed for "git pull --rebase".
+
+The current branch needs an upstream ("git push -u origin main" once).
+
+Examples:
+  # Commit, pull and push
+  jot sync
+
+  # Describe the local changes
+  jot sync -m "Notes from the planning meeting"
+
+  # Pull only
+  jot sync --no-push`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		message, _ := cmd.Flags().GetString("message")
+		noPush, _ := cmd.Flags().GetBool("no-push")
+		format, _ := cmd.Flags().GetString("format")
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected text or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		result, err := nb.Sync(cmd.Context(), services.SyncOptions{Message: message, NoPush: noPush})
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return printJSON(result)
+		}
+		printSyncResult(result)
+		return nil
+	},
+}
+
+func printSyncResult(result *services.SyncResult) {
+	if result.Committed {
+		fmt.Println("Committed local changes")
+	}
+	if len(result.Pulled) == 0 {
+		fmt.Printf("Up to date with %s\n", result.Upstream)
+	} else {
+		fmt.Printf("Pulled %d changed notes from %s\n", len(result.Pulled), result.Upstream)
+		for _, relPath := range result.Pulled {
+			fmt.Printf("  %s\n", relPath)
+		}
+	}
+	for _, relPath := range result.Merged {
+		fmt.Printf("Merged conflicting changes in %s\n", relPath)
+	}
+	if result.Pushed {
+		fmt.Printf("Pushed to %s\n", result.Upstream)
+	}
+}
+
+func init() {
+	syncCmd.Flags().StringP("message", "m", "", "Commit message for local changes (default \"jot sync\")")
+	syncCmd.Flags().Bool("no-push", false, "Commit and pull without pushing")
+	syncCmd.Flags().String("format", "text", "Output format: text or json")
+
+	rootCmd.AddCommand(syncCmd)
+}
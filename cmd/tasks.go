@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+var tasksCmd = &cobra.Command{
+	Use:   "tasks",
+	Short: "List and complete task list items across all notes",
+	Long: `Works with the GFM task list items ("- [ ] ...") of every note.
+
+Tasks are read when notes are indexed, together with inline annotations:
+
+  - [ ] Send the report due:2024-05-01 @sam #finance
+
+"due:" takes a YYYY-MM-DD date, "@name" names an owner and "#tag" adds a
+tag on top of the note's own tags. Items in fenced code blocks are ignored.
+
+Examples:
+  jot tasks list
+  jot tasks list --where 'due:<=today owner:sam'
+  jot tasks done 3f2a1c9`,
+}
+
+var tasksListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List tasks, grouped by note or due date",
+	Long: `Lists open tasks grouped by note. --where filters with the search DSL,
+evaluated against each task and its note:
+
+  status:open|done    Task state (without status: only open tasks are listed,
+                      unless --all is given)
+  due:<date>          Due date; supports >, >=, < and <=
+  owner:<name>        An "@name" annotation
+  tag:<tag>           A "#tag" annotation or a tag of the note
+  path:<prefix|glob>  The note path
+  title:<text>        The note title
+  id:<id>             The task ID or the note's ID
+  has:due / missing:owner
+  <word>              Text of the task
+
+Dates are YYYY-MM-DD, today, tomorrow, yesterday, this-week, next-week,
+last-week, this-month, next-month, last-month, or today+N / this-week-N.
+Week and month names cover the whole period: due:this-week matches any day
+of the week and due:<this-week only earlier days.
+
+Each task is shown with its ID, which "jot tasks done" accepts.
+
+Examples:
+  # Overdue tasks
+  jot tasks list --where 'due:<today'
+
+  # Sam's tasks due in the next week, by due date
+  jot tasks list --where 'owner:sam due:<=today+7' --group due
+
+  # Finished work tasks
+  jot tasks list --where 'status:done tag:work'
+
+  # Everything, as JSON
+  jot tasks list --all --format json`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		where, _ := cmd.Flags().GetString("where")
+		all, _ := cmd.Flags().GetBool("all")
+		group, _ := cmd.Flags().GetString("group")
+		format, _ := cmd.Flags().GetString("format")
+
+		if format != "list" && format != "json" {
+			return fmt.Errorf("unknown format %q (expected list or json)", format)
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		tasks, err := nb.Notes.Tasks(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !all && !services.TaskQueryMentions(where, "status") {
+			if tasks, err = services.FilterTasks(tasks, "status:open", now); err != nil {
+				return err
+			}
+		}
+		if tasks, err = services.FilterTasks(tasks, where, now); err != nil {
+			return err
+		}
+
+		if format == "json" {
+			if tasks == nil {
+				tasks = []services.NoteTask{}
+			}
+			return printJSON(map[string]any{"tasks": tasks, "count": len(tasks)})
+		}
+
+		groups, err := services.GroupTasks(tasks, group)
+		if err != nil {
+			return err
+		}
+		if len(tasks) == 0 {
+			fmt.Println("No tasks found")
+			return nil
+		}
+		printTaskGroups(groups)
+		return nil
+	},
+}
+
+var tasksDoneCmd = &cobra.Command{
+	Use:   "done <id|path:line>...",
+	Short: "Tick the box of one or more tasks",
+	Long: `Ticks the box of each task in place, turning "- [ ]" into "- [x]",
+and stamps the note's "modified" field. A task is given by the ID shown by
+"jot tasks list" or as path:line.
+
+Examples:
+  jot tasks done 3f2a1c9
+  jot tasks done projects/launch.md:14 --dry-run`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range args {
+			change, task, err := nb.Notes.PlanTaskDone(cmd.Context(), ref)
+			if err != nil {
+				return err
+			}
+
+			location := fmt.Sprintf("%s:%d", task.Path, task.Line)
+			switch {
+			case change == nil:
+				fmt.Printf("Already done: %s (%s)\n", task.Text, location)
+			case dryRun:
+				fmt.Printf("Would tick: %s (%s)\n", task.Text, location)
+				printChanges([]*services.NoteChange{change})
+			default:
+				if err := nb.Notes.ApplyChange(cmd.Context(), change); err != nil {
+					return err
+				}
+				fmt.Printf("Done: %s (%s)\n", task.Text, location)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	tasksListCmd.Flags().String("where", "", "Filter tasks with the search DSL")
+	tasksListCmd.Flags().Bool("all", false, "Include done tasks when --where has no status: filter")
+	tasksListCmd.Flags().String("group", services.TaskGroupNote, "Group by note or due")
+	tasksListCmd.Flags().String("format", "list", "Output format: list or json")
+	tasksDoneCmd.Flags().Bool("dry-run", false, "Show the change without writing it")
+
+	tasksCmd.AddCommand(tasksListCmd)
+	tasksCmd.AddCommand(tasksDoneCmd)
+	rootCmd.AddCommand(tasksCmd)
+}
+
+// printTaskGroups prints tasks under their group headings. Tasks grouped by
+// note show their line; tasks grouped by due date show path and line.
+func printTaskGroups(groups map[string][]services.NoteTask) {
+	for i, key := range services.SortedTaskGroupKeys(groups) {
+		if i > 0 {
+			fmt.Println()
+		}
+
+		tasks := groups[key]
+		byNote := key == tasks[0].Path
+		if byNote {
+			fmt.Printf("%s (%s)\n", key, tasks[0].Title)
+		} else {
+			fmt.Println(key)
+		}
+
+		for _, task := range tasks {
+			box := "[ ]"
+			if task.Done {
+				box = "[x]"
+			}
+			location := fmt.Sprintf("%s:%d", task.Path, task.Line)
+			if byNote {
+				location = fmt.Sprintf("line %d", task.Line)
+			}
+			fmt.Printf("  %s %s  %s  (%s)\n", box, task.ID, task.Text, location)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/parser"
+	"github.com/zenobi-us/jot/internal/services"
+	"golang.org/x/term"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:     "tui [query]",
+	Aliases: []string{"browse"},
+	Short:   "Browse, search and preview notes interactively",
+	Long: `Opens a full screen note browser: a search box, the matching notes and a
+rendered preview of the selected note.
+
+Typing filters the list as you go. Plain words fuzzy match titles and
+paths; a query containing ":" uses the search DSL ("tag:work status:todo").
+
+Keys:
+  up/down, ctrl+p/ctrl+n   Select a note
+  pgup/pgdown              Scroll the preview
+  enter                    Open the note in $VISUAL or $EDITOR
+  tab / shift+tab          Cycle through saved views
+  ctrl+l                   List the notes the selected note links to
+  ctrl+b                   List the notes linking to the selected note
+  ctrl+u                   Clear the search box
+  esc                      Go back, clear the search, then quit
+  ctrl+c                   Quit
+
+Examples:
+  jot tui
+  jot tui "tag:meeting"
+  jot browse --view kanban`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			return fmt.Errorf("jot tui needs an interactive terminal")
+		}
+
+		nb, err := requireNotebook(cmd)
+		if err != nil {
+			return err
+		}
+
+		viewName, _ := cmd.Flags().GetString("view")
+		source := newNotebookBrowseSource(nb)
+		model := newBrowseModel(cmd.Context(), source, func(relPath string) tea.Cmd {
+			return tea.Exec(&browseEditor{run: func() error { return editNote(cmd, nb, relPath) }},
+				func(err error) tea.Msg { return browseEditedMsg{path: relPath, err: err} })
+		})
+
+		if len(args) > 0 {
+			model.query = args[0]
+		}
+		if viewName != "" {
+			found := false
+			for i, name := range model.views {
+				if name == viewName {
+					model.mode, model.view, found = browseView, i, true
+				}
+			}
+			if !found {
+				return fmt.Errorf("view not found: %s", viewName)
+			}
+		}
+
+		_, err = tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+		return err
+	},
+}
+
+func init() {
+	tuiCmd.Flags().String("view", "", "Start in this saved view")
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// browseEditor runs the editor while the browser has released the terminal.
+type browseEditor struct {
+	run func() error
+}
+
+func (e *browseEditor) Run() error          { return e.run() }
+func (e *browseEditor) SetStdin(io.Reader)  {}
+func (e *browseEditor) SetStdout(io.Writer) {}
+func (e *browseEditor) SetStderr(io.Writer) {}
+
+// notebookBrowseSource serves the browser from a notebook's index.
+type notebookBrowseSource struct {
+	nb    *services.Notebook
+	views *services.ViewService
+
+	// style is the glamour style of previews, picked before the browser
+	// takes over the terminal so rendering never queries it.
+	style    string
+	mu       sync.Mutex
+	displays map[int]*services.Display // by wrap width
+}
+
+func newNotebookBrowseSource(nb *services.Notebook) *notebookBrowseSource {
+	views := services.NewViewService(cfgService, filepath.Dir(nb.Config.Path))
+	views.SetExecutionContext(nb.Notes.GetIndex(), nb.Notes)
+
+	style := styles.LightStyle
+	if lipgloss.HasDarkBackground() {
+		style = styles.DarkStyle
+	}
+	return &notebookBrowseSource{nb: nb, views: views, style: style, displays: map[int]*services.Display{}}
+}
+
+// Search fuzzy matches plain words against titles and paths and runs
+// queries containing ":" through the search DSL. An empty query lists every
+// note, most recently modified first.
+func (s *notebookBrowseSource) Search(ctx context.Context, query string) ([]browseItem, error) {
+	query = strings.TrimSpace(query)
+	if query != "" && !strings.Contains(query, ":") {
+		notes, err := s.nb.Notes.SearchNotes(ctx, query, true)
+		if err != nil {
+			return nil, err
+		}
+		return noteBrowseItems(notes), nil
+	}
+
+	opts := search.FindOpts{Sort: directiveToSortSpec("modified", "desc")}
+	if query != "" {
+		parsed, err := parser.New().Parse(query)
+		if err != nil {
+			return nil, err
+		}
+		opts.Query = parsed
+		opts.RawQuery = query
+	}
+
+	notes, err := s.nb.Notes.SearchWithFindOpts(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return noteBrowseItems(notes), nil
+}
+
+// Views lists every saved and built-in view by name.
+func (s *notebookBrowseSource) Views() []string {
+	infos, err := s.views.ListAllViews()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, len(infos))
+	for i, info := range infos {
+		names[i] = info.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// View runs a view with its default parameters. Grouped results are listed
+// group by group; the tasks view lists the notes holding the tasks.
+func (s *notebookBrowseSource) View(ctx context.Context, name string) ([]browseItem, error) {
+	view, err := s.views.GetView(name)
+	if err != nil {
+		return nil, err
+	}
+	params := s.views.ApplyParameterDefaults(view, map[string]string{})
+	results, err := s.views.ExecuteViewWithOverrides(ctx, view, params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	items := noteBrowseItems(results.Notes)
+	for _, key := range sortedKeysOf(results.Groups) {
+		items = append(items, noteBrowseItems(results.Groups[key])...)
+	}
+
+	seen := map[string]bool{}
+	for _, key := range services.SortedTaskGroupKeys(results.TaskGroups) {
+		for _, task := range results.TaskGroups[key] {
+			if !seen[task.Path] {
+				seen[task.Path] = true
+				items = append(items, browseItem{Path: task.Path, Title: task.Title})
+			}
+		}
+	}
+	return items, nil
+}
+
+// Links lists the notes the note at relPath links to.
+func (s *notebookBrowseSource) Links(ctx context.Context, relPath string) ([]browseItem, error) {
+	refs, err := s.nb.Notes.OutgoingLinks(ctx, relPath)
+	return noteRefBrowseItems(refs), err
+}
+
+// Backlinks lists the notes linking to the note at relPath.
+func (s *notebookBrowseSource) Backlinks(ctx context.Context, relPath string) ([]browseItem, error) {
+	refs, err := s.nb.Notes.Backlinks(ctx, relPath)
+	return noteRefBrowseItems(refs), err
+}
+
+// Preview renders the note body with Display.Render, wrapped to width.
+func (s *notebookBrowseSource) Preview(ctx context.Context, relPath string, width int) (string, error) {
+	detail, err := s.nb.Notes.Detail(ctx, relPath)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	display, ok := s.displays[width]
+	if !ok {
+		if display, err = services.NewDisplayWithWidth(width, s.style); err == nil {
+			s.displays[width] = display
+		}
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return detail.Body, nil
+	}
+
+	rendered, err := display.Render(detail.Body)
+	if err != nil {
+		return detail.Body, nil
+	}
+	return rendered, nil
+}
+
+func noteRefBrowseItems(refs []services.NoteRef) []browseItem {
+	items := make([]browseItem, len(refs))
+	for i, ref := range refs {
+		items[i] = browseItem{Path: ref.Path, Title: ref.Title}
+	}
+	return items
+}
+
+func sortedKeysOf[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,433 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zenobi-us/jot/internal/services"
+)
+
+// browseSource supplies what the note browser shows. The notebook
+// implements it for "jot tui"; tests use a stub.
+type browseSource interface {
+	// Search returns the notes matching the search box.
+	Search(ctx context.Context, query string) ([]browseItem, error)
+	// Views names the saved views the browser cycles through.
+	Views() []string
+	// View returns the notes of a saved view.
+	View(ctx context.Context, name string) ([]browseItem, error)
+	// Links returns the notes the note at relPath links to.
+	Links(ctx context.Context, relPath string) ([]browseItem, error)
+	// Backlinks returns the notes linking to the note at relPath.
+	Backlinks(ctx context.Context, relPath string) ([]browseItem, error)
+	// Preview renders the note at relPath for a pane width columns wide.
+	Preview(ctx context.Context, relPath string, width int) (string, error)
+}
+
+// browseItem is a note in the browser's result list.
+type browseItem struct {
+	Path  string
+	Title string
+}
+
+// browseMode is what the result list currently shows.
+type browseMode int
+
+const (
+	browseSearch    browseMode = iota // notes matching the search box
+	browseView                        // notes of a saved view
+	browseLinks                       // notes linked from a note
+	browseBacklinks                   // notes linking to a note
+)
+
+// browseState is the part of the browser that "esc" restores after
+// following links or backlinks.
+type browseState struct {
+	mode   browseMode
+	query  string
+	view   int
+	origin string
+	items  []browseItem
+	cursor int
+}
+
+// Messages produced by the browser's commands.
+type (
+	browseResultsMsg struct {
+		seq   int
+		items []browseItem
+		err   error
+	}
+	browsePreviewMsg struct {
+		path    string
+		width   int
+		content string
+		err     error
+	}
+	browseEditedMsg struct {
+		path string
+		err  error
+	}
+)
+
+// browseModel is the bubbletea model of "jot tui". All I/O happens in the
+// commands it returns, so tests drive it by feeding messages to Update.
+type browseModel struct {
+	ctx    context.Context
+	source browseSource
+	// edit opens a note in the editor and reports a browseEditedMsg.
+	edit func(relPath string) tea.Cmd
+
+	browseState
+	views   []string
+	history []browseState
+	seq     int
+
+	preview      string
+	previewPath  string
+	previewWidth int
+	scroll       int
+
+	width, height int
+	status        string
+}
+
+func newBrowseModel(ctx context.Context, source browseSource, edit func(string) tea.Cmd) *browseModel {
+	return &browseModel{
+		ctx:    ctx,
+		source: source,
+		edit:   edit,
+		views:  source.Views(),
+		width:  80,
+		height: 24,
+	}
+}
+
+// Init loads the initial result list.
+func (m *browseModel) Init() tea.Cmd {
+	if m.mode == browseView {
+		return m.loadView()
+	}
+	return m.search()
+}
+
+// Update handles a message and returns the commands it triggers.
+func (m *browseModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, m.loadPreview()
+
+	case browseResultsMsg:
+		if msg.seq != m.seq {
+			return m, nil // superseded by a newer query
+		}
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			return m, nil
+		}
+		m.items = msg.items
+		m.cursor = min(m.cursor, max(len(m.items)-1, 0))
+		return m, m.loadPreview()
+
+	case browsePreviewMsg:
+		if msg.path != m.selectedPath() {
+			return m, nil
+		}
+		m.previewPath, m.previewWidth, m.scroll = msg.path, msg.width, 0
+		m.preview = msg.content
+		if msg.err != nil {
+			m.preview = msg.err.Error()
+		}
+		return m, nil
+
+	case browseEditedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		} else {
+			m.status = "Saved " + msg.path
+		}
+		m.previewPath = ""
+		return m, m.reload()
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m *browseModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = "" // errors and notices last until the next key
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		switch {
+		case len(m.history) > 0:
+			m.browseState = m.history[len(m.history)-1]
+			m.history = m.history[:len(m.history)-1]
+			m.seq++ // drop results still loading for the list we left
+			return m, m.loadPreview()
+		case m.mode == browseView || m.query != "":
+			m.mode, m.query = browseSearch, ""
+			return m, m.search()
+		}
+		return m, tea.Quit
+
+	case tea.KeyUp, tea.KeyCtrlP:
+		return m, m.moveCursor(-1)
+	case tea.KeyDown, tea.KeyCtrlN:
+		return m, m.moveCursor(1)
+	case tea.KeyPgUp:
+		m.scroll = max(m.scroll-m.bodyHeight(), 0)
+		return m, nil
+	case tea.KeyPgDown:
+		m.scroll += m.bodyHeight()
+		return m, nil
+
+	case tea.KeyEnter:
+		if path := m.selectedPath(); path != "" {
+			return m, m.edit(path)
+		}
+		return m, nil
+
+	case tea.KeyTab:
+		return m, m.cycleView(1)
+	case tea.KeyShiftTab:
+		return m, m.cycleView(-1)
+
+	case tea.KeyCtrlL:
+		return m, m.follow(browseLinks)
+	case tea.KeyCtrlB:
+		return m, m.follow(browseBacklinks)
+
+	case tea.KeyBackspace:
+		if m.query == "" {
+			return m, nil
+		}
+		runes := []rune(m.query)
+		return m, m.setQuery(string(runes[:len(runes)-1]))
+	case tea.KeyCtrlU:
+		return m, m.setQuery("")
+
+	case tea.KeyRunes, tea.KeySpace:
+		return m, m.setQuery(m.query + string(msg.Runes))
+	}
+	return m, nil
+}
+
+// setQuery edits the search box, which always returns to search results.
+func (m *browseModel) setQuery(query string) tea.Cmd {
+	m.query = query
+	m.mode = browseSearch
+	m.history = nil
+	m.cursor = 0
+	return m.search()
+}
+
+func (m *browseModel) moveCursor(delta int) tea.Cmd {
+	if len(m.items) == 0 {
+		return nil
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.items)-1)
+	return m.loadPreview()
+}
+
+// cycleView switches to the next or previous saved view.
+func (m *browseModel) cycleView(delta int) tea.Cmd {
+	if len(m.views) == 0 {
+		m.status = "No saved views"
+		return nil
+	}
+	if m.mode == browseView {
+		m.view = (m.view + delta + len(m.views)) % len(m.views)
+	} else if delta < 0 {
+		m.view = len(m.views) - 1
+	} else {
+		m.view = 0
+	}
+	m.mode = browseView
+	m.history = nil
+	m.cursor = 0
+	return m.loadView()
+}
+
+// follow lists the links or backlinks of the selected note. "esc" goes
+// back to the list it came from.
+func (m *browseModel) follow(mode browseMode) tea.Cmd {
+	path := m.selectedPath()
+	if path == "" {
+		return nil
+	}
+	m.history = append(m.history, m.browseState)
+	m.mode, m.origin, m.cursor = mode, path, 0
+	m.items = nil
+	return m.reload()
+}
+
+// reload runs the query behind the current list again.
+func (m *browseModel) reload() tea.Cmd {
+	origin := m.origin
+	switch m.mode {
+	case browseView:
+		return m.loadView()
+	case browseLinks:
+		return m.load(func() ([]browseItem, error) { return m.source.Links(m.ctx, origin) })
+	case browseBacklinks:
+		return m.load(func() ([]browseItem, error) { return m.source.Backlinks(m.ctx, origin) })
+	}
+	return m.search()
+}
+
+func (m *browseModel) search() tea.Cmd {
+	query := m.query
+	return m.load(func() ([]browseItem, error) { return m.source.Search(m.ctx, query) })
+}
+
+func (m *browseModel) loadView() tea.Cmd {
+	name := m.views[m.view]
+	return m.load(func() ([]browseItem, error) { return m.source.View(m.ctx, name) })
+}
+
+// load fetches a result list in the background. Results of earlier loads
+// that finish late are dropped.
+func (m *browseModel) load(fetch func() ([]browseItem, error)) tea.Cmd {
+	m.seq++
+	seq := m.seq
+	return func() tea.Msg {
+		items, err := fetch()
+		return browseResultsMsg{seq: seq, items: items, err: err}
+	}
+}
+
+// loadPreview renders the selected note unless it is already shown at the
+// current width.
+func (m *browseModel) loadPreview() tea.Cmd {
+	path := m.selectedPath()
+	width := m.previewPaneWidth()
+	if path == "" {
+		m.preview, m.previewPath = "", ""
+		return nil
+	}
+	if path == m.previewPath && width == m.previewWidth {
+		return nil
+	}
+	return func() tea.Msg {
+		content, err := m.source.Preview(m.ctx, path, width)
+		return browsePreviewMsg{path: path, width: width, content: content, err: err}
+	}
+}
+
+func (m *browseModel) selectedPath() string {
+	if m.cursor < 0 || m.cursor >= len(m.items) {
+		return ""
+	}
+	return m.items[m.cursor].Path
+}
+
+func (m *browseModel) listPaneWidth() int {
+	return max(m.width*2/5, 20)
+}
+
+func (m *browseModel) previewPaneWidth() int {
+	return max(m.width-m.listPaneWidth()-3, 20)
+}
+
+// bodyHeight is the number of rows for the list and preview panes.
+func (m *browseModel) bodyHeight() int {
+	return max(m.height-4, 1)
+}
+
+var (
+	browseHeaderStyle   = lipgloss.NewStyle().Bold(true)
+	browseSelectedStyle = lipgloss.NewStyle().Reverse(true)
+	browseMutedStyle    = lipgloss.NewStyle().Faint(true)
+)
+
+// View renders the search box, the result list beside the preview and a
+// status line with the key bindings.
+func (m *browseModel) View() string {
+	var b strings.Builder
+	b.WriteString(browseHeaderStyle.Render("Search: ") + m.query + "█")
+	b.WriteString("  " + browseMutedStyle.Render(m.title()) + "\n")
+	b.WriteString(strings.Repeat("─", max(m.width, 1)) + "\n")
+
+	height := m.bodyHeight()
+	list := lipgloss.NewStyle().Width(m.listPaneWidth()).MaxWidth(m.listPaneWidth()).Height(height).MaxHeight(height).
+		Render(m.renderList(height))
+	preview := lipgloss.NewStyle().Width(m.previewPaneWidth()).MaxWidth(m.previewPaneWidth()).Height(height).MaxHeight(height).
+		Render(m.renderPreview(height))
+	separator := strings.TrimSuffix(strings.Repeat(" │\n", height), "\n")
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, list, separator+" ", preview) + "\n")
+
+	status := m.status
+	if status == "" {
+		status = "enter edit · tab views · ctrl+l links · ctrl+b backlinks · esc back · ctrl+c quit"
+	}
+	b.WriteString(browseMutedStyle.Render(status))
+	return b.String()
+}
+
+// title describes the current list.
+func (m *browseModel) title() string {
+	switch m.mode {
+	case browseView:
+		return fmt.Sprintf("view: %s (%d)", m.views[m.view], len(m.items))
+	case browseLinks:
+		return fmt.Sprintf("links from %s (%d)", m.origin, len(m.items))
+	case browseBacklinks:
+		return fmt.Sprintf("backlinks to %s (%d)", m.origin, len(m.items))
+	}
+	return fmt.Sprintf("%d note(s)", len(m.items))
+}
+
+func (m *browseModel) renderList(height int) string {
+	if len(m.items) == 0 {
+		return browseMutedStyle.Render("No notes")
+	}
+
+	// Keep the cursor in view
+	start := max(m.cursor-height+1, 0)
+	end := min(start+height, len(m.items))
+
+	width := m.listPaneWidth()
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		item := m.items[i]
+		line := truncateRunes(item.Title+"  "+item.Path, width)
+		if i == m.cursor {
+			line = browseSelectedStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *browseModel) renderPreview(height int) string {
+	lines := strings.Split(strings.Trim(m.preview, "\n"), "\n")
+	start := min(m.scroll, max(len(lines)-1, 0))
+	end := min(start+height, len(lines))
+	return strings.Join(lines[start:end], "\n")
+}
+
+// truncateRunes shortens s to at most width runes.
+func truncateRunes(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:max(width-1, 0)]) + "…"
+}
+
+// noteBrowseItems converts notes to browser items.
+func noteBrowseItems(notes []services.Note) []browseItem {
+	items := make([]browseItem, len(notes))
+	for i := range notes {
+		items[i] = browseItem{Path: notes[i].File.Relative, Title: notes[i].DisplayName()}
+	}
+	return items
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubBrowseSource serves a fixed set of notes. Search matches titles
+// containing the query.
+type stubBrowseSource struct {
+	items     []browseItem
+	views     map[string][]browseItem
+	links     map[string][]browseItem
+	backlinks map[string][]browseItem
+	queries   []string
+}
+
+func (s *stubBrowseSource) Search(_ context.Context, query string) ([]browseItem, error) {
+	s.queries = append(s.queries, query)
+	var items []browseItem
+	for _, item := range s.items {
+		if strings.Contains(strings.ToLower(item.Title), strings.ToLower(query)) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (s *stubBrowseSource) Views() []string { return []string{"recent", "today"} }
+
+func (s *stubBrowseSource) View(_ context.Context, name string) ([]browseItem, error) {
+	return s.views[name], nil
+}
+
+func (s *stubBrowseSource) Links(_ context.Context, relPath string) ([]browseItem, error) {
+	return s.links[relPath], nil
+}
+
+func (s *stubBrowseSource) Backlinks(_ context.Context, relPath string) ([]browseItem, error) {
+	return s.backlinks[relPath], nil
+}
+
+func (s *stubBrowseSource) Preview(_ context.Context, relPath string, width int) (string, error) {
+	return fmt.Sprintf("preview of %s at %d", relPath, width), nil
+}
+
+func newStubBrowser() (*browseModel, *stubBrowseSource, *[]string) {
+	alpha := browseItem{Path: "alpha.md", Title: "Alpha"}
+	beta := browseItem{Path: "beta.md", Title: "Beta"}
+	gamma := browseItem{Path: "gamma.md", Title: "Gamma"}
+	source := &stubBrowseSource{
+		items:     []browseItem{alpha, beta, gamma},
+		views:     map[string][]browseItem{"recent": {gamma}, "today": {beta}},
+		links:     map[string][]browseItem{"alpha.md": {beta, gamma}},
+		backlinks: map[string][]browseItem{"alpha.md": {gamma}},
+	}
+	edited := &[]string{}
+	model := newBrowseModel(context.Background(), source, func(relPath string) tea.Cmd {
+		*edited = append(*edited, relPath)
+		return func() tea.Msg { return browseEditedMsg{path: relPath} }
+	})
+	return model, source, edited
+}
+
+// drain runs cmd and feeds the messages it produces back into the model
+// until no commands are left, like the bubbletea event loop.
+func drain(t *testing.T, m *browseModel, cmd tea.Cmd) {
+	t.Helper()
+	queue := []tea.Cmd{cmd}
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+		switch msg := next().(type) {
+		case tea.BatchMsg:
+			queue = append(queue, msg...)
+		default:
+			_, follow := m.Update(msg)
+			queue = append(queue, follow)
+		}
+	}
+}
+
+func press(t *testing.T, m *browseModel, key tea.KeyMsg) {
+	t.Helper()
+	_, cmd := m.Update(key)
+	drain(t, m, cmd)
+}
+
+func typeText(t *testing.T, m *browseModel, text string) {
+	t.Helper()
+	for _, r := range text {
+		press(t, m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func itemPaths(items []browseItem) []string {
+	paths := make([]string, len(items))
+	for i, item := range items {
+		paths[i] = item.Path
+	}
+	return paths
+}
+
+func TestBrowseModel_SearchAndPreview(t *testing.T) {
+	m, source, _ := newStubBrowser()
+	drain(t, m, m.Init())
+
+	assert.Equal(t, []string{"alpha.md", "beta.md", "gamma.md"}, itemPaths(m.items))
+	assert.Equal(t, "preview of alpha.md at 45", m.preview)
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyDown})
+	assert.Equal(t, "preview of beta.md at 45", m.preview)
+
+	typeText(t, m, "ga")
+	assert.Equal(t, []string{"", "g", "ga"}, source.queries)
+	assert.Equal(t, []string{"gamma.md"}, itemPaths(m.items))
+	assert.Equal(t, "preview of gamma.md at 45", m.preview)
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyBackspace})
+	assert.Equal(t, "g", m.query)
+
+	// Resizing renders the preview again for the wider pane
+	_, cmd := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	drain(t, m, cmd)
+	assert.Equal(t, "preview of gamma.md at 57", m.preview)
+}
+
+func TestBrowseModel_DropsStaleResults(t *testing.T) {
+	m, _, _ := newStubBrowser()
+	drain(t, m, m.Init())
+
+	_, first := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	_, second := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+
+	// The results for "al" arrive before the slower results for "a"
+	drain(t, m, second)
+	drain(t, m, first)
+	assert.Equal(t, []string{"alpha.md"}, itemPaths(m.items))
+}
+
+func TestBrowseModel_ViewsAndLinks(t *testing.T) {
+	m, _, edited := newStubBrowser()
+	drain(t, m, m.Init())
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, browseView, m.mode)
+	assert.Equal(t, []string{"gamma.md"}, itemPaths(m.items))
+	press(t, m, tea.KeyMsg{Type: tea.KeyTab})
+	assert.Equal(t, []string{"beta.md"}, itemPaths(m.items))
+	press(t, m, tea.KeyMsg{Type: tea.KeyShiftTab})
+	assert.Equal(t, []string{"gamma.md"}, itemPaths(m.items))
+
+	// esc leaves the view for the full search results
+	press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, browseSearch, m.mode)
+	require.Equal(t, "alpha.md", m.selectedPath())
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyCtrlL})
+	assert.Equal(t, browseLinks, m.mode)
+	assert.Equal(t, []string{"beta.md", "gamma.md"}, itemPaths(m.items))
+	assert.Contains(t, m.View(), "links from alpha.md (2)")
+
+	// Links of a note without links, then back twice
+	press(t, m, tea.KeyMsg{Type: tea.KeyCtrlL})
+	assert.Empty(t, m.items)
+	press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, "beta.md", m.selectedPath())
+	press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	assert.Equal(t, browseSearch, m.mode)
+	assert.Equal(t, "alpha.md", m.selectedPath())
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyCtrlB})
+	assert.Equal(t, []string{"gamma.md"}, itemPaths(m.items))
+
+	press(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Equal(t, []string{"gamma.md"}, *edited)
+	assert.Equal(t, "Saved gamma.md", m.status)
+
+	// With nothing left to go back to or clear, esc quits
+	press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	require.NotNil(t, cmd)
+	assert.IsType(t, tea.QuitMsg{}, cmd())
+}
+
+func TestBrowseModel_View(t *testing.T) {
+	m, _, _ := newStubBrowser()
+	drain(t, m, m.Init())
+	typeText(t, m, "al")
+
+	out := m.View()
+	assert.Contains(t, out, "Search: al")
+	assert.Contains(t, out, "1 note(s)")
+	assert.Contains(t, out, "Alpha  alpha.md")
+	assert.Contains(t, out, "preview of alpha.md")
+	assert.Contains(t, out, "ctrl+l links")
+	assert.LessOrEqual(t, strings.Count(out, "\n"), m.height-1)
+}
@@ -0,0 +1,161 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiffOpKind identifies the kind of a line in a diff.
+type DiffOpKind int
+
+const (
+	// DiffEqual is a line present in both inputs.
+	DiffEqual DiffOpKind = iota
+	// DiffDelete is a line only present in the old input.
+	DiffDelete
+	// DiffInsert is a line only present in the new input.
+	DiffInsert
+)
+
+// DiffOp is a single line of a line-based diff.
+type DiffOp struct {
+	Kind DiffOpKind
+	Line string
+}
+
+// DiffLines computes a line diff between a and b using the longest common
+// subsequence. Notes are small, so the quadratic cost is acceptable.
+func DiffLines(a, b []string) []DiffOp {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]DiffOp, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, DiffOp{Kind: DiffEqual, Line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, DiffOp{Kind: DiffDelete, Line: a[i]})
+			i++
+		default:
+			ops = append(ops, DiffOp{Kind: DiffInsert, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, DiffOp{Kind: DiffDelete, Line: a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, DiffOp{Kind: DiffInsert, Line: b[j]})
+	}
+
+	return ops
+}
+
+// DiffStat returns the number of lines added and removed between two texts.
+func DiffStat(oldText, newText string) (added, removed int) {
+	for _, op := range DiffLines(SplitLines(oldText), SplitLines(newText)) {
+		switch op.Kind {
+		case DiffInsert:
+			added++
+		case DiffDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// SplitLines splits text into lines without their trailing newlines.
+// An empty string has no lines.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// UnifiedDiff renders a unified diff between two texts with the given number
+// of context lines. It returns an empty string when the texts are equal.
+func UnifiedDiff(oldName, newName, oldText, newText string, context int) string {
+	ops := DiffLines(SplitLines(oldText), SplitLines(newText))
+
+	// Collect the indices of changed ops, then group them into hunks
+	var changes []int
+	for i, op := range ops {
+		if op.Kind != DiffEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(changes); {
+		end := start
+		for end+1 < len(changes) && changes[end+1]-changes[end] <= 2*context {
+			end++
+		}
+
+		from := max(changes[start]-context, 0)
+		to := min(changes[end]+context+1, len(ops))
+
+		// Line numbers are 1-based positions in the old and new texts
+		oldLine, newLine := 1, 1
+		for _, op := range ops[:from] {
+			if op.Kind != DiffInsert {
+				oldLine++
+			}
+			if op.Kind != DiffDelete {
+				newLine++
+			}
+		}
+
+		oldCount, newCount := 0, 0
+		var hunk strings.Builder
+		for _, op := range ops[from:to] {
+			switch op.Kind {
+			case DiffEqual:
+				oldCount++
+				newCount++
+				hunk.WriteString(" " + op.Line + "\n")
+			case DiffDelete:
+				oldCount++
+				hunk.WriteString("-" + op.Line + "\n")
+			case DiffInsert:
+				newCount++
+				hunk.WriteString("+" + op.Line + "\n")
+			}
+		}
+
+		if oldCount == 0 {
+			oldLine--
+		}
+		if newCount == 0 {
+			newLine--
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldLine, oldCount, newLine, newCount)
+		b.WriteString(hunk.String())
+
+		start = end + 1
+	}
+
+	return b.String()
+}
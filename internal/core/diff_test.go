@@ -0,0 +1,64 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffLines(t *testing.T) {
+	ops := DiffLines([]string{"a", "b", "c"}, []string{"a", "x", "c", "d"})
+
+	assert.Equal(t, []DiffOp{
+		{Kind: DiffEqual, Line: "a"},
+		{Kind: DiffDelete, Line: "b"},
+		{Kind: DiffInsert, Line: "x"},
+		{Kind: DiffEqual, Line: "c"},
+		{Kind: DiffInsert, Line: "d"},
+	}, ops)
+}
+
+func TestDiffLines_Empty(t *testing.T) {
+	assert.Empty(t, DiffLines(nil, nil))
+	assert.Equal(t, []DiffOp{{Kind: DiffInsert, Line: "a"}}, DiffLines(nil, []string{"a"}))
+	assert.Equal(t, []DiffOp{{Kind: DiffDelete, Line: "a"}}, DiffLines([]string{"a"}, nil))
+}
+
+func TestDiffStat(t *testing.T) {
+	added, removed := DiffStat("one\ntwo\nthree\n", "one\n2\nthree\nfour\n")
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, removed)
+
+	added, removed = DiffStat("same\n", "same\n")
+	assert.Zero(t, added)
+	assert.Zero(t, removed)
+}
+
+func TestSplitLines(t *testing.T) {
+	assert.Nil(t, SplitLines(""))
+	assert.Equal(t, []string{"a", "b"}, SplitLines("a\nb\n"))
+	assert.Equal(t, []string{"a", "b"}, SplitLines("a\nb"))
+	assert.Equal(t, []string{"a", ""}, SplitLines("a\n\n"))
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	newText := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n"
+
+	diff := UnifiedDiff("a/note.md", "b/note.md", oldText, newText, 1)
+
+	expected := "--- a/note.md\n+++ b/note.md\n" +
+		"@@ -2,3 +2,3 @@\n 2\n-3\n+three\n 4\n" +
+		"@@ -10,1 +10,2 @@\n 10\n+11\n"
+	assert.Equal(t, expected, diff)
+}
+
+func TestUnifiedDiff_MergesNearbyHunks(t *testing.T) {
+	diff := UnifiedDiff("a", "b", "1\n2\n3\n4\n", "x\n2\n3\ny\n", 3)
+
+	assert.Equal(t, "--- a\n+++ b\n@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n-4\n+y\n", diff)
+}
+
+func TestUnifiedDiff_Equal(t *testing.T) {
+	assert.Empty(t, UnifiedDiff("a", "b", "same\n", "same\n", 3))
+}
@@ -0,0 +1,44 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		name     string
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{name: "exact file", pattern: "secrets.md", path: "secrets.md", expected: true},
+		{name: "single star in segment", pattern: "journal/*.md", path: "journal/jan.md", expected: true},
+		{name: "single star does not cross segments", pattern: "journal/*.md", path: "journal/2024/jan.md", expected: false},
+		{name: "double star folder", pattern: "secrets/**", path: "secrets/aws/keys.md", expected: true},
+		{name: "double star matches direct child", pattern: "secrets/**", path: "secrets/keys.md", expected: true},
+		{name: "double star prefix", pattern: "**/*.md", path: "notes/daily/today.md", expected: true},
+		{name: "double star prefix matches root file", pattern: "**/*.md", path: "today.md", expected: true},
+		{name: "double star in middle", pattern: "projects/**/notes.md", path: "projects/a/b/notes.md", expected: true},
+		{name: "double star in middle zero segments", pattern: "projects/**/notes.md", path: "projects/notes.md", expected: true},
+		{name: "different folder", pattern: "secrets/**", path: "public/keys.md", expected: false},
+		{name: "leading slash ignored", pattern: "/secrets/*.md", path: "secrets/keys.md", expected: true},
+		{name: "invalid pattern", pattern: "[", path: "[", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MatchGlob(tt.pattern, tt.path))
+		})
+	}
+}
+
+func TestMatchAnyGlob(t *testing.T) {
+	patterns := []string{"secrets/**", "incidents/*.md"}
+
+	assert.True(t, MatchAnyGlob(patterns, "incidents/2024-01.md"))
+	assert.True(t, MatchAnyGlob(patterns, "secrets/db.md"))
+	assert.False(t, MatchAnyGlob(patterns, "notes/todo.md"))
+	assert.False(t, MatchAnyGlob(nil, "notes/todo.md"))
+}
@@ -8,8 +8,8 @@ import (
 
 // ValidationError represents a validation error with path context.
 type ValidationError struct {
-	Path    string
-	Message string
+	Path    string `json:"path"`
+	Message string `json:"message"`
 }
 
 func (e ValidationError) Error() string {
@@ -37,20 +37,26 @@ func (e ValidationErrors) Error() string {
 	return strings.Join(lines, "\n")
 }
 
-// PrettyPrint formats validation errors in a hierarchical format grouped by path.
+// PrettyPrint formats validation errors in a hierarchical format grouped by
+// path. Paths are listed in the order they first appear.
 func (e ValidationErrors) PrettyPrint() string {
 	grouped := make(map[string][]string)
+	var paths []string
 
 	for _, err := range e {
 		path := err.Path
 		if path == "" {
 			path = "(root)"
 		}
+		if _, ok := grouped[path]; !ok {
+			paths = append(paths, path)
+		}
 		grouped[path] = append(grouped[path], err.Message)
 	}
 
 	var lines []string
-	for path, messages := range grouped {
+	for _, path := range paths {
+		messages := grouped[path]
 		lines = append(lines, fmt.Sprintf("- %s", path))
 		for _, msg := range messages {
 			lines = append(lines, fmt.Sprintf("  - %s", msg))
@@ -114,6 +114,16 @@ func TestValidationErrors(t *testing.T) {
 	assert.Contains(t, pretty, "  - is required")
 }
 
+func TestValidationErrors_PrettyPrintKeepsOrder(t *testing.T) {
+	errors := ValidationErrors{
+		{Path: "b.md", Message: "first"},
+		{Path: "a.md", Message: "second"},
+		{Path: "b.md", Message: "third"},
+	}
+
+	assert.Equal(t, "- b.md\n  - first\n  - third\n- a.md\n  - second", errors.PrettyPrint())
+}
+
 func TestValidator(t *testing.T) {
 	v := NewValidator()
 
@@ -9,6 +9,16 @@ type ViewDefinition struct {
 	Parameters  []ViewParameter `json:"parameters,omitempty"`
 	Query       string          `json:"query"`          // "filter DSL | directives"
 	Type        string          `json:"type,omitempty"` // "query" (default) or "special"
+	Board       *BoardConfig    `json:"board,omitempty"`
+}
+
+// BoardConfig lays out a grouped view as a kanban board.
+type BoardConfig struct {
+	// Columns lists the columns in order. Groups not listed follow them,
+	// sorted by name.
+	Columns []string `json:"columns,omitempty"`
+	// WIPLimits caps the number of cards in a column.
+	WIPLimits map[string]int `json:"wip_limits,omitempty"`
 }
 
 // ViewInfo represents view metadata for discovery/listing (includes origin)
@@ -0,0 +1,330 @@
+// Package archive implements a read-only search.Storage backed by a zip or
+// tar archive.
+//
+// The archive is read into memory once when it is opened. This keeps lookups
+// cheap and lets a notebook be shipped as a single file, at the cost of
+// memory proportional to the archive size.
+//
+// Supported formats are detected from the file extension:
+//   - .zip
+//   - .tar
+//   - .tar.gz, .tgz
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// Storage implements search.Storage over the contents of an archive file.
+// All write operations return search.ErrReadOnly.
+type Storage struct {
+	path  string
+	files map[string]*entry
+	dirs  map[string]time.Time
+}
+
+// entry is a single regular file held in memory.
+type entry struct {
+	data    []byte
+	modTime time.Time
+	mode    fs.FileMode
+}
+
+// Open reads the archive at archivePath into memory.
+func Open(archivePath string) (*Storage, error) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Storage{
+		path:  archivePath,
+		files: make(map[string]*entry),
+		dirs:  map[string]time.Time{".": info.ModTime()},
+	}
+
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		err = s.loadZip()
+	case strings.HasSuffix(lower, ".tar"):
+		err = s.loadTar(false)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		err = s.loadTar(true)
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s (expected .zip, .tar, .tar.gz or .tgz)", archivePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+
+	return s, nil
+}
+
+// loadZip reads all regular files from a zip archive.
+func (s *Storage) loadZip() error {
+	r, err := zip.OpenReader(s.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+
+	for _, f := range r.File {
+		name := cleanPath(f.Name)
+		if name == "." {
+			continue
+		}
+		if f.FileInfo().IsDir() {
+			s.addDir(name, f.Modified)
+			continue
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		s.addFile(name, data, f.Modified, f.Mode())
+	}
+
+	return nil
+}
+
+// loadTar reads all regular files from a tar archive, optionally gzipped.
+func (s *Storage) loadTar(gzipped bool) error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if gzipped {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := cleanPath(hdr.Name)
+		if name == "." {
+			continue
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			s.addDir(name, hdr.ModTime)
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			s.addFile(name, data, hdr.ModTime, fs.FileMode(hdr.Mode).Perm())
+		}
+	}
+}
+
+// addFile records a file and all of its parent directories.
+func (s *Storage) addFile(name string, data []byte, modTime time.Time, mode fs.FileMode) {
+	s.files[name] = &entry{data: data, modTime: modTime, mode: mode}
+	s.addDir(path.Dir(name), modTime)
+}
+
+// addDir records a directory and all of its parents.
+func (s *Storage) addDir(name string, modTime time.Time) {
+	for name != "." && name != "/" {
+		if _, ok := s.dirs[name]; !ok {
+			s.dirs[name] = modTime
+		}
+		name = path.Dir(name)
+	}
+}
+
+// cleanPath normalises a storage path to a slash-separated relative path.
+func cleanPath(p string) string {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// Read reads the content of a file.
+func (s *Storage) Read(p string) ([]byte, error) {
+	e, ok := s.files[cleanPath(p)]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: p, Err: fs.ErrNotExist}
+	}
+	return bytes.Clone(e.data), nil
+}
+
+// ReadStream opens a file for reading.
+func (s *Storage) ReadStream(p string) (io.ReadCloser, error) {
+	e, ok := s.files[cleanPath(p)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: p, Err: fs.ErrNotExist}
+	}
+	return io.NopCloser(bytes.NewReader(e.data)), nil
+}
+
+// Write always fails because archives are read-only.
+func (s *Storage) Write(p string, content []byte) error {
+	return &fs.PathError{Op: "write", Path: p, Err: search.ErrReadOnly}
+}
+
+// Exists returns true if the path exists.
+func (s *Storage) Exists(p string) bool {
+	_, err := s.Stat(p)
+	return err == nil
+}
+
+// Stat returns file metadata.
+func (s *Storage) Stat(p string) (search.FileInfo, error) {
+	name := cleanPath(p)
+	if e, ok := s.files[name]; ok {
+		return s.fileInfo(name, e), nil
+	}
+	if modTime, ok := s.dirs[name]; ok {
+		return s.dirInfo(name, modTime), nil
+	}
+	return search.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+}
+
+// Walk traverses the directory tree starting at root in lexical order.
+func (s *Storage) Walk(root string, walkFn search.WalkFunc) error {
+	info, err := s.Stat(root)
+	if err != nil {
+		return walkFn(root, search.FileInfo{}, err)
+	}
+
+	err = s.walk(info, walkFn)
+	if err == search.SkipDir || err == search.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walk visits info and, for directories, its children.
+func (s *Storage) walk(info search.FileInfo, walkFn search.WalkFunc) error {
+	if err := walkFn(info.Path, info, nil); err != nil {
+		if err == search.SkipDir && info.IsDir {
+			return nil
+		}
+		return err
+	}
+
+	if !info.IsDir {
+		return nil
+	}
+
+	children, err := s.List(info.Path)
+	if err != nil {
+		return walkFn(info.Path, info, err)
+	}
+
+	for _, child := range children {
+		if err := s.walk(child, walkFn); err != nil {
+			if err == search.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns entries in a directory (non-recursive), sorted by name.
+func (s *Storage) List(p string) ([]search.FileInfo, error) {
+	dir := cleanPath(p)
+	if _, ok := s.dirs[dir]; !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: p, Err: fs.ErrNotExist}
+	}
+
+	var result []search.FileInfo
+	for name, e := range s.files {
+		if path.Dir(name) == dir {
+			result = append(result, s.fileInfo(name, e))
+		}
+	}
+	for name, modTime := range s.dirs {
+		if name != "." && path.Dir(name) == dir {
+			result = append(result, s.dirInfo(name, modTime))
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// Remove always fails because archives are read-only.
+func (s *Storage) Remove(p string) error {
+	return &fs.PathError{Op: "remove", Path: p, Err: search.ErrReadOnly}
+}
+
+// Rename always fails because archives are read-only.
+func (s *Storage) Rename(oldPath, newPath string) error {
+	return &fs.PathError{Op: "rename", Path: oldPath, Err: search.ErrReadOnly}
+}
+
+// Root returns the path to the archive file.
+func (s *Storage) Root() string {
+	return s.path
+}
+
+func (s *Storage) fileInfo(name string, e *entry) search.FileInfo {
+	return search.FileInfo{
+		Path:    filepath.FromSlash(name),
+		Name:    path.Base(name),
+		Size:    int64(len(e.data)),
+		ModTime: e.modTime,
+		Mode:    e.mode,
+	}
+}
+
+func (s *Storage) dirInfo(name string, modTime time.Time) search.FileInfo {
+	return search.FileInfo{
+		Path:    filepath.FromSlash(name),
+		Name:    path.Base(name),
+		ModTime: modTime,
+		IsDir:   true,
+		Mode:    fs.ModeDir | 0555,
+	}
+}
+
+// Ensure Storage implements search.Storage.
+var _ search.Storage = (*Storage)(nil)
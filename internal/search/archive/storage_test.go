@@ -0,0 +1,183 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+var testFiles = map[string]string{
+	"readme.md":        "# Readme",
+	"projects/todo.md": "# Todo",
+	"projects/a/b.md":  "# Nested",
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	zw := zip.NewWriter(f)
+	for name, content := range testFiles {
+		w, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = io.WriteString(w, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range testFiles {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  time.Now(),
+			Typeflag: tar.TypeReg,
+		}))
+		_, err := io.WriteString(tw, content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+}
+
+func TestOpen_Zip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	data, err := s.Read("projects/todo.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Todo", string(data))
+
+	assert.True(t, s.Exists("projects"))
+	assert.True(t, s.Exists("projects/a/b.md"))
+	assert.False(t, s.Exists("missing.md"))
+	assert.Equal(t, path, s.Root())
+}
+
+func TestOpen_TarGz(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.tar.gz")
+	writeTarGz(t, path)
+
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	data, err := s.Read("readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Readme", string(data))
+}
+
+func TestOpen_UnsupportedFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.rar")
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0644))
+
+	_, err := Open(path)
+	assert.Error(t, err)
+}
+
+func TestStorage_Stat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	info, err := s.Stat("projects/todo.md")
+	require.NoError(t, err)
+	assert.False(t, info.IsDir)
+	assert.Equal(t, "todo.md", info.Name)
+	assert.Equal(t, int64(6), info.Size)
+
+	info, err = s.Stat("projects")
+	require.NoError(t, err)
+	assert.True(t, info.IsDir)
+
+	_, err = s.Stat("missing.md")
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestStorage_List(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	entries, err := s.List("projects")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Name)
+	assert.True(t, entries[0].IsDir)
+	assert.Equal(t, "todo.md", entries[1].Name)
+}
+
+func TestStorage_Walk(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	var files []string
+	err = s.Walk(".", func(p string, info search.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir {
+			files = append(files, filepath.ToSlash(p))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"projects/a/b.md", "projects/todo.md", "readme.md"}, files)
+}
+
+func TestStorage_Walk_SkipDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	var files []string
+	err = s.Walk(".", func(p string, info search.FileInfo, err error) error {
+		if info.IsDir && info.Name == "projects" {
+			return search.SkipDir
+		}
+		if !info.IsDir {
+			files = append(files, filepath.ToSlash(p))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"readme.md"}, files)
+}
+
+func TestStorage_WritesAreReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.zip")
+	writeZip(t, path)
+	s, err := Open(path)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.Write("new.md", []byte("x")), search.ErrReadOnly)
+	assert.ErrorIs(t, s.Remove("readme.md"), search.ErrReadOnly)
+	assert.ErrorIs(t, s.Rename("readme.md", "other.md"), search.ErrReadOnly)
+}
@@ -2,6 +2,7 @@ package bleve
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -104,6 +105,7 @@ func (idx *Index) Add(ctx context.Context, doc search.Document) error {
 	// Convert to Bleve document
 	bleveDoc := BleveDocument{
 		Path:     doc.Path,
+		ID:       doc.ID,
 		Title:    doc.Title,
 		Body:     doc.Body,
 		Lead:     doc.Lead,
@@ -113,6 +115,13 @@ func (idx *Index) Add(ctx context.Context, doc search.Document) error {
 		Checksum: doc.Checksum,
 		Metadata: doc.Metadata,
 	}
+	if len(doc.Tasks) > 0 {
+		tasks, err := json.Marshal(doc.Tasks)
+		if err != nil {
+			return fmt.Errorf("failed to encode tasks: %w", err)
+		}
+		bleveDoc.Tasks = string(tasks)
+	}
 
 	// Use path as document ID
 	return idx.index.Index(doc.Path, bleveDoc)
@@ -217,6 +226,8 @@ func (idx *Index) FindByPath(ctx context.Context, path string) (search.Document,
 
 	doc.VisitFields(func(field bindex.Field) {
 		switch field.Name() {
+		case FieldID:
+			result.ID = string(field.Value())
 		case FieldTitle:
 			result.Title = string(field.Value())
 		case FieldLead:
@@ -395,6 +406,9 @@ func extractDocument(hit *bsearch.DocumentMatch) search.Document {
 		Metadata: make(map[string]any),
 	}
 
+	if v, ok := hit.Fields[FieldID].(string); ok {
+		doc.ID = v
+	}
 	if v, ok := hit.Fields[FieldTitle].(string); ok {
 		doc.Title = v
 	}
@@ -407,6 +421,9 @@ func extractDocument(hit *bsearch.DocumentMatch) search.Document {
 	if v, ok := hit.Fields[FieldChecksum].(string); ok {
 		doc.Checksum = v
 	}
+	if v, ok := hit.Fields[FieldTasks].(string); ok && v != "" {
+		_ = json.Unmarshal([]byte(v), &doc.Tasks)
+	}
 
 	// Parse tags
 	if v, ok := hit.Fields[FieldTags]; ok {
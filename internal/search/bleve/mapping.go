@@ -2,9 +2,12 @@ package bleve
 
 import (
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
-	"github.com/blevesearch/bleve/v2/analysis/analyzer/simple"
 	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
 	"github.com/blevesearch/bleve/v2/mapping"
 )
 
@@ -21,6 +24,7 @@ const (
 // Field names in the Bleve index.
 const (
 	FieldPath     = "path"
+	FieldID       = "id"
 	FieldTitle    = "title"
 	FieldBody     = "body"
 	FieldLead     = "lead"
@@ -29,8 +33,18 @@ const (
 	FieldModified = "modified"
 	FieldChecksum = "checksum"
 	FieldMetadata = "metadata"
+	FieldTasks    = "tasks"
 )
 
+// TagAnalyzer indexes each tag as a single lowercased token, so tags like
+// "sprint-41" or "q1/planning" match exactly instead of being split.
+const TagAnalyzer = "tag"
+
+// MetadataAnalyzer splits frontmatter values into lowercased words like the
+// standard analyzer but keeps stop words, so values such as "doing" or
+// "off" stay searchable.
+const MetadataAnalyzer = "metadata"
+
 // BuildDocumentMapping creates the Bleve document mapping for notes.
 //
 // The mapping defines how each field is indexed and its relative weight
@@ -39,6 +53,18 @@ func BuildDocumentMapping() mapping.IndexMapping {
 	// Create the index mapping
 	indexMapping := bleve.NewIndexMapping()
 
+	// Registering a built-in tokenizer and filter on a fresh mapping cannot fail
+	_ = indexMapping.AddCustomAnalyzer(TagAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     single.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+	_ = indexMapping.AddCustomAnalyzer(MetadataAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{lowercase.Name},
+	})
+
 	// Create the document mapping for notes
 	noteMapping := bleve.NewDocumentMapping()
 
@@ -49,6 +75,13 @@ func BuildDocumentMapping() mapping.IndexMapping {
 	pathField.IncludeInAll = true
 	noteMapping.AddFieldMappingsAt(FieldPath, pathField)
 
+	// ID field - whole value, lowercased, like tags
+	idField := bleve.NewTextFieldMapping()
+	idField.Analyzer = TagAnalyzer
+	idField.Store = true
+	idField.IncludeInAll = false
+	noteMapping.AddFieldMappingsAt(FieldID, idField)
+
 	// Title field - standard analyzer, high weight
 	titleField := bleve.NewTextFieldMapping()
 	titleField.Analyzer = standard.Name
@@ -70,9 +103,9 @@ func BuildDocumentMapping() mapping.IndexMapping {
 	leadField.IncludeInAll = true
 	noteMapping.AddFieldMappingsAt(FieldLead, leadField)
 
-	// Tags field - simple analyzer (lowercase, no stemming)
+	// Tags field - whole tag, lowercased
 	tagsField := bleve.NewTextFieldMapping()
-	tagsField.Analyzer = simple.Name
+	tagsField.Analyzer = TagAnalyzer
 	tagsField.Store = true
 	tagsField.IncludeInAll = true
 	noteMapping.AddFieldMappingsAt(FieldTags, tagsField)
@@ -94,9 +127,17 @@ func BuildDocumentMapping() mapping.IndexMapping {
 	checksumField.Index = false // Not searchable, just stored
 	noteMapping.AddFieldMappingsAt(FieldChecksum, checksumField)
 
+	// Tasks field - JSON encoded task list items (not searchable, just stored)
+	tasksField := bleve.NewTextFieldMapping()
+	tasksField.Analyzer = keyword.Name
+	tasksField.Store = true
+	tasksField.Index = false
+	noteMapping.AddFieldMappingsAt(FieldTasks, tasksField)
+
 	// Metadata field - dynamic for arbitrary frontmatter
 	metadataMapping := bleve.NewDocumentMapping()
 	metadataMapping.Dynamic = true
+	metadataMapping.DefaultAnalyzer = MetadataAnalyzer
 	noteMapping.AddSubDocumentMapping(FieldMetadata, metadataMapping)
 
 	// Set the default document type
@@ -112,6 +153,7 @@ func BuildDocumentMapping() mapping.IndexMapping {
 // It mirrors search.Document but uses types compatible with Bleve.
 type BleveDocument struct {
 	Path     string         `json:"path"`
+	ID       string         `json:"id,omitempty"`
 	Title    string         `json:"title"`
 	Body     string         `json:"body"`
 	Lead     string         `json:"lead"`
@@ -120,6 +162,7 @@ type BleveDocument struct {
 	Modified string         `json:"modified"` // ISO8601 format
 	Checksum string         `json:"checksum"`
 	Metadata map[string]any `json:"metadata,omitempty"`
+	Tasks    string         `json:"tasks,omitempty"` // JSON encoded []search.Task
 }
 
 // TimeFormat is the ISO8601 format used for date fields.
@@ -86,8 +86,8 @@ func translateFieldExpr(e search.FieldExpr) (bquery.Query, error) {
 	switch e.Op {
 	case search.OpEquals, "":
 		// Default: match or term query depending on field
-		if field == FieldTags {
-			// Tags use term query for exact match
+		if field == FieldTags || field == FieldID {
+			// Tags and IDs use term query for exact match
 			tq := bquery.NewTermQuery(strings.ToLower(e.Value))
 			tq.SetField(field)
 			return tq, nil
@@ -263,6 +263,8 @@ func normalizeField(field string) string {
 	switch strings.ToLower(field) {
 	case "path", "p":
 		return FieldPath
+	case "id":
+		return FieldID
 	case "title", "t":
 		return FieldTitle
 	case "body", "content", "b":
@@ -275,8 +277,8 @@ func normalizeField(field string) string {
 		return FieldCreated
 	case "modified", "updated":
 		return FieldModified
-	case "status":
-		return FieldMetadata + ".status"
+	case "status", "due", "owner":
+		return FieldMetadata + "." + strings.ToLower(field)
 	default:
 		// Check if it's a metadata field
 		if strings.HasPrefix(field, "meta.") || strings.HasPrefix(field, "metadata.") {
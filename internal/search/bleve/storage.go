@@ -102,7 +102,12 @@ func (s *AferoStorage) Remove(path string) error {
 
 // Rename moves a file from oldPath to newPath.
 func (s *AferoStorage) Rename(oldPath, newPath string) error {
-	return s.fs.Rename(s.fullPath(oldPath), s.fullPath(newPath))
+	fullPath := s.fullPath(newPath)
+	// Create parent directories if needed
+	if err := s.fs.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	return s.fs.Rename(s.fullPath(oldPath), fullPath)
 }
 
 // Root returns the absolute path to the notebook root.
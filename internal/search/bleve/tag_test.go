@@ -230,3 +230,79 @@ func TestTagCaseInsensitivity(t *testing.T) {
 		})
 	}
 }
+
+// TestTagExactMatch tests that tags are matched whole, not split on punctuation.
+func TestTagExactMatch(t *testing.T) {
+	ctx := context.Background()
+	index, err := NewIndex(MemStorage(), Options{InMemory: true})
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+
+	require.NoError(t, index.Add(ctx, search.Document{Path: "a.md", Tags: []string{"Sprint-41"}}))
+	require.NoError(t, index.Add(ctx, search.Document{Path: "b.md", Tags: []string{"sprint-42"}}))
+
+	query := &search.Query{
+		Expressions: []search.Expr{
+			search.FieldExpr{Field: "tag", Op: search.OpEquals, Value: "sprint-41"},
+		},
+	}
+
+	results, err := index.Find(ctx, search.FindOpts{Query: query})
+	require.NoError(t, err)
+	require.Len(t, results.Documents(), 1)
+	assert.Equal(t, "a.md", results.Documents()[0].Path)
+}
+
+// TestIDExactMatch tests that note IDs are indexed and matched whole.
+func TestIDExactMatch(t *testing.T) {
+	ctx := context.Background()
+	index, err := NewIndex(MemStorage(), Options{InMemory: true})
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+
+	require.NoError(t, index.Add(ctx, search.Document{Path: "a.md", ID: "01HX3Q5R7M8N9P0QRSTVWXYZ12"}))
+	require.NoError(t, index.Add(ctx, search.Document{Path: "b.md", ID: "20240501093000"}))
+	require.NoError(t, index.Add(ctx, search.Document{Path: "c.md"}))
+
+	query := &search.Query{
+		Expressions: []search.Expr{
+			search.FieldExpr{Field: "id", Op: search.OpEquals, Value: "01hx3q5r7m8n9p0qrstvwxyz12"},
+		},
+	}
+
+	results, err := index.Find(ctx, search.FindOpts{Query: query})
+	require.NoError(t, err)
+	require.Len(t, results.Documents(), 1)
+	assert.Equal(t, "a.md", results.Documents()[0].Path)
+	assert.Equal(t, "01HX3Q5R7M8N9P0QRSTVWXYZ12", results.Documents()[0].ID)
+
+	doc, err := index.FindByPath(ctx, "b.md")
+	require.NoError(t, err)
+	assert.Equal(t, "20240501093000", doc.ID)
+}
+
+// TestMetadataStopWords tests that frontmatter values which are English stop
+// words, like a "doing" status, are indexed and searchable.
+func TestMetadataStopWords(t *testing.T) {
+	ctx := context.Background()
+	index, err := NewIndex(MemStorage(), Options{InMemory: true})
+	require.NoError(t, err)
+	defer func() { _ = index.Close() }()
+
+	require.NoError(t, index.Add(ctx, search.Document{Path: "doing.md", Metadata: map[string]any{"status": "doing"}}))
+	require.NoError(t, index.Add(ctx, search.Document{Path: "todo.md", Metadata: map[string]any{"status": "todo"}}))
+
+	find := func(expr search.Expr) []string {
+		t.Helper()
+		results, err := index.Find(ctx, search.FindOpts{Query: &search.Query{Expressions: []search.Expr{expr}}})
+		require.NoError(t, err)
+		var paths []string
+		for _, doc := range results.Documents() {
+			paths = append(paths, doc.Path)
+		}
+		return paths
+	}
+
+	assert.ElementsMatch(t, []string{"doing.md", "todo.md"}, find(search.ExistsExpr{Field: "status"}))
+	assert.Equal(t, []string{"doing.md"}, find(search.FieldExpr{Field: "status", Op: search.OpEquals, Value: "Doing"}))
+}
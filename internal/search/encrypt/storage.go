@@ -0,0 +1,261 @@
+// Package encrypt implements a search.Storage decorator that encrypts notes
+// at rest using age (https://age-encryption.org).
+//
+// Encrypted files are stored ASCII-armored so they stay diff- and
+// paste-friendly. Reads transparently decrypt any age file when a matching
+// identity is available and fail with search.ErrEncrypted otherwise, so
+// callers can skip the file instead of indexing ciphertext.
+//
+// A write is encrypted when the path matches one of the configured globs or
+// when the file being replaced is already encrypted. Encrypt and Decrypt
+// convert existing files explicitly.
+package encrypt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// ErrNoRecipients is returned when a file must be encrypted but no
+// recipient (public key or passphrase) is configured.
+var ErrNoRecipients = errors.New("no encryption key configured")
+
+// binaryHeader is the first line of a binary age file.
+const binaryHeader = "age-encryption.org/v1\n"
+
+// Options configures an encrypting storage.
+type Options struct {
+	// Identities decrypt files. Without identities encrypted files cannot be read.
+	Identities []age.Identity
+
+	// Recipients encrypt files. Without recipients nothing can be encrypted.
+	Recipients []age.Recipient
+
+	// Globs select paths that are always encrypted on write (e.g. "secrets/**").
+	Globs []string
+}
+
+// Storage wraps another search.Storage and encrypts selected files.
+type Storage struct {
+	inner search.Storage
+	opts  Options
+}
+
+// New wraps inner with age encryption.
+func New(inner search.Storage, opts Options) *Storage {
+	return &Storage{inner: inner, opts: opts}
+}
+
+// Inner returns the wrapped storage.
+func (s *Storage) Inner() search.Storage {
+	return s.inner
+}
+
+// CanDecrypt reports whether any identity is configured.
+func (s *Storage) CanDecrypt() bool {
+	return len(s.opts.Identities) > 0
+}
+
+// CanEncrypt reports whether any recipient is configured.
+func (s *Storage) CanEncrypt() bool {
+	return len(s.opts.Recipients) > 0
+}
+
+// IsEncrypted reports whether content is an age file, armored or binary.
+func IsEncrypted(content []byte) bool {
+	trimmed := bytes.TrimLeft(content, " \t\r\n")
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) ||
+		bytes.HasPrefix(content, []byte(binaryHeader))
+}
+
+// IsEncryptedPath reports whether the file at p is currently encrypted.
+func (s *Storage) IsEncryptedPath(p string) (bool, error) {
+	data, err := s.inner.Read(p)
+	if err != nil {
+		return false, err
+	}
+	return IsEncrypted(data), nil
+}
+
+// ShouldEncrypt reports whether new content written to p will be encrypted.
+func (s *Storage) ShouldEncrypt(p string) bool {
+	if core.MatchAnyGlob(s.opts.Globs, filepath.ToSlash(p)) {
+		return true
+	}
+	encrypted, err := s.IsEncryptedPath(p)
+	return err == nil && encrypted
+}
+
+// Read reads and, if needed, decrypts a file.
+func (s *Storage) Read(p string) ([]byte, error) {
+	data, err := s.inner.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	return s.decrypt(p, data)
+}
+
+// DecryptContent decrypts content read for p outside the storage, such as an
+// earlier version from version control. Plain content is returned as is.
+func (s *Storage) DecryptContent(p string, data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+	return s.decrypt(p, data)
+}
+
+// ReadStream opens a file for reading, decrypting it if needed.
+func (s *Storage) ReadStream(p string) (io.ReadCloser, error) {
+	data, err := s.Read(p)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Write writes content, encrypting it when the path requires it.
+func (s *Storage) Write(p string, content []byte) error {
+	if s.ShouldEncrypt(p) {
+		return s.WriteEncrypted(p, content)
+	}
+	return s.inner.Write(p, content)
+}
+
+// WriteEncrypted encrypts content and writes it regardless of globs.
+func (s *Storage) WriteEncrypted(p string, content []byte) error {
+	ciphertext, err := s.encrypt(p, content)
+	if err != nil {
+		return err
+	}
+	return s.inner.Write(p, ciphertext)
+}
+
+// Encrypt converts an existing plaintext file to an encrypted one.
+// Already encrypted files are left untouched.
+func (s *Storage) Encrypt(p string) error {
+	data, err := s.inner.Read(p)
+	if err != nil {
+		return err
+	}
+	if IsEncrypted(data) {
+		return nil
+	}
+	return s.WriteEncrypted(p, data)
+}
+
+// Decrypt converts an existing encrypted file back to plaintext.
+// Plaintext files are left untouched. Note that a later Write to a path
+// matching the configured globs encrypts it again.
+func (s *Storage) Decrypt(p string) error {
+	data, err := s.inner.Read(p)
+	if err != nil {
+		return err
+	}
+	if !IsEncrypted(data) {
+		return nil
+	}
+	plaintext, err := s.decrypt(p, data)
+	if err != nil {
+		return err
+	}
+	return s.inner.Write(p, plaintext)
+}
+
+func (s *Storage) encrypt(p string, content []byte) ([]byte, error) {
+	if !s.CanEncrypt() {
+		return nil, &fs.PathError{Op: "encrypt", Path: p, Err: ErrNoRecipients}
+	}
+
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, s.opts.Recipients...)
+	if err != nil {
+		return nil, &fs.PathError{Op: "encrypt", Path: p, Err: err}
+	}
+	if _, err := w.Write(content); err != nil {
+		return nil, &fs.PathError{Op: "encrypt", Path: p, Err: err}
+	}
+	if err := w.Close(); err != nil {
+		return nil, &fs.PathError{Op: "encrypt", Path: p, Err: err}
+	}
+	if err := aw.Close(); err != nil {
+		return nil, &fs.PathError{Op: "encrypt", Path: p, Err: err}
+	}
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+func (s *Storage) decrypt(p string, data []byte) ([]byte, error) {
+	if !s.CanDecrypt() {
+		return nil, &fs.PathError{Op: "decrypt", Path: p, Err: search.ErrEncrypted}
+	}
+
+	var src io.Reader = bytes.NewReader(data)
+	if !bytes.HasPrefix(data, []byte(binaryHeader)) {
+		src = armor.NewReader(bufio.NewReader(bytes.NewReader(bytes.TrimLeft(data, " \t\r\n"))))
+	}
+
+	r, err := age.Decrypt(src, s.opts.Identities...)
+	if err != nil {
+		return nil, &fs.PathError{Op: "decrypt", Path: p, Err: fmt.Errorf("%w: %w", search.ErrEncrypted, err)}
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &fs.PathError{Op: "decrypt", Path: p, Err: err}
+	}
+
+	return plaintext, nil
+}
+
+// Exists returns true if the path exists.
+func (s *Storage) Exists(p string) bool {
+	return s.inner.Exists(p)
+}
+
+// Stat returns file metadata. Sizes of encrypted files are ciphertext sizes.
+func (s *Storage) Stat(p string) (search.FileInfo, error) {
+	return s.inner.Stat(p)
+}
+
+// Walk traverses the directory tree of the wrapped storage.
+func (s *Storage) Walk(root string, walkFn search.WalkFunc) error {
+	return s.inner.Walk(root, walkFn)
+}
+
+// List returns entries in a directory of the wrapped storage.
+func (s *Storage) List(p string) ([]search.FileInfo, error) {
+	return s.inner.List(p)
+}
+
+// Remove deletes a file.
+func (s *Storage) Remove(p string) error {
+	return s.inner.Remove(p)
+}
+
+// Rename moves a file. Encrypted files stay encrypted.
+func (s *Storage) Rename(oldPath, newPath string) error {
+	return s.inner.Rename(oldPath, newPath)
+}
+
+// Root returns the root of the wrapped storage.
+func (s *Storage) Root() string {
+	return s.inner.Root()
+}
+
+// Ensure Storage implements search.Storage.
+var _ search.Storage = (*Storage)(nil)
@@ -0,0 +1,144 @@
+package encrypt
+
+import (
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+)
+
+func newKeyedStorage(t *testing.T, globs ...string) (*Storage, search.Storage) {
+	t.Helper()
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+
+	inner := bleve.MemStorage()
+	return New(inner, Options{
+		Identities: []age.Identity{identity},
+		Recipients: []age.Recipient{identity.Recipient()},
+		Globs:      globs,
+	}), inner
+}
+
+func TestStorage_WritePlainOutsideGlobs(t *testing.T) {
+	s, inner := newKeyedStorage(t, "secrets/**")
+
+	require.NoError(t, s.Write("notes/todo.md", []byte("# Todo")))
+
+	raw, err := inner.Read("notes/todo.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Todo", string(raw))
+}
+
+func TestStorage_WriteEncryptsMatchingGlobs(t *testing.T) {
+	s, inner := newKeyedStorage(t, "secrets/**")
+
+	require.NoError(t, s.Write("secrets/aws.md", []byte("key: abc")))
+
+	raw, err := inner.Read("secrets/aws.md")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(raw))
+	assert.True(t, strings.HasPrefix(string(raw), "-----BEGIN AGE ENCRYPTED FILE-----"))
+	assert.NotContains(t, string(raw), "abc")
+
+	plain, err := s.Read("secrets/aws.md")
+	require.NoError(t, err)
+	assert.Equal(t, "key: abc", string(plain))
+}
+
+func TestStorage_EncryptedFilesStayEncrypted(t *testing.T) {
+	s, inner := newKeyedStorage(t)
+
+	require.NoError(t, s.WriteEncrypted("incident.md", []byte("v1")))
+	require.NoError(t, s.Write("incident.md", []byte("v2")))
+
+	raw, err := inner.Read("incident.md")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(raw))
+
+	plain, err := s.Read("incident.md")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", string(plain))
+}
+
+func TestStorage_EncryptDecrypt(t *testing.T) {
+	s, inner := newKeyedStorage(t)
+	require.NoError(t, inner.Write("note.md", []byte("hello")))
+
+	require.NoError(t, s.Encrypt("note.md"))
+	encrypted, err := s.IsEncryptedPath("note.md")
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	// Encrypting twice is a no-op
+	raw, _ := inner.Read("note.md")
+	require.NoError(t, s.Encrypt("note.md"))
+	again, _ := inner.Read("note.md")
+	assert.Equal(t, raw, again)
+
+	require.NoError(t, s.Decrypt("note.md"))
+	raw, err = inner.Read("note.md")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(raw))
+}
+
+func TestStorage_ReadWithoutKey(t *testing.T) {
+	keyed, inner := newKeyedStorage(t)
+	require.NoError(t, keyed.WriteEncrypted("secret.md", []byte("hidden")))
+
+	keyless := New(inner, Options{})
+	_, err := keyless.Read("secret.md")
+	assert.ErrorIs(t, err, search.ErrEncrypted)
+	assert.False(t, keyless.CanDecrypt())
+}
+
+func TestStorage_ReadWithWrongKey(t *testing.T) {
+	keyed, inner := newKeyedStorage(t)
+	require.NoError(t, keyed.WriteEncrypted("secret.md", []byte("hidden")))
+
+	other, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	s := New(inner, Options{Identities: []age.Identity{other}})
+
+	_, err = s.Read("secret.md")
+	assert.ErrorIs(t, err, search.ErrEncrypted)
+}
+
+func TestStorage_WriteEncryptedWithoutRecipients(t *testing.T) {
+	s := New(bleve.MemStorage(), Options{Globs: []string{"secrets/**"}})
+
+	err := s.Write("secrets/aws.md", []byte("x"))
+	assert.ErrorIs(t, err, ErrNoRecipients)
+	assert.False(t, s.Exists("secrets/aws.md"))
+}
+
+func TestStorage_Passphrase(t *testing.T) {
+	recipient, err := age.NewScryptRecipient("correct horse")
+	require.NoError(t, err)
+	recipient.SetWorkFactor(10)
+	identity, err := age.NewScryptIdentity("correct horse")
+	require.NoError(t, err)
+
+	s := New(bleve.MemStorage(), Options{
+		Identities: []age.Identity{identity},
+		Recipients: []age.Recipient{recipient},
+	})
+
+	require.NoError(t, s.WriteEncrypted("note.md", []byte("secret")))
+	plain, err := s.Read("note.md")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.True(t, IsEncrypted([]byte("-----BEGIN AGE ENCRYPTED FILE-----\nabc")))
+	assert.True(t, IsEncrypted([]byte("age-encryption.org/v1\n-> X25519 abc")))
+	assert.False(t, IsEncrypted([]byte("---\ntitle: Plain\n---\n")))
+	assert.False(t, IsEncrypted(nil))
+}
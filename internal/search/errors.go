@@ -24,6 +24,12 @@ var (
 
 	// ErrStorageError is returned for filesystem-related errors.
 	ErrStorageError = errors.New("storage error")
+
+	// ErrReadOnly is returned when writing to a read-only storage backend.
+	ErrReadOnly = errors.New("storage is read-only")
+
+	// ErrEncrypted is returned when reading an encrypted file without a usable key.
+	ErrEncrypted = errors.New("file is encrypted and no matching key is available")
 )
 
 // IndexError wraps an error with additional context.
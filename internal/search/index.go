@@ -55,6 +55,10 @@ type Document struct {
 	// Path is the relative path from notebook root (e.g., "projects/todo.md")
 	Path string
 
+	// ID is the stable note ID from the "id" frontmatter field, if any.
+	// Unlike Path it survives renames.
+	ID string
+
 	// Title is extracted from frontmatter or first heading
 	Title string
 
@@ -78,6 +82,9 @@ type Document struct {
 
 	// Checksum for change detection (e.g., xxhash of content)
 	Checksum string
+
+	// Tasks are the task list items of the note, in file order
+	Tasks []Task
 }
 
 // IndexStats contains statistics about the index.
@@ -126,7 +126,7 @@ func convertField(f *fieldExprAST) search.Expr {
 	}
 
 	value := unquote(f.Value)
-	field := strings.ToLower(f.Field)
+	field := strings.ToLower(strings.TrimSuffix(f.Field, ":"))
 	op := normalizeOp(f.Operator)
 
 	// Date fields get special handling
@@ -36,7 +36,7 @@ type expressionAST struct {
 // existenceExprAST represents an existence check: has:field or missing:field
 type existenceExprAST struct {
 	Keyword string `parser:"@ExistenceKeyword ':'"`
-	Field   string `parser:"( @Field | @Word )"`
+	Field   string `parser:"@Word"`
 }
 
 // notExprAST represents a negated expression: -term or -field:value
@@ -45,9 +45,10 @@ type notExprAST struct {
 	Term  *termAST      `parser:"    | @@ )"`
 }
 
-// fieldExprAST represents a field-qualified expression: field:value or field:>value
+// fieldExprAST represents a field-qualified expression: field:value or field:>value.
+// Field holds the field name with its colon.
 type fieldExprAST struct {
-	Field    string `parser:"@Field ':'"`
+	Field    string `parser:"@Field"`
 	Operator string `parser:"@( '>''=' | '<''=' | '>' | '<' )?"`
 	Value    string `parser:"( @String | @Date | @Word )"`
 }
@@ -62,11 +63,14 @@ var queryLexer = lexer.MustSimple([]lexer.SimpleRule{
 	// Existence keywords must come before Field to be matched first
 	{Name: "ExistenceKeyword", Pattern: `(has|missing)`},
 	{Name: "OrKeyword", Pattern: `(?i)OR`},
-	{Name: "Field", Pattern: `(tag|title|path|created|modified|body|status)`},
+	// A field name only lexes as a field with its colon, so terms like
+	// "idea", "owner" or "due-diligence" stay words
+	{Name: "Field", Pattern: `(tag|title|path|created|modified|body|status|id|due|owner):`},
 	{Name: "String", Pattern: `"[^"]*"`},
 	// Date patterns must come before Word to capture dates properly
 	{Name: "Date", Pattern: `\d{4}-\d{2}-\d{2}`},
-	{Name: "Word", Pattern: `[^\s:"\-><>=]+`},
+	// A leading "-" negates; inside a word it is kept ("sprint-41")
+	{Name: "Word", Pattern: `[^\s:"\-><>=][^\s:"><=]*`},
 	{Name: "Punct", Pattern: `[:\-><>=]`},
 	{Name: "Whitespace", Pattern: `\s+`},
 })
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/zenobi-us/jot/internal/search"
@@ -30,6 +31,48 @@ func TestParser_Parse_SimpleTerm(t *testing.T) {
 			wantType: "TermExpr",
 			wantVal:  "project meeting",
 		},
+		{
+			name:     "word starting with a field name",
+			input:    "idea",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "idea",
+		},
+		{
+			name:     "field name without a value",
+			input:    "owner",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "owner",
+		},
+		{
+			name:     "hyphenated word starting with a field name",
+			input:    "due-diligence",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "due-diligence",
+		},
+		{
+			name:     "hyphenated word starting with owner",
+			input:    "owner-meeting",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "owner-meeting",
+		},
+		{
+			name:     "hyphenated word starting with status",
+			input:    "status-report",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "status-report",
+		},
+		{
+			name:     "longer word starting with a field name",
+			input:    "tagline",
+			wantLen:  1,
+			wantType: "TermExpr",
+			wantVal:  "tagline",
+		},
 		{
 			name:     "multiple terms",
 			input:    "meeting notes",
@@ -108,6 +151,34 @@ func TestParser_Parse_FieldExpr(t *testing.T) {
 			wantOp:    search.OpEquals,
 			wantValue: "important",
 		},
+		{
+			name:      "hyphenated value",
+			input:     "tag:sprint-41",
+			wantField: "tag",
+			wantOp:    search.OpEquals,
+			wantValue: "sprint-41",
+		},
+		{
+			name:      "note id",
+			input:     "id:01HX3Q5R7M8N9P0QRSTVWXYZ12",
+			wantField: "id",
+			wantOp:    search.OpEquals,
+			wantValue: "01HX3Q5R7M8N9P0QRSTVWXYZ12",
+		},
+		{
+			name:      "task owner",
+			input:     "owner:sam",
+			wantField: "owner",
+			wantOp:    search.OpEquals,
+			wantValue: "sam",
+		},
+		{
+			name:      "relative due date",
+			input:     "due:<=today+7",
+			wantField: "due",
+			wantOp:    search.OpLte,
+			wantValue: "today+7",
+		},
 	}
 
 	for _, tt := range tests {
@@ -471,3 +542,24 @@ func TestParser_Parse_OrExpressions(t *testing.T) {
 		}
 	})
 }
+
+func TestParser_Parse_HyphenatedWordsBesideFields(t *testing.T) {
+	query, err := New().Parse("status:open due-diligence -owner:sam has:due")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	want := []search.Expr{
+		search.FieldExpr{Field: "status", Op: search.OpEquals, Value: "open"},
+		search.TermExpr{Value: "due-diligence"},
+		search.NotExpr{Expr: search.FieldExpr{Field: "owner", Op: search.OpEquals, Value: "sam"}},
+		search.ExistsExpr{Field: "due"},
+	}
+	if len(query.Expressions) != len(want) {
+		t.Fatalf("got %d expressions %#v, want %d", len(query.Expressions), query.Expressions, len(want))
+	}
+	for i := range want {
+		if got := query.Expressions[i]; !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("expression %d = %#v, want %#v", i, got, want[i])
+		}
+	}
+}
@@ -49,6 +49,7 @@ type Storage interface {
 	Remove(path string) error
 
 	// Rename moves a file from oldPath to newPath.
+	// Parent directories of newPath are created automatically.
 	Rename(oldPath, newPath string) error
 
 	// Root returns the absolute path to the notebook root.
@@ -0,0 +1,109 @@
+package search
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Task is a GFM task list item ("- [ ] text") found in a note.
+//
+// Inline annotations in the text are extracted, so
+// "- [ ] Send the report due:2024-05-01 @sam #finance" is due on
+// 2024-05-01, owned by sam and tagged finance.
+type Task struct {
+	// Line is the 1-based line of the item in the note file
+	Line int `json:"line"`
+
+	// Text is the item text after the checkbox, annotations included
+	Text string `json:"text"`
+
+	// Done reports whether the box is ticked
+	Done bool `json:"done"`
+
+	// Due is the date of a "due:YYYY-MM-DD" annotation, if any
+	Due string `json:"due,omitempty"`
+
+	// Owners are the "@name" annotations, without the "@"
+	Owners []string `json:"owners,omitempty"`
+
+	// Tags are the "#tag" annotations (lowercase, without the "#")
+	Tags []string `json:"tags,omitempty"`
+}
+
+var (
+	taskItemPattern  = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX])\]\s+(.*)$`)
+	taskDuePattern   = regexp.MustCompile(`(?:^|\s)due:(\d{4}-\d{2}-\d{2})\b`)
+	taskOwnerPattern = regexp.MustCompile(`(?:^|\s)@([\w][\w.-]*)`)
+	taskTagPattern   = regexp.MustCompile(`(?:^|\s)#([\w][\w/-]*)`)
+)
+
+// ParseTasks returns the task list items of a note in file order. Items in
+// the frontmatter and in fenced code blocks are ignored.
+func ParseTasks(content []byte) []Task {
+	lines := strings.Split(string(content), "\n")
+
+	start := 0
+	if len(lines) > 0 && strings.TrimRight(lines[0], "\r") == "---" {
+		for i := 1; i < len(lines); i++ {
+			if strings.TrimRight(lines[i], "\r") == "---" {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	var tasks []Task
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], "\r")
+
+		trimmed := strings.TrimLeft(line, " \t")
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			marker := trimmed[:3]
+			if fence == "" {
+				fence = marker
+			} else if fence == marker {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+
+		match := taskItemPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		text := strings.TrimSpace(match[2])
+		if text == "" {
+			continue
+		}
+
+		task := Task{
+			Line: i + 1,
+			Text: text,
+			Done: match[1] != " ",
+		}
+		if due := taskDuePattern.FindStringSubmatch(text); due != nil {
+			task.Due = due[1]
+		}
+		for _, owner := range taskOwnerPattern.FindAllStringSubmatch(text, -1) {
+			task.Owners = appendUnique(task.Owners, strings.TrimRight(owner[1], ".-"))
+		}
+		for _, tag := range taskTagPattern.FindAllStringSubmatch(text, -1) {
+			task.Tags = appendUnique(task.Tags, strings.ToLower(tag[1]))
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,413 @@
+// Package webdav implements search.Storage against a remote WebDAV server.
+//
+// Only the small subset of RFC 4918 needed for note storage is used:
+// GET, PUT, DELETE, MOVE, MKCOL and PROPFIND. Paths passed to the storage
+// are relative to the configured base URL.
+package webdav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// Options configures the WebDAV client.
+type Options struct {
+	// Username and Password enable HTTP basic authentication when set.
+	Username string
+	Password string
+
+	// Client is the HTTP client used for requests.
+	// Defaults to a client with a 30 second timeout.
+	Client *http.Client
+}
+
+// Storage implements search.Storage using WebDAV requests.
+type Storage struct {
+	base   *url.URL
+	client *http.Client
+	opts   Options
+}
+
+// New creates a WebDAV storage rooted at rawURL.
+func New(rawURL string, opts Options) (*Storage, error) {
+	base, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webdav url: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webdav url %q: scheme must be http or https", rawURL)
+	}
+	if !strings.HasSuffix(base.Path, "/") {
+		base.Path += "/"
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &Storage{
+		base:   base,
+		client: client,
+		opts:   opts,
+	}, nil
+}
+
+// resolve returns the absolute URL for a relative storage path.
+func (s *Storage) resolve(p string, dir bool) string {
+	rel := cleanPath(p)
+	u := *s.base
+	if rel != "." {
+		u.Path = s.base.Path + rel
+		if dir {
+			u.Path += "/"
+		}
+	}
+	return u.String()
+}
+
+// do executes a request against a relative path.
+func (s *Storage) do(method, p string, dir bool, body io.Reader, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.resolve(p, dir), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if s.opts.Username != "" || s.opts.Password != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	return s.client.Do(req)
+}
+
+// Read reads the content of a file.
+func (s *Storage) Read(p string) ([]byte, error) {
+	rc, err := s.ReadStream(p)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+	return io.ReadAll(rc)
+}
+
+// ReadStream opens a file for reading.
+func (s *Storage) ReadStream(p string) (io.ReadCloser, error) {
+	resp, err := s.do(http.MethodGet, p, false, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, statusError("read", p, resp)
+	}
+	return resp.Body, nil
+}
+
+// Write writes content to a file, creating parent collections as needed.
+func (s *Storage) Write(p string, content []byte) error {
+	if err := s.mkdirAll(path.Dir(cleanPath(p))); err != nil {
+		return err
+	}
+
+	resp, err := s.do(http.MethodPut, p, false, bytes.NewReader(content), nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	default:
+		return statusError("write", p, resp)
+	}
+}
+
+// mkdirAll creates a collection and all of its parents.
+func (s *Storage) mkdirAll(dir string) error {
+	if dir == "." || dir == "/" || dir == "" {
+		return nil
+	}
+	if info, err := s.Stat(dir); err == nil {
+		if !info.IsDir {
+			return &fs.PathError{Op: "mkdir", Path: dir, Err: fs.ErrExist}
+		}
+		return nil
+	}
+	if err := s.mkdirAll(path.Dir(dir)); err != nil {
+		return err
+	}
+
+	resp, err := s.do("MKCOL", dir, true, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusOK, http.StatusMethodNotAllowed:
+		// 405 means the collection already exists.
+		return nil
+	default:
+		return statusError("mkdir", dir, resp)
+	}
+}
+
+// Exists returns true if the path exists.
+func (s *Storage) Exists(p string) bool {
+	_, err := s.Stat(p)
+	return err == nil
+}
+
+// Stat returns file metadata.
+func (s *Storage) Stat(p string) (search.FileInfo, error) {
+	infos, err := s.propfind(p, "0")
+	if err != nil {
+		return search.FileInfo{}, err
+	}
+	if len(infos) == 0 {
+		return search.FileInfo{}, &fs.PathError{Op: "stat", Path: p, Err: fs.ErrNotExist}
+	}
+	return infos[0], nil
+}
+
+// Walk traverses the directory tree starting at root in lexical order.
+func (s *Storage) Walk(root string, walkFn search.WalkFunc) error {
+	info, err := s.Stat(root)
+	if err != nil {
+		return walkFn(root, search.FileInfo{}, err)
+	}
+
+	err = s.walk(info, walkFn)
+	if err == search.SkipDir || err == search.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walk visits info and, for collections, its children.
+func (s *Storage) walk(info search.FileInfo, walkFn search.WalkFunc) error {
+	if err := walkFn(info.Path, info, nil); err != nil {
+		if err == search.SkipDir && info.IsDir {
+			return nil
+		}
+		return err
+	}
+
+	if !info.IsDir {
+		return nil
+	}
+
+	children, err := s.List(info.Path)
+	if err != nil {
+		return walkFn(info.Path, info, err)
+	}
+
+	for _, child := range children {
+		if err := s.walk(child, walkFn); err != nil {
+			if err == search.SkipDir {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// List returns entries in a collection (non-recursive), sorted by name.
+func (s *Storage) List(p string) ([]search.FileInfo, error) {
+	infos, err := s.propfind(p, "1")
+	if err != nil {
+		return nil, err
+	}
+
+	self := cleanPath(p)
+	result := make([]search.FileInfo, 0, len(infos))
+	for _, info := range infos {
+		if cleanPath(info.Path) == self {
+			continue
+		}
+		result = append(result, info)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// Remove deletes a file.
+func (s *Storage) Remove(p string) error {
+	resp, err := s.do(http.MethodDelete, p, false, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted:
+		return nil
+	default:
+		return statusError("remove", p, resp)
+	}
+}
+
+// Rename moves a file from oldPath to newPath.
+func (s *Storage) Rename(oldPath, newPath string) error {
+	if err := s.mkdirAll(path.Dir(cleanPath(newPath))); err != nil {
+		return err
+	}
+
+	resp, err := s.do("MOVE", oldPath, false, nil, map[string]string{
+		"Destination": s.resolve(newPath, false),
+		"Overwrite":   "T",
+	})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusNoContent, http.StatusOK:
+		return nil
+	default:
+		return statusError("rename", oldPath, resp)
+	}
+}
+
+// Root returns the base URL of the storage.
+func (s *Storage) Root() string {
+	return s.base.String()
+}
+
+// propfindBody requests the properties needed to build search.FileInfo.
+const propfindBody = `<?xml version="1.0" encoding="utf-8"?>
+<propfind xmlns="DAV:"><prop><resourcetype/><getcontentlength/><getlastmodified/></prop></propfind>`
+
+// multistatus mirrors the subset of a PROPFIND response we read.
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				ContentLength string `xml:"getcontentlength"`
+				LastModified  string `xml:"getlastmodified"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// propfind issues a PROPFIND request and converts the response to FileInfo.
+func (s *Storage) propfind(p, depth string) ([]search.FileInfo, error) {
+	resp, err := s.do("PROPFIND", p, false, strings.NewReader(propfindBody), map[string]string{
+		"Depth":        depth,
+		"Content-Type": "application/xml; charset=utf-8",
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, statusError("stat", p, resp)
+	}
+
+	var ms multistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, fmt.Errorf("invalid PROPFIND response for %s: %w", p, err)
+	}
+
+	infos := make([]search.FileInfo, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		rel, err := s.relativeHref(r.Href)
+		if err != nil {
+			continue
+		}
+
+		info := search.FileInfo{
+			Path: filepath.FromSlash(rel),
+			Name: path.Base(rel),
+			Mode: 0644,
+		}
+		for _, ps := range r.Propstat {
+			if !strings.Contains(ps.Status, " 200 ") {
+				continue
+			}
+			if ps.Prop.ResourceType.Collection != nil {
+				info.IsDir = true
+				info.Mode = fs.ModeDir | 0755
+			}
+			if n, err := strconv.ParseInt(ps.Prop.ContentLength, 10, 64); err == nil {
+				info.Size = n
+			}
+			if t, err := http.ParseTime(ps.Prop.LastModified); err == nil {
+				info.ModTime = t
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
+
+// relativeHref converts a response href into a path relative to the base URL.
+func (s *Storage) relativeHref(href string) (string, error) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return "", err
+	}
+	p := u.Path
+	if !strings.HasPrefix(p, s.base.Path) {
+		if strings.TrimSuffix(p, "/")+"/" == s.base.Path {
+			return ".", nil
+		}
+		return "", fmt.Errorf("href %q outside of %q", href, s.base.Path)
+	}
+	return cleanPath(strings.TrimPrefix(p, s.base.Path)), nil
+}
+
+// cleanPath normalises a storage path to a slash-separated relative path.
+func cleanPath(p string) string {
+	p = path.Clean("/" + filepath.ToSlash(p))
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// statusError converts an unexpected HTTP status into an error.
+// 404 responses wrap fs.ErrNotExist so callers can use errors.Is.
+func statusError(op, p string, resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return &fs.PathError{Op: op, Path: p, Err: fs.ErrNotExist}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &fs.PathError{Op: op, Path: p, Err: fs.ErrPermission}
+	default:
+		return &fs.PathError{Op: op, Path: p, Err: fmt.Errorf("%w: unexpected status %s", search.ErrStorageError, resp.Status)}
+	}
+}
+
+// Ensure Storage implements search.Storage.
+var _ search.Storage = (*Storage)(nil)
@@ -0,0 +1,140 @@
+package webdav
+
+import (
+	"errors"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xwebdav "golang.org/x/net/webdav"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// newTestStorage starts an in-memory WebDAV server and returns a storage
+// rooted at its /notes/ collection.
+func newTestStorage(t *testing.T, opts Options) *Storage {
+	t.Helper()
+
+	handler := &xwebdav.Handler{
+		FileSystem: xwebdav.NewMemFS(),
+		LockSystem: xwebdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	s, err := New(srv.URL+"/notes", opts)
+	require.NoError(t, err)
+	require.NoError(t, s.mkdirAll("."))
+
+	resp, err := s.do("MKCOL", ".", true, nil, nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	return s
+}
+
+func TestNew_InvalidScheme(t *testing.T) {
+	_, err := New("ftp://example.com/notes", Options{})
+	assert.Error(t, err)
+}
+
+func TestStorage_WriteRead(t *testing.T) {
+	s := newTestStorage(t, Options{})
+
+	require.NoError(t, s.Write("projects/todo.md", []byte("# Todo")))
+
+	data, err := s.Read("projects/todo.md")
+	require.NoError(t, err)
+	assert.Equal(t, "# Todo", string(data))
+	assert.True(t, s.Exists("projects"))
+}
+
+func TestStorage_Read_NotFound(t *testing.T) {
+	s := newTestStorage(t, Options{})
+
+	_, err := s.Read("missing.md")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+	assert.False(t, s.Exists("missing.md"))
+}
+
+func TestStorage_Stat(t *testing.T) {
+	s := newTestStorage(t, Options{})
+	require.NoError(t, s.Write("readme.md", []byte("hello")))
+
+	info, err := s.Stat("readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "readme.md", info.Name)
+	assert.Equal(t, int64(5), info.Size)
+	assert.False(t, info.IsDir)
+	assert.False(t, info.ModTime.IsZero())
+}
+
+func TestStorage_ListAndWalk(t *testing.T) {
+	s := newTestStorage(t, Options{})
+	require.NoError(t, s.Write("readme.md", []byte("a")))
+	require.NoError(t, s.Write("projects/todo.md", []byte("b")))
+	require.NoError(t, s.Write("projects/a/b.md", []byte("c")))
+
+	entries, err := s.List("projects")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Name)
+	assert.True(t, entries[0].IsDir)
+	assert.Equal(t, "todo.md", entries[1].Name)
+
+	var files []string
+	err = s.Walk(".", func(p string, info search.FileInfo, err error) error {
+		require.NoError(t, err)
+		if !info.IsDir {
+			files = append(files, filepath.ToSlash(p))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"projects/a/b.md", "projects/todo.md", "readme.md"}, files)
+}
+
+func TestStorage_RemoveAndRename(t *testing.T) {
+	s := newTestStorage(t, Options{})
+	require.NoError(t, s.Write("a.md", []byte("a")))
+
+	require.NoError(t, s.Rename("a.md", "archive/b.md"))
+	assert.False(t, s.Exists("a.md"))
+	assert.True(t, s.Exists("archive/b.md"))
+
+	require.NoError(t, s.Remove("archive/b.md"))
+	assert.False(t, s.Exists("archive/b.md"))
+}
+
+func TestStorage_BasicAuth(t *testing.T) {
+	handler := &xwebdav.Handler{
+		FileSystem: xwebdav.NewMemFS(),
+		LockSystem: xwebdav.NewMemLS(),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "jot" || pass != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	anon, err := New(srv.URL, Options{})
+	require.NoError(t, err)
+	_, err = anon.Read("readme.md")
+	assert.True(t, errors.Is(err, fs.ErrPermission))
+
+	authed, err := New(srv.URL, Options{Username: "jot", Password: "secret"})
+	require.NoError(t, err)
+	require.NoError(t, authed.Write("readme.md", []byte("hi")))
+	data, err := authed.Read("readme.md")
+	require.NoError(t, err)
+	assert.Equal(t, "hi", string(data))
+}
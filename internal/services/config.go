@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	kjson "github.com/knadh/koanf/parsers/json"
@@ -131,6 +132,16 @@ func (c *ConfigService) Write(cfg Config) error {
 	return nil
 }
 
+// RegisterNotebook adds a notebook directory to the registered notebooks,
+// unless it is already registered.
+func (c *ConfigService) RegisterNotebook(notebookDir string) error {
+	if slices.Contains(c.Store.Notebooks, notebookDir) {
+		return nil
+	}
+	c.Store.Notebooks = append(c.Store.Notebooks, notebookDir)
+	return c.Write(c.Store)
+}
+
 // Path returns the config file path.
 func (c *ConfigService) Path() string {
 	return c.path
@@ -20,10 +20,18 @@ type Display struct {
 
 // NewDisplay creates a new display service with glamour rendering.
 func NewDisplay() (*Display, error) {
-	renderer, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
-		glamour.WithWordWrap(100),
-	)
+	return NewDisplayWithWidth(100, "")
+}
+
+// NewDisplayWithWidth creates a display service that wraps rendered
+// markdown at width columns. An empty style picks one from the terminal
+// background, which queries the terminal.
+func NewDisplayWithWidth(width int, style string) (*Display, error) {
+	styleOpt := glamour.WithAutoStyle()
+	if style != "" {
+		styleOpt = glamour.WithStandardStyle(style)
+	}
+	renderer, err := glamour.NewTermRenderer(styleOpt, glamour.WithWordWrap(width))
 	if err != nil {
 		return nil, err
 	}
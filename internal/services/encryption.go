@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/encrypt"
+)
+
+// EnvKeyFile overrides the encryption key_file from .jot.json, so every team
+// member can keep their age identity wherever they like.
+const EnvKeyFile = "JOT_KEY_FILE"
+
+// EncryptionConfig configures encryption at rest in .jot.json.
+type EncryptionConfig struct {
+	// Globs select notes that are always encrypted, e.g. "secrets/**".
+	Globs []string `json:"globs,omitempty"`
+
+	// KeyFile is an age identity file used to decrypt and, via its public
+	// keys, encrypt notes. Relative paths resolve against the notebook
+	// directory and "~/" expands to the home directory.
+	KeyFile string `json:"key_file,omitempty"`
+
+	// Recipients are additional age public keys notes are encrypted to,
+	// so the whole team can read them.
+	Recipients []string `json:"recipients,omitempty"`
+
+	// PassphraseEnv names the environment variable holding a passphrase.
+	// Passphrase encryption is only used when no public keys are available.
+	PassphraseEnv string `json:"passphrase_env,omitempty"`
+
+	// ScryptWorkFactor overrides the passphrase work factor (log2 of the scrypt N).
+	ScryptWorkFactor int `json:"scrypt_work_factor,omitempty"`
+}
+
+// NewEncryptedStorage wraps storage with age encryption.
+//
+// The wrapper is applied even without an encryption config so that encrypted
+// notes are always detected and reported rather than indexed as ciphertext.
+// A missing key file or passphrase is not an error: encrypted notes simply
+// can't be read.
+func NewEncryptedStorage(storage search.Storage, cfg *EncryptionConfig, notebookDir string) (*encrypt.Storage, error) {
+	if cfg == nil {
+		return encrypt.New(storage, encrypt.Options{}), nil
+	}
+
+	opts := encrypt.Options{Globs: cfg.Globs}
+
+	for _, r := range cfg.Recipients {
+		recipients, err := age.ParseRecipients(strings.NewReader(r))
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption recipient %q: %w", r, err)
+		}
+		opts.Recipients = append(opts.Recipients, recipients...)
+	}
+
+	keyFile := cfg.KeyFile
+	if override := strings.TrimSpace(os.Getenv(EnvKeyFile)); override != "" {
+		keyFile = override
+	}
+	if keyFile != "" {
+		identities, err := loadIdentities(resolveKeyPath(keyFile, notebookDir))
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range identities {
+			opts.Identities = append(opts.Identities, id)
+			switch id := id.(type) {
+			case *age.X25519Identity:
+				opts.Recipients = append(opts.Recipients, id.Recipient())
+			case *age.HybridIdentity:
+				opts.Recipients = append(opts.Recipients, id.Recipient())
+			}
+		}
+	}
+
+	if cfg.PassphraseEnv != "" {
+		if passphrase := os.Getenv(cfg.PassphraseEnv); passphrase != "" {
+			identity, err := age.NewScryptIdentity(passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("invalid encryption passphrase: %w", err)
+			}
+			opts.Identities = append(opts.Identities, identity)
+
+			// age doesn't allow mixing scrypt with other recipients
+			if len(opts.Recipients) == 0 {
+				recipient, err := age.NewScryptRecipient(passphrase)
+				if err != nil {
+					return nil, fmt.Errorf("invalid encryption passphrase: %w", err)
+				}
+				if cfg.ScryptWorkFactor > 0 {
+					recipient.SetWorkFactor(cfg.ScryptWorkFactor)
+				}
+				opts.Recipients = append(opts.Recipients, recipient)
+			}
+		}
+	}
+
+	return encrypt.New(storage, opts), nil
+}
+
+// loadIdentities reads an age identity file. A missing file yields no
+// identities so notebooks still open for users without the key.
+func loadIdentities(path string) ([]age.Identity, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		log := Log("Encryption")
+		log.Debug().Str("path", path).Msg("encryption key file not found")
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open encryption key file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key file %s: %w", path, err)
+	}
+	return identities, nil
+}
+
+// resolveKeyPath expands "~/" and resolves relative paths against notebookDir.
+func resolveKeyPath(path, notebookDir string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	if !filepath.IsAbs(path) {
+		return filepath.Join(notebookDir, path)
+	}
+	return path
+}
+
+// Encryption returns the encryption layer of the notebook storage,
+// or nil if the storage isn't wrapped.
+func (n *Notebook) Encryption() *encrypt.Storage {
+	enc, _ := n.Storage.(*encrypt.Storage)
+	return enc
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// ImportConfig configures "jot import" in .jot.json.
+type ImportConfig struct {
+	// ENEXPath is a text/template for the paths of notes imported from
+	// Evernote, relative to the notebook root. See ENEXPathData for the
+	// available fields.
+	ENEXPath string `json:"enex_path,omitempty"`
+}
+
+// DefaultENEXPath is used when the notebook configures no ENEX path.
+const DefaultENEXPath = "evernote/{{ slug .Notebook }}/{{ .Slug }}.md"
+
+// SourceIDField is the frontmatter field holding the ID of an imported
+// note in its source, used to recognise it when importing again.
+const SourceIDField = "source_id"
+
+// ENEXPathData is the data available to ENEX path patterns. The "slug"
+// function slugifies any field.
+type ENEXPathData struct {
+	// Title is the note title and Slug its slugified form.
+	Title, Slug string
+	// Notebook is the export file name without .enex, which Evernote names
+	// after the exported notebook.
+	Notebook string
+	// Date is the note's creation date as YYYY-MM-DD, and Year, Month and
+	// Day its zero-padded parts.
+	Date, Year, Month, Day string
+}
+
+// ENEXImportOptions controls an Evernote import.
+type ENEXImportOptions struct {
+	// PathPattern overrides the notebook's ENEX path pattern.
+	PathPattern string
+	// Update rewrites notes imported before whose content changed.
+	Update bool
+}
+
+// Statuses of notes in an import plan.
+const (
+	ImportNew       = "new"
+	ImportUpdated   = "updated"
+	ImportUnchanged = "unchanged"
+	ImportSkipped   = "skipped"
+)
+
+// ENEXImport is the plan for importing an Evernote export. It is built
+// without writing anything, so it doubles as the dry-run report.
+type ENEXImport struct {
+	File     string     `json:"file"`
+	Notebook string     `json:"notebook"`
+	Notes    []ENEXNote `json:"notes"`
+}
+
+// ENEXNote describes how one Evernote note is imported.
+type ENEXNote struct {
+	Title    string `json:"title"`
+	SourceID string `json:"source_id"`
+	Path     string `json:"path"`
+	// Status is new, updated, unchanged, or skipped when the note was
+	// imported before and changed without Update.
+	Status      string   `json:"status"`
+	Attachments []string `json:"attachments,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
+
+	Content   []byte            `json:"-"`
+	resources map[string][]byte // attachment path -> data
+}
+
+// enexNote is a <note> of an ENEX export.
+type enexNote struct {
+	Title      string         `xml:"title"`
+	Content    string         `xml:"content"`
+	Created    string         `xml:"created"`
+	Updated    string         `xml:"updated"`
+	Tags       []string       `xml:"tag"`
+	SourceURL  string         `xml:"note-attributes>source-url"`
+	Author     string         `xml:"note-attributes>author"`
+	Resources  []enexResource `xml:"resource"`
+	createdAt  time.Time
+	modifiedAt time.Time
+}
+
+type enexResource struct {
+	Data struct {
+		Encoding string `xml:"encoding,attr"`
+		Value    string `xml:",chardata"`
+	} `xml:"data"`
+	Mime     string `xml:"mime"`
+	FileName string `xml:"resource-attributes>file-name"`
+}
+
+// enexTimeFormat is the timestamp format of ENEX exports.
+const enexTimeFormat = "20060102T150405Z"
+
+// readENEX decodes the notes of an export one at a time.
+func readENEX(r io.Reader, fn func(*enexNote) error) error {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "note" {
+			continue
+		}
+		var note enexNote
+		if err := decoder.DecodeElement(&note, &start); err != nil {
+			return err
+		}
+		note.Title = strings.TrimSpace(note.Title)
+		note.createdAt, _ = time.Parse(enexTimeFormat, strings.TrimSpace(note.Created))
+		note.modifiedAt, _ = time.Parse(enexTimeFormat, strings.TrimSpace(note.Updated))
+		if note.modifiedAt.IsZero() {
+			note.modifiedAt = note.createdAt
+		}
+		if err := fn(&note); err != nil {
+			return err
+		}
+	}
+}
+
+// sourceID identifies an Evernote note across exports. Exports carry no
+// note GUID, so it is derived from the title and creation time.
+func (n *enexNote) sourceID() string {
+	sum := sha256.Sum256([]byte(n.Title + "\x00" + strings.TrimSpace(n.Created)))
+	return "evernote:" + hex.EncodeToString(sum[:8])
+}
+
+// PlanENEXImport reads an Evernote export and plans where each note goes.
+// Notes whose source ID is already in the notebook keep their path and are
+// only rewritten with opts.Update.
+func (n *Notebook) PlanENEXImport(ctx context.Context, file string, opts ENEXImportOptions) (*ENEXImport, error) {
+	pattern := opts.PathPattern
+	if pattern == "" && n.Config.Import != nil {
+		pattern = n.Config.Import.ENEXPath
+	}
+	if pattern == "" {
+		pattern = DefaultENEXPath
+	}
+
+	existing, err := n.Notes.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	imported := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, note := range existing {
+		taken[note.File.Relative] = true
+		if id, ok := note.Metadata[SourceIDField].(string); ok && id != "" {
+			imported[id] = note.File.Relative
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	plan := &ENEXImport{
+		File:     file,
+		Notebook: strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)),
+		Notes:    []ENEXNote{},
+	}
+	seen := make(map[string]int)
+	err = readENEX(f, func(source *enexNote) error {
+		note := ENEXNote{Title: source.Title, SourceID: source.sourceID(), Status: ImportNew}
+		// Notes sharing a title and creation time are told apart by order
+		if seen[note.SourceID]++; seen[note.SourceID] > 1 {
+			note.SourceID = fmt.Sprintf("%s-%d", note.SourceID, seen[note.SourceID])
+		}
+		if relPath, ok := imported[note.SourceID]; ok {
+			note.Path = relPath
+		} else {
+			relPath, err := enexPath(pattern, plan.Notebook, source)
+			if err != nil {
+				return fmt.Errorf("invalid ENEX path pattern: %w", err)
+			}
+			note.Path = uniquePath(relPath, taken)
+			imported[note.SourceID] = note.Path
+		}
+		taken[note.Path] = true
+
+		if err := convertENEXNote(&note, source); err != nil {
+			return fmt.Errorf("%s: %w", note.Path, err)
+		}
+		if n.Storage.Exists(note.Path) {
+			current, err := n.Storage.Read(note.Path)
+			if err != nil {
+				return err
+			}
+			switch {
+			case string(current) == string(note.Content):
+				note.Status = ImportUnchanged
+			case opts.Update:
+				note.Status = ImportUpdated
+			default:
+				note.Status = ImportSkipped
+			}
+		}
+		plan.Notes = append(plan.Notes, note)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	return plan, nil
+}
+
+// ApplyENEXImport writes the new and updated notes of a plan with their
+// attachments and indexes them.
+func (n *Notebook) ApplyENEXImport(ctx context.Context, plan *ENEXImport) error {
+	for _, note := range plan.Notes {
+		if note.Status != ImportNew && note.Status != ImportUpdated {
+			continue
+		}
+		for _, attachment := range note.Attachments {
+			if err := n.Storage.Write(attachment, note.resources[attachment]); err != nil {
+				return fmt.Errorf("failed to write %s: %w", attachment, err)
+			}
+		}
+		if err := n.Storage.Write(note.Path, note.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", note.Path, err)
+		}
+		if err := n.Notes.IndexFile(ctx, note.Path); err != nil {
+			n.Notes.log.Warn().Err(err).Str("path", note.Path).Msg("failed to index imported note")
+		}
+	}
+	return nil
+}
+
+// enexPath renders the path pattern for a note.
+func enexPath(pattern, notebook string, note *enexNote) (string, error) {
+	slug := core.Slugify(note.Title)
+	if slug == "" {
+		slug = strings.TrimPrefix(note.sourceID(), "evernote:")
+	}
+	created := note.createdAt
+	data := ENEXPathData{
+		Title:    note.Title,
+		Slug:     slug,
+		Notebook: notebook,
+		Date:     created.Format("2006-01-02"),
+		Year:     created.Format("2006"),
+		Month:    created.Format("01"),
+		Day:      created.Format("02"),
+	}
+	return renderPathPattern(pattern, template.FuncMap{"slug": core.Slugify}, data)
+}
+
+// uniquePath appends -2, -3, ... to a note path already in use.
+func uniquePath(relPath string, taken map[string]bool) string {
+	if !taken[relPath] {
+		return relPath
+	}
+	stem := strings.TrimSuffix(relPath, ".md")
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d.md", stem, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// convertENEXNote builds the markdown of a note and its attachments, which
+// are stored in a "<note>.resources" folder next to it.
+func convertENEXNote(note *ENEXNote, source *enexNote) error {
+	folder := strings.TrimSuffix(note.Path, ".md") + ".resources"
+	note.resources = make(map[string][]byte)
+
+	type resource struct {
+		path  string
+		image bool
+		used  bool
+	}
+	byHash := make(map[string]*resource)
+	var order []string
+	names := make(map[string]bool)
+	for i, res := range source.Resources {
+		data, err := decodeENEXData(res.Data.Encoding, res.Data.Value)
+		if err != nil {
+			note.Warnings = append(note.Warnings, fmt.Sprintf("resource %d: %v", i+1, err))
+			continue
+		}
+		sum := md5.Sum(data)
+		hash := hex.EncodeToString(sum[:])
+		if _, ok := byHash[hash]; ok {
+			continue
+		}
+
+		name := resourceFileName(res.FileName, hash, res.Mime)
+		rel := path.Join(folder, uniqueName(name, names))
+		note.resources[rel] = data
+		note.Attachments = append(note.Attachments, rel)
+		byHash[hash] = &resource{path: rel, image: strings.HasPrefix(res.Mime, "image/")}
+		order = append(order, hash)
+	}
+
+	body, converter, err := enmlToMarkdown(source.Content, func(hash string) (string, bool) {
+		res, ok := byHash[hash]
+		if !ok {
+			return "", false
+		}
+		res.used = true
+		link := fmt.Sprintf("[%s](%s)", path.Base(res.path), markdownPath(relativePath(note.Path, res.path)))
+		if res.image {
+			link = "!" + link
+		}
+		return link, true
+	})
+	if err != nil {
+		return err
+	}
+	for _, hash := range converter.missing {
+		note.Warnings = append(note.Warnings, "missing resource "+hash)
+	}
+	if converter.encrypted > 0 {
+		note.Warnings = append(note.Warnings, fmt.Sprintf("%d encrypted sections not imported", converter.encrypted))
+	}
+
+	// Attachments the note doesn't embed are listed at the end
+	var unused []string
+	for _, hash := range order {
+		if res := byHash[hash]; !res.used {
+			unused = append(unused, fmt.Sprintf("- [%s](%s)", path.Base(res.path), markdownPath(relativePath(note.Path, res.path))))
+		}
+	}
+	if len(unused) > 0 {
+		body += "\n## Attachments\n\n" + strings.Join(unused, "\n") + "\n"
+	}
+
+	title := source.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	content := "# " + title + "\n\n" + strings.TrimLeft(body, "\n")
+
+	set := map[string]any{
+		"title":       title,
+		SourceIDField: note.SourceID,
+	}
+	if !source.createdAt.IsZero() {
+		set["created"] = source.createdAt.Format(time.RFC3339)
+		set["modified"] = source.modifiedAt.Format(time.RFC3339)
+	}
+	var tags []string
+	for _, tag := range source.Tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !hasFold(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > 0 {
+		set["tags"] = tags
+	}
+	if source.SourceURL != "" {
+		set["source_url"] = source.SourceURL
+	}
+	if source.Author != "" {
+		set["author"] = source.Author
+	}
+
+	updated, err := UpdateFrontmatter([]byte(content), set, nil)
+	if err != nil {
+		return err
+	}
+	note.Content = updated
+	return nil
+}
+
+// decodeENEXData decodes the data of a resource, base64 unless stated
+// otherwise.
+func decodeENEXData(encoding, value string) ([]byte, error) {
+	if encoding != "" && encoding != "base64" {
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+	if err != nil {
+		return nil, errors.New("invalid base64 data")
+	}
+	return data, nil
+}
+
+// enexExtensions are used for resources without a file name, before the
+// system MIME table whose choice varies.
+var enexExtensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/svg+xml":   ".svg",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"audio/mpeg":      ".mp3",
+	"audio/wav":       ".wav",
+	"text/plain":      ".txt",
+}
+
+// resourceFileName returns a safe file name for a resource.
+func resourceFileName(name, hash, mimeType string) string {
+	name = strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-").Replace(name))
+	if name != "" && name != "." && name != ".." && !strings.HasPrefix(name, ".") {
+		return name
+	}
+	ext, ok := enexExtensions[mimeType]
+	if !ok {
+		if exts, _ := mime.ExtensionsByType(mimeType); len(exts) > 0 {
+			ext = exts[0]
+		} else {
+			ext = ".bin"
+		}
+	}
+	return hash[:12] + ext
+}
+
+// uniqueName appends -2, -3, ... to a file name already used.
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	ext := path.Ext(name)
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
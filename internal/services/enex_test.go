@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestENMLToMarkdown(t *testing.T) {
+	media := func(hash string) (string, bool) {
+		if hash == "abc" {
+			return "![img.png](note.resources/img.png)", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name string
+		enml string
+		want string
+	}{
+		{
+			name: "lines, entities and inline markup",
+			enml: "<en-note><div>Agenda&nbsp;for <b>Monday </b>&amp; <i>Tuesday</i></div><div><br/></div><div>Next</div></en-note>",
+			want: "Agenda for **Monday** & *Tuesday*\n\nNext\n",
+		},
+		{
+			name: "headings, links and rules",
+			enml: "<en-note><h2>Links</h2><p>See <a href=\"https://x.dev/a b\">docs</a> or <a href=\"https://x.dev\">https://x.dev</a></p><hr/></en-note>",
+			want: "## Links\n\nSee [docs](https://x.dev/a%20b) or <https://x.dev>\n\n---\n",
+		},
+		{
+			name: "to-dos and nested lists",
+			enml: "<en-note><div><en-todo checked=\"true\"/>Done</div><div><en-todo/>Open</div>" +
+				"<ul><li>One</li><li><div><en-todo/>Two</div><ol><li>A</li><li>B</li></ol></li></ul></en-note>",
+			want: "- [x] Done\n- [ ] Open\n\n- One\n- [ ] Two\n  1. A\n  2. B\n",
+		},
+		{
+			name: "media, tables and code blocks",
+			enml: "<en-note><div><en-media hash=\"ABC\" type=\"image/png\"/></div>" +
+				"<table><tr><td>a</td><td>b|c</td></tr><tr><td>1</td></tr></table>" +
+				"<div style=\"-en-codeblock:true\"><div>if x {</div><div>  y()</div><div>}</div></div>" +
+				"<pre>raw  <code>text</code></pre></en-note>",
+			want: "![img.png](note.resources/img.png)\n\n| a | b\\|c |\n| --- | --- |\n| 1 |  |\n\n```\nif x {\n  y()\n}\n```\n\n```\nraw  text\n```\n",
+		},
+		{
+			name: "quotes",
+			enml: "<en-note><blockquote><div>Quoted</div><div>text</div></blockquote></en-note>",
+			want: "> Quoted\n> text\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := enmlToMarkdown(tt.enml, media)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, converter, err := enmlToMarkdown(`<en-note><en-media hash="def"/><en-crypt>secret</en-crypt></en-note>`, media)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"def"}, converter.missing)
+	assert.Equal(t, 1, converter.encrypted)
+}
+
+// enexFixture returns an export with a note embedding an image, a note
+// with a PDF attachment and a note with a non-ASCII title.
+func enexFixture() string {
+	image := []byte("png bytes")
+	sum := md5.Sum(image)
+	note := func(title, created, body, extra string) string {
+		return fmt.Sprintf("<note><title>%s</title><created>%s</created><updated>20240102T080000Z</updated>%s"+
+			"<content><![CDATA[<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE en-note SYSTEM \"http://xml.evernote.com/pub/enml2.dtd\">\n<en-note>%s</en-note>]]></content></note>\n",
+			title, created, extra, body)
+	}
+	return "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<en-export>\n" +
+		note("Trip Plan", "20240101T100000Z", `<div>Pack</div><en-media hash="`+hex.EncodeToString(sum[:])+`" type="image/png"/>`,
+			"<tag>travel</tag><tag>2024</tag><note-attributes><source-url>https://example.com</source-url></note-attributes>"+
+				"<resource><data encoding=\"base64\">"+base64.StdEncoding.EncodeToString(image)+"</data><mime>image/png</mime>"+
+				"<resource-attributes><file-name>map.png</file-name></resource-attributes></resource>") +
+		note("Trip Plan", "20240301T100000Z", "<div>Second trip</div>",
+			"<resource><data>"+base64.StdEncoding.EncodeToString([]byte("pdf"))+"</data><mime>application/pdf</mime></resource>") +
+		note("旅行", "20240401T100000Z", "<div>Unicode</div>", "") +
+		"</en-export>\n"
+}
+
+func openENEXNotebook(t *testing.T) (*Notebook, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	notebookDir := createTestNotebook(t, tmpDir, "notes")
+	file := filepath.Join(tmpDir, "Travel.enex")
+	require.NoError(t, os.WriteFile(file, []byte(enexFixture()), 0644))
+
+	nb, err := NewNotebookService(createTestConfigService(t, tmpDir, nil)).Open(notebookDir)
+	require.NoError(t, err)
+	return nb, file
+}
+
+func TestNotebook_ENEXImport(t *testing.T) {
+	ctx := context.Background()
+	nb, file := openENEXNotebook(t)
+	require.NoError(t, nb.Storage.Write("evernote/travel/trip-plan.md", []byte("# Not imported\n")))
+	require.NoError(t, nb.Notes.IndexFile(ctx, "evernote/travel/trip-plan.md"))
+
+	plan, err := nb.PlanENEXImport(ctx, file, ENEXImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Travel", plan.Notebook)
+	require.Len(t, plan.Notes, 3)
+
+	trip := plan.Notes[0]
+	assert.Equal(t, ImportNew, trip.Status)
+	assert.Equal(t, "evernote/travel/trip-plan-2.md", trip.Path, "an existing note keeps its path")
+	assert.Equal(t, []string{"evernote/travel/trip-plan-2.resources/map.png"}, trip.Attachments)
+	assert.Equal(t, "---\ncreated: \"2024-01-01T10:00:00Z\"\nmodified: \"2024-01-02T08:00:00Z\"\nsource_id: "+trip.SourceID+
+		"\nsource_url: https://example.com\ntags:\n  - travel\n  - \"2024\"\ntitle: Trip Plan\n---\n\n"+
+		"# Trip Plan\n\nPack\n![map.png](trip-plan-2.resources/map.png)\n", string(trip.Content))
+
+	second := plan.Notes[1]
+	assert.Equal(t, "evernote/travel/trip-plan-3.md", second.Path)
+	assert.NotEqual(t, trip.SourceID, second.SourceID)
+	assert.Len(t, second.Attachments, 1)
+	assert.True(t, strings.HasSuffix(second.Attachments[0], ".pdf"), second.Attachments[0])
+	assert.Contains(t, string(second.Content), "## Attachments\n\n- [")
+
+	assert.Equal(t, "evernote/travel/"+strings.TrimPrefix(plan.Notes[2].SourceID, "evernote:")+".md", plan.Notes[2].Path)
+
+	require.NoError(t, nb.ApplyENEXImport(ctx, plan))
+	image, err := nb.Storage.Read("evernote/travel/trip-plan-2.resources/map.png")
+	require.NoError(t, err)
+	assert.Equal(t, "png bytes", string(image))
+	found, err := nb.Notes.ResolveNote(ctx, "evernote/travel/trip-plan-2.md")
+	require.NoError(t, err)
+	require.NotEmpty(t, found)
+	assert.Equal(t, []string{"travel", "2024"}, metadataStrings(found[0].Metadata, "tags"))
+
+	// Importing again changes nothing, even with another path pattern
+	plan, err = nb.PlanENEXImport(ctx, file, ENEXImportOptions{PathPattern: "other/{{ .Slug }}"})
+	require.NoError(t, err)
+	for _, note := range plan.Notes {
+		assert.Equal(t, ImportUnchanged, note.Status, note.Path)
+	}
+
+	// Edited notes are skipped unless updating
+	require.NoError(t, nb.Storage.Write(trip.Path, []byte("# Edited\n")))
+	plan, err = nb.PlanENEXImport(ctx, file, ENEXImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ImportSkipped, plan.Notes[0].Status)
+	plan, err = nb.PlanENEXImport(ctx, file, ENEXImportOptions{Update: true})
+	require.NoError(t, err)
+	assert.Equal(t, ImportUpdated, plan.Notes[0].Status)
+	require.NoError(t, nb.ApplyENEXImport(ctx, plan))
+	content, err := nb.Storage.Read(trip.Path)
+	require.NoError(t, err)
+	assert.Equal(t, string(trip.Content), string(content))
+}
+
+func TestNotebook_ENEXImportPathPattern(t *testing.T) {
+	nb, file := openENEXNotebook(t)
+	nb.Config.Import = &ImportConfig{ENEXPath: "{{ .Year }}/{{ .Month }}/{{ slug .Title }}"}
+
+	plan, err := nb.PlanENEXImport(context.Background(), file, ENEXImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2024/01/trip-plan.md", plan.Notes[0].Path)
+	assert.Equal(t, "2024/03/trip-plan.md", plan.Notes[1].Path)
+
+	_, err = nb.PlanENEXImport(context.Background(), file, ENEXImportOptions{PathPattern: "../{{ .Slug }}"})
+	assert.ErrorContains(t, err, "outside the notebook")
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// enmlNode is an element or, when Name is empty, a text node of an ENML
+// document.
+type enmlNode struct {
+	Name     string
+	Attr     map[string]string
+	Text     string
+	Children []*enmlNode
+}
+
+// parseENML parses the XHTML of an Evernote note. HTML entities such as
+// &nbsp; are accepted without the ENML DTD and unclosed void elements are
+// closed.
+func parseENML(content string) (*enmlNode, error) {
+	decoder := xml.NewDecoder(strings.NewReader(content))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	root := &enmlNode{Name: "#document"}
+	stack := []*enmlNode{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &enmlNode{Name: strings.ToLower(t.Name.Local), Attr: make(map[string]string)}
+			for _, attr := range t.Attr {
+				node.Attr[strings.ToLower(attr.Name.Local)] = attr.Value
+			}
+			parent.Children = append(parent.Children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			parent.Children = append(parent.Children, &enmlNode{Text: string(t)})
+		}
+	}
+	return root, nil
+}
+
+// enmlMedia is the markdown link for an <en-media> element, by resource hash.
+type enmlMedia func(hash string) (string, bool)
+
+// enmlConverter writes ENML as markdown.
+type enmlConverter struct {
+	media enmlMedia
+	// missing lists en-media hashes without a resource
+	missing []string
+	// encrypted counts <en-crypt> sections, which can't be converted
+	encrypted int
+
+	pre  int
+	list int
+	// item is the builder of the list item being written, so a to-do at
+	// its start doesn't get a second list marker
+	item *strings.Builder
+}
+
+var (
+	whitespacePattern = regexp.MustCompile(`\s+`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// enmlToMarkdown converts an ENML document to markdown.
+func enmlToMarkdown(content string, media enmlMedia) (string, *enmlConverter, error) {
+	root, err := parseENML(content)
+	if err != nil {
+		return "", nil, err
+	}
+	c := &enmlConverter{media: media}
+	var b strings.Builder
+	c.children(root, &b)
+	return cleanMarkdown(b.String()), c, nil
+}
+
+// cleanMarkdown trims trailing spaces and collapses runs of blank lines.
+func cleanMarkdown(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	text = strings.Trim(text, "\n")
+	if text == "" {
+		return ""
+	}
+	return text + "\n"
+}
+
+func (c *enmlConverter) children(n *enmlNode, b *strings.Builder) {
+	for _, child := range n.Children {
+		c.node(child, b)
+	}
+}
+
+// inline renders the children of n on their own, for wrapping in markup.
+func (c *enmlConverter) inline(n *enmlNode) string {
+	var b strings.Builder
+	c.children(n, &b)
+	return b.String()
+}
+
+func (c *enmlConverter) node(n *enmlNode, b *strings.Builder) {
+	if n.Name == "" {
+		c.text(n.Text, b)
+		return
+	}
+
+	switch n.Name {
+	case "style", "script", "title", "head":
+	case "br":
+		b.WriteString("\n")
+	case "div":
+		if strings.Contains(n.Attr["style"], "-en-codeblock") {
+			c.codeBlock(n, b)
+			return
+		}
+		ensureLine(b)
+		c.children(n, b)
+		ensureLine(b)
+	case "p":
+		ensureBlank(b)
+		c.children(n, b)
+		ensureBlank(b)
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(n.Name[1:])
+		ensureBlank(b)
+		b.WriteString(strings.Repeat("#", level) + " " + oneLine(c.inline(n)))
+		ensureBlank(b)
+	case "hr":
+		ensureBlank(b)
+		b.WriteString("---")
+		ensureBlank(b)
+	case "pre":
+		c.codeBlock(n, b)
+	case "blockquote":
+		ensureBlank(b)
+		body := cleanMarkdown(c.inline(n))
+		for _, line := range strings.Split(strings.TrimSuffix(body, "\n"), "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		ensureBlank(b)
+	case "ul", "ol":
+		c.listBlock(n, b)
+	case "li":
+		// A list item outside a list
+		c.listItem(n, "- ", b)
+	case "table":
+		c.table(n, b)
+	case "b", "strong":
+		c.wrap(n, "**", b)
+	case "i", "em":
+		c.wrap(n, "*", b)
+	case "s", "strike", "del":
+		c.wrap(n, "~~", b)
+	case "code", "tt":
+		if c.pre > 0 {
+			c.children(n, b)
+		} else {
+			c.wrap(n, "`", b)
+		}
+	case "a":
+		c.link(n, b)
+	case "img":
+		if src := n.Attr["src"]; src != "" {
+			fmt.Fprintf(b, "![%s](%s)", n.Attr["alt"], src)
+		}
+	case "en-media":
+		hash := strings.ToLower(n.Attr["hash"])
+		if link, ok := c.media(hash); ok {
+			b.WriteString(link)
+		} else {
+			c.missing = append(c.missing, hash)
+		}
+	case "en-todo":
+		if atLineStart(b) && (b != c.item || b.Len() > 0) {
+			b.WriteString("- ")
+		}
+		if n.Attr["checked"] == "true" {
+			b.WriteString("[x] ")
+		} else {
+			b.WriteString("[ ] ")
+		}
+	case "en-crypt":
+		c.encrypted++
+		ensureBlank(b)
+		b.WriteString("> Encrypted Evernote content was not imported.")
+		ensureBlank(b)
+	default:
+		c.children(n, b)
+	}
+}
+
+// text writes a text node. Outside preformatted blocks whitespace collapses
+// as in HTML and is dropped at the start of a line. Evernote spaces text
+// with &nbsp;, which becomes a plain space.
+func (c *enmlConverter) text(text string, b *strings.Builder) {
+	text = strings.ReplaceAll(text, "\u00a0", " ")
+	if c.pre > 0 {
+		b.WriteString(text)
+		return
+	}
+	text = whitespacePattern.ReplaceAllString(text, " ")
+	if atLineStart(b) {
+		text = strings.TrimLeft(text, " ")
+	}
+	b.WriteString(text)
+}
+
+// wrap surrounds inline content with markup such as "**", keeping spaces
+// outside the markers so the markdown stays valid.
+func (c *enmlConverter) wrap(n *enmlNode, marker string, b *strings.Builder) {
+	content := c.inline(n)
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		b.WriteString(content)
+		return
+	}
+	if strings.HasPrefix(content, " ") && !atLineStart(b) {
+		b.WriteString(" ")
+	}
+	b.WriteString(marker + trimmed + marker)
+	if strings.HasSuffix(content, " ") {
+		b.WriteString(" ")
+	}
+}
+
+func (c *enmlConverter) link(n *enmlNode, b *strings.Builder) {
+	href := n.Attr["href"]
+	text := oneLine(c.inline(n))
+	switch {
+	case href == "":
+		b.WriteString(text)
+	case text == "" || text == href:
+		b.WriteString("<" + href + ">")
+	default:
+		fmt.Fprintf(b, "[%s](%s)", text, strings.ReplaceAll(href, " ", "%20"))
+	}
+}
+
+// codeBlock writes a <pre> or Evernote code block as a fenced block.
+func (c *enmlConverter) codeBlock(n *enmlNode, b *strings.Builder) {
+	c.pre++
+	var code strings.Builder
+	c.children(n, &code)
+	c.pre--
+
+	ensureBlank(b)
+	b.WriteString("```\n" + strings.Trim(code.String(), "\n") + "\n```")
+	ensureBlank(b)
+}
+
+func (c *enmlConverter) listBlock(n *enmlNode, b *strings.Builder) {
+	if c.list > 0 {
+		ensureLine(b)
+	} else {
+		ensureBlank(b)
+	}
+	c.list++
+	number := 1
+	for _, child := range n.Children {
+		if child.Name != "li" {
+			// Stray text between items is whitespace
+			if child.Name != "" {
+				c.node(child, b)
+			}
+			continue
+		}
+		marker := "- "
+		if n.Name == "ol" {
+			marker = fmt.Sprintf("%d. ", number)
+			number++
+		}
+		c.listItem(child, marker, b)
+	}
+	c.list--
+	if c.list > 0 {
+		ensureLine(b)
+	} else {
+		ensureBlank(b)
+	}
+}
+
+// listItem writes an item with its continuation lines indented under the
+// marker.
+func (c *enmlConverter) listItem(n *enmlNode, marker string, b *strings.Builder) {
+	item := &strings.Builder{}
+	outer := c.item
+	c.item = item
+	c.children(n, item)
+	c.item = outer
+
+	ensureLine(b)
+	body := strings.Trim(cleanMarkdown(item.String()), "\n")
+	indent := strings.Repeat(" ", len(marker))
+	for i, line := range strings.Split(body, "\n") {
+		switch {
+		case i == 0:
+			b.WriteString(marker + line)
+		case line == "":
+		default:
+			b.WriteString(indent + line)
+		}
+		b.WriteString("\n")
+	}
+}
+
+// table writes a table as a markdown table with the first row as header.
+func (c *enmlConverter) table(n *enmlNode, b *strings.Builder) {
+	var rows [][]string
+	var collect func(*enmlNode)
+	collect = func(n *enmlNode) {
+		for _, child := range n.Children {
+			switch child.Name {
+			case "tr":
+				var cells []string
+				for _, cell := range child.Children {
+					if cell.Name == "td" || cell.Name == "th" {
+						cells = append(cells, strings.ReplaceAll(oneLine(c.inline(cell)), "|", `\|`))
+					}
+				}
+				rows = append(rows, cells)
+			case "thead", "tbody", "tfoot":
+				collect(child)
+			}
+		}
+	}
+	collect(n)
+	if len(rows) == 0 {
+		return
+	}
+
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	ensureBlank(b)
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString(strings.Repeat("| --- ", columns) + "|\n")
+		}
+	}
+	ensureBlank(b)
+}
+
+// oneLine joins rendered inline content onto a single line.
+func oneLine(text string) string {
+	return strings.TrimSpace(whitespacePattern.ReplaceAllString(text, " "))
+}
+
+func atLineStart(b *strings.Builder) bool {
+	return b.Len() == 0 || strings.HasSuffix(b.String(), "\n")
+}
+
+// ensureLine ends the current line unless it is empty.
+func ensureLine(b *strings.Builder) {
+	if !atLineStart(b) {
+		b.WriteString("\n")
+	}
+}
+
+// ensureBlank ends the current paragraph with a blank line.
+func ensureBlank(b *strings.Builder) {
+	if b.Len() == 0 {
+		return
+	}
+	s := b.String()
+	switch {
+	case strings.HasSuffix(s, "\n\n"):
+	case strings.HasSuffix(s, "\n"):
+		b.WriteString("\n")
+	default:
+		b.WriteString("\n\n")
+	}
+}
@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// Field types accepted in the "type" of a field schema.
+const (
+	FieldTypeString   = "string"
+	FieldTypeNumber   = "number"
+	FieldTypeInteger  = "integer"
+	FieldTypeBoolean  = "boolean"
+	FieldTypeDate     = "date"
+	FieldTypeDatetime = "datetime"
+	FieldTypeList     = "list"
+)
+
+// fieldTypes lists the supported field types in the order they are documented.
+var fieldTypes = []string{
+	FieldTypeString, FieldTypeNumber, FieldTypeInteger, FieldTypeBoolean,
+	FieldTypeDate, FieldTypeDatetime, FieldTypeList,
+}
+
+// FieldSchema constrains one frontmatter field. It is declared in the
+// "schema" of .jot.json or of a notebook group.
+type FieldSchema struct {
+	// Type is one of string, number, integer, boolean, date, datetime or list.
+	Type string `json:"type,omitempty"`
+
+	// Enum lists the allowed values. For lists it applies to every item.
+	Enum []any `json:"enum,omitempty"`
+
+	// Required fields must be present and not empty.
+	Required bool `json:"required,omitempty"`
+
+	// Pattern is a regular expression string values must match. For lists
+	// it applies to every item.
+	Pattern string `json:"pattern,omitempty"`
+}
+
+// merge returns s with the constraints set in other layered on top. A field
+// required anywhere stays required.
+func (s FieldSchema) merge(other FieldSchema) FieldSchema {
+	if other.Type != "" {
+		s.Type = other.Type
+	}
+	if other.Enum != nil {
+		s.Enum = other.Enum
+	}
+	if other.Pattern != "" {
+		s.Pattern = other.Pattern
+	}
+	s.Required = s.Required || other.Required
+	return s
+}
+
+// FieldSchemas returns the field schema that applies to a note at relPath:
+// the notebook "schema" with the schema of every matching group layered on
+// top in order, like group metadata.
+func (c *StoredNotebookConfig) FieldSchemas(relPath string) map[string]FieldSchema {
+	schemas := make(map[string]FieldSchema, len(c.Schema))
+	layer := func(fields map[string]*FieldSchema) {
+		for name, field := range fields {
+			if field != nil {
+				schemas[name] = schemas[name].merge(*field)
+			}
+		}
+	}
+
+	layer(c.Schema)
+	for _, group := range c.MatchGroups(relPath) {
+		layer(group.Schema)
+	}
+	return schemas
+}
+
+// ValidateSchema checks the field schemas declared in the notebook config.
+func (c *StoredNotebookConfig) ValidateSchema() core.ValidationErrors {
+	v := core.NewValidator()
+	validateFieldSchemas(v.WithPath("schema"), c.Schema)
+	for _, group := range c.Groups {
+		validateFieldSchemas(v.WithPath(fmt.Sprintf("groups[%s].schema", group.Name)), group.Schema)
+	}
+	return v.Errors()
+}
+
+func validateFieldSchemas(v *core.Validator, fields map[string]*FieldSchema) {
+	for _, name := range sortedKeys(fields) {
+		field := fields[name]
+		if field == nil {
+			continue
+		}
+		fv := v.WithPath(name)
+		if field.Type != "" && !validFieldType(field.Type) {
+			fv.AddError(fmt.Sprintf("unknown type %q (expected one of %s)", field.Type, strings.Join(fieldTypes, ", ")))
+		}
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				fv.AddError(fmt.Sprintf("invalid pattern: %v", err))
+			}
+		}
+	}
+}
+
+func validFieldType(t string) bool {
+	for _, known := range fieldTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFrontmatter checks metadata against the field schema of relPath.
+// Each error's path is the field name.
+func (c *StoredNotebookConfig) ValidateFrontmatter(relPath string, metadata map[string]any) core.ValidationErrors {
+	schemas := c.FieldSchemas(relPath)
+	v := core.NewValidator()
+	for _, name := range sortedKeys(schemas) {
+		validateField(v.WithPath(name), schemas[name], metadata[name])
+	}
+	return v.Errors()
+}
+
+// CoerceFields converts string values of fields the schema of relPath types
+// as number, integer or boolean, so "--data priority=2" stores a number.
+func (c *StoredNotebookConfig) CoerceFields(relPath string, data map[string]any) {
+	schemas := c.FieldSchemas(relPath)
+	for name, value := range data {
+		switch schemas[name].Type {
+		case FieldTypeNumber, FieldTypeInteger, FieldTypeBoolean:
+			if s, ok := value.(string); ok {
+				data[name] = ParseFieldValue(s)
+			}
+		}
+	}
+}
+
+// NewViolations returns the errors in after that are not in before, so an
+// edit is only blamed for the problems it introduces.
+func NewViolations(before, after core.ValidationErrors) core.ValidationErrors {
+	existing := make(map[string]bool, len(before))
+	for _, err := range before {
+		existing[err.Error()] = true
+	}
+
+	var result core.ValidationErrors
+	for _, err := range after {
+		if !existing[err.Error()] {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// ValidateContent checks the frontmatter of note content against the field
+// schema of relPath.
+func (c *StoredNotebookConfig) ValidateContent(relPath string, content []byte) core.ValidationErrors {
+	metadata, _ := parseFrontmatter(content)
+	return c.ValidateFrontmatter(relPath, metadata)
+}
+
+func validateField(v *core.Validator, schema FieldSchema, value any) {
+	if isEmptyFieldValue(value) {
+		if schema.Required {
+			v.AddError("is required")
+		}
+		return
+	}
+
+	if schema.Type != "" && !matchesFieldType(schema.Type, value) {
+		v.AddError(fmt.Sprintf("must be %s, got %s", describeFieldType(schema.Type), describeFieldValue(value)))
+		return
+	}
+
+	items := []any{value}
+	if list, ok := value.([]any); ok {
+		items = list
+	}
+
+	var pattern *regexp.Regexp
+	if schema.Pattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(schema.Pattern); err != nil {
+			v.AddError(fmt.Sprintf("has an invalid pattern in .jot.json: %v", err))
+			return
+		}
+	}
+
+	for _, item := range items {
+		if len(schema.Enum) > 0 && !inEnum(schema.Enum, item) {
+			v.AddError(fmt.Sprintf("must be one of %s, got %s", describeEnum(schema.Enum), describeFieldValue(item)))
+		}
+		if pattern != nil {
+			if s, ok := item.(string); !ok || !pattern.MatchString(s) {
+				v.AddError(fmt.Sprintf("must match %s, got %s", schema.Pattern, describeFieldValue(item)))
+			}
+		}
+	}
+}
+
+func isEmptyFieldValue(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+func matchesFieldType(fieldType string, value any) bool {
+	switch fieldType {
+	case FieldTypeString:
+		_, ok := value.(string)
+		return ok
+	case FieldTypeNumber:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+	case FieldTypeInteger:
+		switch v := value.(type) {
+		case int, int64, uint64:
+			return true
+		case float64:
+			return v == float64(int64(v))
+		}
+	case FieldTypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case FieldTypeDate:
+		return isDateValue(value, "2006-01-02", time.RFC3339)
+	case FieldTypeDatetime:
+		return isDateValue(value, time.RFC3339)
+	case FieldTypeList:
+		_, ok := value.([]any)
+		return ok
+	}
+	return false
+}
+
+// isDateValue reports whether value is a time or a string in one of layouts.
+func isDateValue(value any, layouts ...string) bool {
+	switch v := value.(type) {
+	case time.Time:
+		return true
+	case string:
+		for _, layout := range layouts {
+			if _, err := time.Parse(layout, v); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inEnum(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeFieldType(fieldType string) string {
+	switch fieldType {
+	case FieldTypeDate:
+		return "a date (YYYY-MM-DD)"
+	case FieldTypeDatetime:
+		return "a datetime (RFC 3339)"
+	case FieldTypeInteger:
+		return "an integer"
+	default:
+		return "a " + fieldType
+	}
+}
+
+func describeFieldValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a mapping"
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return fmt.Sprint(value)
+}
+
+func describeEnum(enum []any) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprint(v)
+	}
+	return strings.Join(values, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ValidateNotes checks the frontmatter of every note against the notebook's
+// field schema. Each error's path is the note path and its message names the
+// field. It also returns the number of notes checked. Encrypted notes that
+// cannot be read are skipped.
+func (n *Notebook) ValidateNotes(ctx context.Context) (core.ValidationErrors, int, error) {
+	if errs := n.Config.ValidateSchema(); len(errs) > 0 {
+		return nil, 0, fmt.Errorf("invalid schema in .jot.json:\n%s", errs.PrettyPrint())
+	}
+
+	notes, err := n.Notes.getAllNotes(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortNotesByPath(notes)
+
+	var result core.ValidationErrors
+	checked := 0
+	for _, note := range notes {
+		relPath := note.File.Relative
+		content, err := n.Storage.Read(relPath)
+		if errors.Is(err, search.ErrEncrypted) {
+			continue
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		checked++
+
+		for _, violation := range n.Config.ValidateContent(relPath, content) {
+			result = append(result, core.ValidationError{Path: relPath, Message: violation.Error()})
+		}
+	}
+	return result, checked, nil
+}
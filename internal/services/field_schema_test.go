@@ -0,0 +1,137 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSchemaConfig() StoredNotebookConfig {
+	return StoredNotebookConfig{
+		Schema: map[string]*FieldSchema{
+			"status":   {Type: FieldTypeString, Enum: []any{"todo", "doing", "done"}},
+			"due":      {Type: FieldTypeDate},
+			"priority": {Type: FieldTypeInteger},
+			"tags":     {Type: FieldTypeList, Pattern: `^[a-z0-9-]+$`},
+		},
+		Groups: []NotebookGroup{
+			{Name: "Default", Globs: []string{"**/*.md"}, Metadata: map[string]any{}},
+			{Name: "Projects", Globs: []string{"projects/**"}, Schema: map[string]*FieldSchema{
+				"owner":  {Type: FieldTypeString, Required: true},
+				"status": {Required: true},
+			}},
+		},
+	}
+}
+
+func TestFieldSchemas_GroupsLayerOnTop(t *testing.T) {
+	cfg := testSchemaConfig()
+
+	schemas := cfg.FieldSchemas("projects/apollo.md")
+	assert.True(t, schemas["owner"].Required)
+	assert.Equal(t, FieldSchema{Type: FieldTypeString, Enum: []any{"todo", "doing", "done"}, Required: true}, schemas["status"])
+
+	schemas = cfg.FieldSchemas("ideas.md")
+	assert.NotContains(t, schemas, "owner")
+	assert.False(t, schemas["status"].Required)
+}
+
+func TestValidateFrontmatter(t *testing.T) {
+	cfg := testSchemaConfig()
+
+	tests := []struct {
+		name     string
+		path     string
+		metadata map[string]any
+		want     []string
+	}{
+		{
+			name:     "valid",
+			path:     "ideas.md",
+			metadata: map[string]any{"status": "done", "due": "2024-05-01", "priority": 2, "tags": []any{"work", "q3"}},
+		},
+		{
+			name:     "optional fields may be missing",
+			path:     "ideas.md",
+			metadata: map[string]any{},
+		},
+		{
+			name:     "parsed dates are dates",
+			path:     "ideas.md",
+			metadata: map[string]any{"due": time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:     "enum",
+			path:     "ideas.md",
+			metadata: map[string]any{"status": "wip"},
+			want:     []string{`status: must be one of todo, doing, done, got "wip"`},
+		},
+		{
+			name:     "types",
+			path:     "ideas.md",
+			metadata: map[string]any{"due": "tomorrow", "priority": 1.5, "tags": "work"},
+			want: []string{
+				`due: must be a date (YYYY-MM-DD), got "tomorrow"`,
+				`priority: must be an integer, got 1.5`,
+				`tags: must be a list, got "work"`,
+			},
+		},
+		{
+			name:     "pattern applies to list items",
+			path:     "ideas.md",
+			metadata: map[string]any{"tags": []any{"ok", "Not OK"}},
+			want:     []string{`tags: must match ^[a-z0-9-]+$, got "Not OK"`},
+		},
+		{
+			name:     "required by group",
+			path:     "projects/apollo.md",
+			metadata: map[string]any{"owner": "", "status": "todo"},
+			want:     []string{"owner: is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, err := range cfg.ValidateFrontmatter(tt.path, tt.metadata) {
+				got = append(got, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateSchema(t *testing.T) {
+	cfg := StoredNotebookConfig{
+		Schema: map[string]*FieldSchema{"due": {Type: "timestamp"}},
+		Groups: []NotebookGroup{
+			{Name: "Projects", Schema: map[string]*FieldSchema{"owner": {Pattern: "("}}},
+		},
+	}
+
+	errs := cfg.ValidateSchema()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error(), `schema.due: unknown type "timestamp"`)
+	assert.Contains(t, errs[1].Error(), "groups[Projects].schema.owner: invalid pattern")
+}
+
+func TestCoerceFields(t *testing.T) {
+	cfg := testSchemaConfig()
+
+	data := map[string]any{"priority": "2", "status": "2"}
+	cfg.CoerceFields("ideas.md", data)
+	assert.Equal(t, map[string]any{"priority": 2, "status": "2"}, data)
+}
+
+func TestNewViolations(t *testing.T) {
+	cfg := testSchemaConfig()
+	before := cfg.ValidateFrontmatter("ideas.md", map[string]any{"status": "wip"})
+	after := cfg.ValidateFrontmatter("ideas.md", map[string]any{"status": "wip", "due": "soon"})
+
+	violations := NewViolations(before, after)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "due", violations[0].Path)
+	assert.Empty(t, NewViolations(before, before))
+}
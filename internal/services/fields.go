@@ -0,0 +1,176 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFieldValue converts a value from the command line into the YAML type
+// it reads as, so "priority=2" stores a number and "done=true" a boolean.
+// Flow lists ("[a, b]") become lists. Anything else stays a string.
+func ParseFieldValue(raw string) any {
+	if raw == "" {
+		return raw
+	}
+
+	var value any
+	if err := yaml.Unmarshal([]byte(raw), &value); err != nil {
+		return raw
+	}
+
+	switch v := value.(type) {
+	case int, float64, bool, string:
+		return v
+	case []any:
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			return v
+		}
+	}
+	return raw
+}
+
+// ParseFieldValues applies ParseFieldValue to every value parsed by
+// ParseDataFlags, including the elements of repeated fields.
+func ParseFieldValues(data map[string]any) map[string]any {
+	result := make(map[string]any, len(data))
+	for key, value := range data {
+		switch v := value.(type) {
+		case string:
+			result[key] = ParseFieldValue(v)
+		case []any:
+			typed := make([]any, len(v))
+			for i, item := range v {
+				if s, ok := item.(string); ok {
+					typed[i] = ParseFieldValue(s)
+				} else {
+					typed[i] = item
+				}
+			}
+			result[key] = typed
+		default:
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// PlanFieldUpdate computes setting and removing frontmatter fields of a
+// note, stamping "modified", without writing. It returns nil when the note
+// would not change; ApplyChange writes the change.
+func (s *NoteService) PlanFieldUpdate(relPath string, set map[string]any, unset []string) (*NoteChange, error) {
+	return s.planRewrite(relPath, func(content []byte) ([]byte, error) {
+		return UpdateFrontmatter(content, set, unset)
+	})
+}
+
+// PlanTagUpdate computes adding and removing tags of a note without
+// writing, and returns the resulting tag list. Existing tags keep their
+// order and tags are compared case-insensitively. A singular "tag" field
+// counts as tags and is folded into the "tags" list when they change. The
+// change is nil when the tags would not change.
+func (s *NoteService) PlanTagUpdate(relPath string, add, remove []string) (*NoteChange, []string, error) {
+	var tags []string
+	change, err := s.planRewrite(relPath, func(content []byte) ([]byte, error) {
+		metadata, _ := parseFrontmatter(content)
+		current := metadataTags(metadata)
+		tags = mergeTags(current, add, remove)
+
+		if equalStrings(current, tags) {
+			return content, nil
+		}
+		if len(tags) == 0 {
+			return UpdateFrontmatter(content, nil, []string{"tags", "tag"})
+		}
+		return UpdateFrontmatter(content, map[string]any{"tags": tags}, []string{"tag"})
+	})
+	return change, tags, err
+}
+
+// ApplyChange writes a planned change and re-indexes the note.
+func (s *NoteService) ApplyChange(ctx context.Context, change *NoteChange) error {
+	if err := s.storage.Write(change.Path, []byte(change.After)); err != nil {
+		return fmt.Errorf("failed to save note: %w", err)
+	}
+
+	if s.index != nil {
+		if err := s.IndexFile(ctx, change.Path); err != nil {
+			s.log.Warn().Err(err).Str("path", change.Path).Msg("failed to re-index note")
+		}
+	}
+
+	return nil
+}
+
+// planRewrite applies edit to a note's content. When the content changes,
+// "modified" is stamped and the change is returned; otherwise nil.
+func (s *NoteService) planRewrite(relPath string, edit func([]byte) ([]byte, error)) (*NoteChange, error) {
+	content, err := s.storage.Read(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	edited, err := edit(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", relPath, err)
+	}
+	if bytes.Equal(content, edited) {
+		return nil, nil
+	}
+
+	stamped, err := UpdateFrontmatter(edited, map[string]any{
+		"modified": time.Now().Format(time.RFC3339),
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", relPath, err)
+	}
+
+	return &NoteChange{
+		Path:    relPath,
+		OldPath: relPath,
+		Before:  string(content),
+		After:   string(stamped),
+	}, nil
+}
+
+// metadataTags returns the tags of a note from its "tags" and "tag"
+// fields, without duplicates.
+func metadataTags(metadata map[string]any) []string {
+	return mergeTags(metadataStrings(metadata, "tags"), metadataStrings(metadata, "tag"), nil)
+}
+
+// mergeTags appends tags from add that are missing and drops tags in remove.
+func mergeTags(current, add, remove []string) []string {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		removed[strings.ToLower(tag)] = true
+	}
+
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, tag := range append(append([]string{}, current...), add...) {
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] || removed[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, tag)
+	}
+	return result
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
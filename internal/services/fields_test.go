@@ -0,0 +1,136 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+func TestParseFieldValue(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected any
+	}{
+		{"done", "done"},
+		{"2", 2},
+		{"1.5", 1.5},
+		{"true", true},
+		{"[a, b]", []any{"a", "b"}},
+		{"", ""},
+		{"a: b", "a: b"},
+		{"- item", "- item"},
+		{"2024-01-15", "2024-01-15"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			assert.Equal(t, tt.expected, services.ParseFieldValue(tt.raw))
+		})
+	}
+}
+
+func TestParseFieldValues_RepeatedFields(t *testing.T) {
+	data, err := services.ParseDataFlags([]string{"priority=2", "owner=alice", "owner=bob"})
+	require.NoError(t, err)
+
+	typed := services.ParseFieldValues(data)
+	assert.Equal(t, 2, typed["priority"])
+	assert.Equal(t, []any{"alice", "bob"}, typed["owner"])
+}
+
+func TestNoteService_PlanFieldUpdate(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, map[string]string{"task.md": "---\n# keep me\ntitle: Task\nowner: alice # lead\nstatus: todo\n---\n\nBody.\n"})
+	notePath := filepath.Join(notebookDir, "notes", "task.md")
+
+	change, err := svc.PlanFieldUpdate("notes/task.md", map[string]any{"status": "done", "priority": 2}, []string{"owner"})
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	require.NoError(t, svc.ApplyChange(ctx, change))
+
+	content, err := os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "---\n# keep me\ntitle: Task\nstatus: done\npriority: 2\nmodified: "), string(content))
+	assert.NotContains(t, string(content), "owner")
+	assert.True(t, strings.HasSuffix(string(content), "---\n\nBody.\n"))
+
+	notes, err := svc.SearchWithConditions(ctx, []services.QueryCondition{{Type: "and", Field: "data.status", Operator: "=", Value: "done"}})
+	require.NoError(t, err)
+	assert.Len(t, notes, 1, "note should be re-indexed")
+}
+
+func TestNoteService_PlanFieldUpdate_NoChange(t *testing.T) {
+	original := "---\ntitle: Task\n---\n\nBody.\n"
+	svc, notebookDir := testutil.NewNoteService(t, map[string]string{"task.md": original})
+	notePath := filepath.Join(notebookDir, "notes", "task.md")
+
+	change, err := svc.PlanFieldUpdate("notes/task.md", nil, []string{"missing"})
+	require.NoError(t, err)
+	assert.Nil(t, change)
+
+	content, err := os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content), "unchanged notes are not stamped")
+}
+
+func TestNoteService_PlanTagUpdate(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, map[string]string{"task.md": "---\ntitle: Task\ntags: [work, Urgent] # triage\n---\n\nBody.\n"})
+	notePath := filepath.Join(notebookDir, "notes", "task.md")
+
+	change, tags, err := svc.PlanTagUpdate("notes/task.md", []string{"review", "work"}, []string{"urgent"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"work", "review"}, tags)
+	require.NoError(t, svc.ApplyChange(ctx, change))
+
+	content, err := os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "tags: [work, review] # triage\n")
+
+	change, tags, err = svc.PlanTagUpdate("notes/task.md", nil, []string{"work", "review"})
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+	require.NoError(t, svc.ApplyChange(ctx, change))
+
+	content, err = os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "tags")
+}
+
+func TestNoteService_PlanTagUpdate_SingularTag(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, map[string]string{"old.md": "---\ntitle: Old\ntag: legacy\n---\n\nBody.\n"})
+	notePath := filepath.Join(notebookDir, "notes", "old.md")
+
+	change, tags, err := svc.PlanTagUpdate("notes/old.md", []string{"urgent"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy", "urgent"}, tags)
+	require.NoError(t, svc.ApplyChange(ctx, change))
+
+	content, err := os.ReadFile(notePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "tag: legacy", "the singular field is folded into tags")
+	results, err := svc.GetIndex().Find(ctx, search.FindOpts{Tags: []string{"urgent"}})
+	require.NoError(t, err)
+	assert.Len(t, results.Items, 1, "the added tag is indexed")
+
+	change, tags, err = svc.PlanTagUpdate("notes/old.md", nil, []string{"legacy"})
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Equal(t, []string{"urgent"}, tags)
+
+	svc, _ = testutil.NewNoteService(t, map[string]string{"old.md": "---\ntag: legacy\n---\n"})
+	change, tags, err = svc.PlanTagUpdate("notes/old.md", nil, []string{"legacy"})
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Empty(t, tags)
+	assert.NotContains(t, change.After, "tag")
+}
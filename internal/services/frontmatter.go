@@ -0,0 +1,122 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// splitFrontmatter separates the YAML frontmatter from the note body using the
+// same delimiters as parseFrontmatter. ok is false when there is no frontmatter.
+func splitFrontmatter(content []byte) (frontmatter, body []byte, ok bool) {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content, false
+	}
+
+	rest := content[4:]
+	endIdx := bytes.Index(rest, []byte("\n---\n"))
+	if endIdx == -1 {
+		return nil, content, false
+	}
+
+	return rest[:endIdx+1], rest[endIdx+5:], true
+}
+
+// UpdateFrontmatter sets and removes frontmatter fields of a note, leaving the
+// body untouched. Existing keys keep their position and comments; new keys
+// are appended in alphabetical order. A note without frontmatter gets one.
+func UpdateFrontmatter(content []byte, set map[string]any, unset []string) ([]byte, error) {
+	frontmatter, body, ok := splitFrontmatter(content)
+
+	var doc yaml.Node
+	if ok && len(bytes.TrimSpace(frontmatter)) > 0 {
+		if err := yaml.Unmarshal(frontmatter, &doc); err != nil {
+			return nil, fmt.Errorf("invalid frontmatter: %w", err)
+		}
+	}
+
+	if doc.Kind == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	mapping := doc.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("invalid frontmatter: expected a mapping of fields")
+	}
+
+	for _, key := range unset {
+		removeMappingKey(mapping, key)
+	}
+
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var value yaml.Node
+		if err := value.Encode(set[key]); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		setMappingKey(mapping, key, &value)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	if len(mapping.Content) > 0 {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode frontmatter: %w", err)
+		}
+	}
+	buf.WriteString("---\n")
+
+	// Keep the blank line jot writes between frontmatter and body
+	if !ok && len(body) > 0 {
+		buf.WriteString("\n")
+	}
+	buf.Write(body)
+
+	return buf.Bytes(), nil
+}
+
+// setMappingKey replaces the value of key, keeping its comments and, for
+// values of the same kind, its style (so "tags: [a, b]" stays a flow list).
+// Missing keys are appended.
+func setMappingKey(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			old := mapping.Content[i+1]
+			if old.Kind == value.Kind && old.Kind != yaml.ScalarNode {
+				value.Style = old.Style
+			}
+			value.HeadComment = old.HeadComment
+			value.LineComment = old.LineComment
+			value.FootComment = old.FootComment
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+
+	mapping.Content = append(mapping.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
+
+// removeMappingKey deletes key and its value from a mapping node.
+func removeMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateFrontmatter_PreservesOrderAndComments(t *testing.T) {
+	content := "---\n# owner of the note\nauthor: alice\ntitle: Plan # working title\ntags:\n  - work\n---\n\n# Plan\n\nBody.\n"
+
+	out, err := UpdateFrontmatter([]byte(content), map[string]any{
+		"title":    "Final Plan",
+		"modified": "2024-01-02T03:04:05Z",
+	}, nil)
+	require.NoError(t, err)
+
+	expected := "---\n# owner of the note\nauthor: alice\ntitle: Final Plan # working title\ntags:\n  - work\nmodified: \"2024-01-02T03:04:05Z\"\n---\n\n# Plan\n\nBody.\n"
+	assert.Equal(t, expected, string(out))
+}
+
+func TestUpdateFrontmatter_Unset(t *testing.T) {
+	content := "---\ntitle: Plan\nstatus: draft\n---\n\nBody.\n"
+
+	out, err := UpdateFrontmatter([]byte(content), nil, []string{"status", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Plan\n---\n\nBody.\n", string(out))
+}
+
+func TestUpdateFrontmatter_AddsFrontmatter(t *testing.T) {
+	out, err := UpdateFrontmatter([]byte("# Plain\n"), map[string]any{"status": "draft"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nstatus: draft\n---\n\n# Plain\n", string(out))
+
+	metadata, body := parseFrontmatter(out)
+	assert.Equal(t, "draft", metadata["status"])
+	assert.Equal(t, "\n# Plain\n", body)
+}
+
+func TestUpdateFrontmatter_NewKeysSorted(t *testing.T) {
+	out, err := UpdateFrontmatter([]byte("---\ntitle: A\n---\n"), map[string]any{
+		"zeta":  1,
+		"alpha": []string{"a", "b"},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: A\nalpha:\n  - a\n  - b\nzeta: 1\n---\n", string(out))
+}
+
+func TestUpdateFrontmatter_InvalidYAML(t *testing.T) {
+	_, err := UpdateFrontmatter([]byte("---\ntitle: [unclosed\n---\n"), map[string]any{"a": 1}, nil)
+	assert.Error(t, err)
+}
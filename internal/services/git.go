@@ -0,0 +1,429 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// ErrNotGitRepo is returned by OpenGit when the notes root is not inside a
+// git work tree, or git is not installed.
+var ErrNotGitRepo = errors.New("not a git repository")
+
+// GitConfig configures git integration in .jot.json. History and diffs
+// work whenever the notes root is in a git work tree; auto-commit and
+// commit dates are opt-in.
+type GitConfig struct {
+	// AutoCommit commits the notes each mutating command changed.
+	AutoCommit bool `json:"auto_commit,omitempty"`
+	// CommitDates uses the author dates of a note's first and last commits
+	// as the created/modified fallback instead of the file mtime. It reads
+	// the whole history of the notes root each time the notebook opens.
+	CommitDates bool `json:"commit_dates,omitempty"`
+}
+
+// Commit is a git commit touching a note.
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Author  string    `json:"author"`
+	Date    time.Time `json:"date"`
+	Subject string    `json:"subject"`
+	// Path is the note's path at this commit, relative to the notes root.
+	// It differs from the current path when the note was renamed.
+	Path string `json:"path"`
+}
+
+// Short returns the abbreviated commit hash.
+func (c Commit) Short() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// CommitTimes are the author dates of the first and last commits of a file.
+type CommitTimes struct {
+	First time.Time
+	Last  time.Time
+	// Dirty is set when the file has uncommitted changes.
+	Dirty bool
+}
+
+// Git runs the local git binary against the work tree holding a notebook.
+type Git struct {
+	root string // notes root, symlinks resolved
+	top  string // work tree top level
+}
+
+// OpenGit finds the git work tree containing the notes root.
+func OpenGit(ctx context.Context, root string) (*Git, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, ErrNotGitRepo
+	}
+	resolved, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Git{root: resolved, top: resolved}
+	top, err := g.run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, ErrNotGitRepo
+	}
+	g.top = strings.TrimSpace(top)
+	return g, nil
+}
+
+// run executes git in the work tree and returns its output. Paths are
+// printed unquoted so non-ASCII names map back to notes, and git never
+// opens an editor.
+func (g *Git) run(ctx context.Context, args ...string) (string, error) {
+	args = append([]string{"-C", g.top, "-c", "core.quotePath=false"}, args...)
+	c := exec.CommandContext(ctx, "git", args...)
+	c.Env = append(os.Environ(), "GIT_EDITOR=true")
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[4], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[4], err)
+	}
+	return stdout.String(), nil
+}
+
+// repoPath converts a note path to a path relative to the work tree.
+func (g *Git) repoPath(relPath string) string {
+	p, err := filepath.Rel(g.top, filepath.Join(g.root, filepath.FromSlash(relPath)))
+	if err != nil {
+		return relPath
+	}
+	return filepath.ToSlash(p)
+}
+
+// notePath converts a work tree path to a note path. It reports false for
+// files outside the notes root.
+func (g *Git) notePath(repoPath string) (string, bool) {
+	p, err := filepath.Rel(g.root, filepath.Join(g.top, filepath.FromSlash(repoPath)))
+	if err != nil || p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(p), true
+}
+
+// CommitTimes returns the first and last commit times of every file under
+// the notes root, keyed by note path, from a single "git log", and marks
+// the files "git status" reports as changed.
+func (g *Git) CommitTimes(ctx context.Context) (map[string]CommitTimes, error) {
+	out, err := g.run(ctx, "log", "--format=%x1e%aI", "--name-only", "--", g.repoPath("."))
+	if err != nil {
+		return nil, err
+	}
+
+	times := make(map[string]CommitTimes)
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		date, err := time.Parse(time.RFC3339, lines[0])
+		if err != nil {
+			continue
+		}
+		for _, line := range lines[1:] {
+			relPath, ok := g.notePath(strings.TrimSpace(line))
+			if !ok || line == "" {
+				continue
+			}
+			// git log lists commits newest first
+			t, seen := times[relPath]
+			if !seen {
+				t.Last = date
+			}
+			t.First = date
+			times[relPath] = t
+		}
+	}
+
+	status, err := g.run(ctx, "status", "--porcelain", "-z", "--untracked-files=no", "--", g.repoPath("."))
+	if err != nil {
+		return nil, err
+	}
+	entries := strings.Split(status, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		// renames and copies are followed by their source path
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+		if relPath, ok := g.notePath(entry[3:]); ok {
+			if t, seen := times[relPath]; seen {
+				t.Dirty = true
+				times[relPath] = t
+			}
+		}
+	}
+	return times, nil
+}
+
+// History lists the commits touching the note at relPath, newest first,
+// following renames.
+func (g *Git) History(ctx context.Context, relPath string) ([]Commit, error) {
+	out, err := g.run(ctx, "log", "--follow", "--format=%x1e%H%x1f%an%x1f%aI%x1f%s", "--name-only", "--", g.repoPath(relPath))
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []Commit{}
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		fields := strings.Split(lines[0], "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		date, _ := time.Parse(time.RFC3339, fields[2])
+		commit := Commit{Hash: fields[0], Author: fields[1], Date: date, Subject: fields[3], Path: relPath}
+		for _, line := range lines[1:] {
+			if p, ok := g.notePath(strings.TrimSpace(line)); ok && line != "" {
+				commit.Path = p
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// Show returns the content of the note at relPath as of rev.
+func (g *Git) Show(ctx context.Context, rev, relPath string) ([]byte, error) {
+	out, err := g.run(ctx, "show", rev+":"+g.repoPath(relPath))
+	if err != nil {
+		return nil, err
+	}
+	return []byte(out), nil
+}
+
+// ResolveRev returns the full hash of the commit rev names.
+func (g *Git) ResolveRev(ctx context.Context, rev string) (string, error) {
+	out, err := g.run(ctx, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("unknown revision %q", rev)
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// Dirty reports whether the note at relPath has uncommitted changes.
+func (g *Git) Dirty(ctx context.Context, relPath string) (bool, error) {
+	out, err := g.run(ctx, "status", "--porcelain", "--", g.repoPath(relPath))
+	return strings.TrimSpace(out) != "", err
+}
+
+// IsAncestor reports whether commit is rev or one of its ancestors.
+func (g *Git) IsAncestor(ctx context.Context, commit, rev string) bool {
+	_, err := g.run(ctx, "merge-base", "--is-ancestor", commit, rev)
+	return err == nil
+}
+
+// Commit stages the notes at relPaths, including deletions, and commits
+// just those paths. It reports false when none of them changed.
+func (g *Git) Commit(ctx context.Context, message string, relPaths []string) (bool, error) {
+	if len(relPaths) == 0 {
+		return false, nil
+	}
+	all := make([]string, len(relPaths))
+	for i, p := range relPaths {
+		all[i] = g.repoPath(p)
+	}
+
+	// Skip paths git cannot stage: neither on disk nor tracked, like a note
+	// created and then trashed by the same command
+	tracked, err := g.run(ctx, append([]string{"ls-files", "--"}, all...)...)
+	if err != nil {
+		return false, err
+	}
+	known := make(map[string]bool)
+	for _, line := range strings.Split(tracked, "\n") {
+		known[line] = true
+	}
+	var paths []string
+	for i, p := range all {
+		if known[p] || fileExists(filepath.Join(g.root, filepath.FromSlash(relPaths[i]))) {
+			paths = append(paths, p)
+		}
+	}
+	if len(paths) == 0 {
+		return false, nil
+	}
+
+	if _, err := g.run(ctx, append([]string{"add", "-A", "--"}, paths...)...); err != nil {
+		return false, err
+	}
+	// diff --quiet exits 1 when there are staged changes
+	if _, err := g.run(ctx, append([]string{"diff", "--cached", "--quiet", "--"}, paths...)...); err == nil {
+		return false, nil
+	}
+	if _, err := g.run(ctx, append([]string{"commit", "-q", "-m", message, "--"}, paths...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// changeRecorder wraps a storage backend and records the notes written,
+// removed or renamed through it, for auto-commit. Files under .jot (trash,
+// manifests) are left out.
+type changeRecorder struct {
+	search.Storage
+	mu      sync.Mutex
+	changed map[string]bool
+}
+
+func newChangeRecorder(storage search.Storage) *changeRecorder {
+	return &changeRecorder{Storage: storage, changed: make(map[string]bool)}
+}
+
+func (r *changeRecorder) record(paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range paths {
+		p = path.Clean(filepath.ToSlash(p))
+		if p != ".jot" && !strings.HasPrefix(p, ".jot/") {
+			r.changed[p] = true
+		}
+	}
+}
+
+// Write writes the file and records it.
+func (r *changeRecorder) Write(p string, content []byte) error {
+	if err := r.Storage.Write(p, content); err != nil {
+		return err
+	}
+	r.record(p)
+	return nil
+}
+
+// Remove deletes the file and records it.
+func (r *changeRecorder) Remove(p string) error {
+	if err := r.Storage.Remove(p); err != nil {
+		return err
+	}
+	r.record(p)
+	return nil
+}
+
+// Rename moves the file and records both paths.
+func (r *changeRecorder) Rename(oldPath, newPath string) error {
+	if err := r.Storage.Rename(oldPath, newPath); err != nil {
+		return err
+	}
+	r.record(oldPath, newPath)
+	return nil
+}
+
+// Changed returns the recorded paths, sorted.
+func (r *changeRecorder) Changed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	paths := make([]string, 0, len(r.changed))
+	for p := range r.changed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// AutoCommit commits the notes changed through the notebook's storage when
+// git.auto_commit is set, listing them under message. It reports whether a
+// commit was made.
+func (n *Notebook) AutoCommit(ctx context.Context, message string) (bool, error) {
+	if n.Config.Git == nil || !n.Config.Git.AutoCommit || n.changes == nil {
+		return false, nil
+	}
+	changed := n.changes.Changed()
+	if len(changed) == 0 {
+		return false, nil
+	}
+	git := n.GitRepo()
+	if git == nil {
+		return false, nil
+	}
+	return git.Commit(ctx, message+"\n\n"+strings.Join(changed, "\n"), changed)
+}
+
+// NoteHistory lists the commits touching the note at relPath, newest first.
+func (n *Notebook) NoteHistory(ctx context.Context, relPath string) ([]Commit, error) {
+	git := n.GitRepo()
+	if git == nil {
+		return nil, fmt.Errorf("notebook %q is not in a git repository", n.Config.Name)
+	}
+	return git.History(ctx, relPath)
+}
+
+// NoteRevision returns the note at relPath as committed at rev, decrypted
+// when the notebook has the key, and the commit that last changed it by
+// then. Without rev it is the version before the working copy: HEAD when
+// the note has uncommitted changes, otherwise the commit before the last.
+// A nil commit means the note did not exist yet and content is empty.
+func (n *Notebook) NoteRevision(ctx context.Context, relPath, rev string) ([]byte, *Commit, error) {
+	history, err := n.NoteHistory(ctx, relPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history) == 0 {
+		return nil, nil, fmt.Errorf("note %s has no commits", relPath)
+	}
+
+	git := n.GitRepo()
+	var commit *Commit
+	switch {
+	case rev != "":
+		hash, err := git.ResolveRev(ctx, rev)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range history {
+			if git.IsAncestor(ctx, history[i].Hash, hash) {
+				commit = &history[i]
+				break
+			}
+		}
+		if commit == nil {
+			return nil, nil, fmt.Errorf("note %s did not exist at %s", relPath, rev)
+		}
+	default:
+		dirty, err := git.Dirty(ctx, relPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		if dirty {
+			commit = &history[0]
+		} else if len(history) > 1 {
+			commit = &history[1]
+		} else {
+			return nil, nil, nil
+		}
+	}
+
+	content, err := git.Show(ctx, commit.Hash, commit.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if enc := n.Encryption(); enc != nil {
+		if content, err = enc.DecryptContent(relPath, content); err != nil {
+			return nil, nil, err
+		}
+	}
+	return content, commit, nil
+}
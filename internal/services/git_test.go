@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// gitRun runs git in dir, with author and committer dates set to date
+// when it is not empty.
+func gitRun(t *testing.T, dir, date string, args ...string) string {
+	t.Helper()
+	c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	c.Env = os.Environ()
+	if date != "" {
+		c.Env = append(c.Env, "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+	}
+	out, err := c.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return string(out)
+}
+
+// createGitNotebook creates a notebook in a fresh git repository.
+func createGitNotebook(t *testing.T, git *GitConfig) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+
+	notebookDir := createTestNotebook(t, t.TempDir(), "notes")
+	if git != nil {
+		configPath := filepath.Join(notebookDir, NotebookConfigFile)
+		var stored StoredNotebookConfig
+		data, err := os.ReadFile(configPath)
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &stored))
+		stored.Git = git
+		data, err = json.Marshal(stored)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(configPath, data, 0644))
+	}
+
+	gitRun(t, notebookDir, "", "init", "-q")
+	gitRun(t, notebookDir, "", "config", "user.name", "Sam")
+	gitRun(t, notebookDir, "", "config", "user.email", "sam@example.com")
+	gitRun(t, notebookDir, "", "add", ".")
+	gitRun(t, notebookDir, "2024-01-01T09:00:00Z", "commit", "-q", "-m", "init")
+	return notebookDir
+}
+
+func writeGitNote(t *testing.T, notebookDir, relPath, content string) {
+	t.Helper()
+	path := filepath.Join(notebookDir, ".notes", filepath.FromSlash(relPath))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestOpenGit_NotARepository(t *testing.T) {
+	_, err := OpenGit(context.Background(), t.TempDir())
+	assert.ErrorIs(t, err, ErrNotGitRepo)
+}
+
+func TestGit_HistoryFollowsRenames(t *testing.T) {
+	notebookDir := createGitNotebook(t, nil)
+	ctx := context.Background()
+
+	writeGitNote(t, notebookDir, "plan.md", "# Plan\n")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "add", ".")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "commit", "-q", "-m", "Add plan")
+	gitRun(t, notebookDir, "", "mv", ".notes/plan.md", ".notes/launch.md")
+	gitRun(t, notebookDir, "2024-03-01T09:00:00Z", "commit", "-q", "-m", "Rename plan")
+	writeGitNote(t, notebookDir, "launch.md", "# Plan\n\nShip it\n")
+	gitRun(t, notebookDir, "2024-04-01T09:00:00Z", "commit", "-q", "-am", "Update launch")
+
+	git, err := OpenGit(ctx, filepath.Join(notebookDir, ".notes"))
+	require.NoError(t, err)
+
+	history, err := git.History(ctx, "launch.md")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "Update launch", history[0].Subject)
+	assert.Equal(t, "Sam", history[0].Author)
+	assert.Equal(t, "launch.md", history[1].Path)
+	assert.Equal(t, "plan.md", history[2].Path, "commits before the rename keep the old path")
+	assert.Len(t, history[0].Short(), 7)
+
+	times, err := git.CommitTimes(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01", times["launch.md"].First.UTC().Format("2006-01-02"), "commit times do not follow renames")
+	assert.Equal(t, "2024-04-01", times["launch.md"].Last.UTC().Format("2006-01-02"))
+	assert.NotContains(t, times, "../.jot.json", "files outside the notes root are left out")
+}
+
+func TestNotebook_CommitDates(t *testing.T) {
+	notebookDir := createGitNotebook(t, &GitConfig{CommitDates: true})
+	writeGitNote(t, notebookDir, "plain.md", "# Plain\n")
+	writeGitNote(t, notebookDir, "dated.md", "---\ncreated: 2020-05-01T00:00:00Z\n---\n# Dated\n")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "add", ".")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "commit", "-q", "-m", "Add notes")
+	writeGitNote(t, notebookDir, "plain.md", "# Plain\n\nMore\n")
+	gitRun(t, notebookDir, "2024-03-01T09:00:00Z", "commit", "-q", "-am", "Edit plain")
+	writeGitNote(t, notebookDir, "draft.md", "# Draft\n")
+	gitRun(t, notebookDir, "2024-03-01T09:00:00Z", "add", ".notes/draft.md")
+	gitRun(t, notebookDir, "2024-03-01T09:00:00Z", "commit", "-q", "-m", "Add draft")
+	writeGitNote(t, notebookDir, "draft.md", "# Draft\n\nUncommitted\n")
+	edited := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(notebookDir, ".notes", "draft.md"), edited, edited))
+
+	svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+	require.NotNil(t, notebook.GitRepo())
+
+	ctx := context.Background()
+	results, err := notebook.Notes.index.Find(ctx, search.FindOpts{PathPrefix: "plain.md"})
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	plain := results.Items[0].Document
+	assert.Equal(t, "2024-02-01", plain.Created.UTC().Format("2006-01-02"))
+	assert.Equal(t, "2024-03-01", plain.Modified.UTC().Format("2006-01-02"))
+
+	results, err = notebook.Notes.index.Find(ctx, search.FindOpts{PathPrefix: "draft.md"})
+	require.NoError(t, err)
+	require.Len(t, results.Items, 1)
+	assert.Equal(t, "2024-03-01", results.Items[0].Document.Created.UTC().Format("2006-01-02"))
+	assert.Equal(t, "2024-05-01", results.Items[0].Document.Modified.UTC().Format("2006-01-02"), "uncommitted edits keep the file time")
+
+	content, err := notebook.Storage.Read("dated.md")
+	require.NoError(t, err)
+	first, last := fileTimes(notebook.Notes.commitTimes, "dated.md", time.Now())
+	doc := buildDocument("dated.md", content, first, last)
+	assert.Equal(t, "2020-05-01", doc.Created.UTC().Format("2006-01-02"), "frontmatter dates win")
+	assert.Equal(t, "2024-02-01", doc.Modified.UTC().Format("2006-01-02"))
+}
+
+func TestNotebook_CommitDatesOptIn(t *testing.T) {
+	notebookDir := createGitNotebook(t, nil)
+	writeGitNote(t, notebookDir, "plain.md", "# Plain\n")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "add", ".")
+	gitRun(t, notebookDir, "2024-02-01T09:00:00Z", "commit", "-q", "-m", "Add notes")
+
+	svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+	assert.Nil(t, notebook.Notes.commitTimes, "git log is not read")
+	assert.Nil(t, notebook.git, "git is not run until it is needed")
+	require.NotNil(t, notebook.GitRepo(), "history works without commit dates")
+}
+
+func TestNotebook_AutoCommit(t *testing.T) {
+	notebookDir := createGitNotebook(t, &GitConfig{AutoCommit: true})
+	writeGitNote(t, notebookDir, "old.md", "# Old\n")
+	writeGitNote(t, notebookDir, "stray.md", "# Stray\n")
+	gitRun(t, notebookDir, "", "add", ".notes/old.md")
+	gitRun(t, notebookDir, "", "commit", "-q", "-m", "Add old")
+
+	ctx := context.Background()
+	svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+
+	committed, err := notebook.AutoCommit(ctx, "jot nothing")
+	require.NoError(t, err)
+	assert.False(t, committed, "nothing changed")
+
+	require.NoError(t, notebook.Storage.Write("new.md", []byte("# New\n")))
+	require.NoError(t, notebook.Storage.Rename("old.md", "archive/old.md"))
+	require.NoError(t, notebook.Storage.Write(".jot/trash/x.md", []byte("x")))
+	committed, err = notebook.AutoCommit(ctx, "jot notes add New")
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	assert.Equal(t, "jot notes add New\n\narchive/old.md\nnew.md\nold.md", strings.TrimSpace(gitRun(t, notebookDir, "", "log", "-1", "--format=%B")))
+	files := gitRun(t, notebookDir, "", "show", "--name-status", "--format=", "HEAD")
+	assert.Contains(t, files, ".notes/new.md")
+	assert.Contains(t, files, ".notes/archive/old.md")
+	assert.NotContains(t, files, "stray.md", "only notes the command changed are committed")
+	assert.NotContains(t, files, ".jot/trash")
+	assert.Contains(t, gitRun(t, notebookDir, "", "status", "--porcelain"), "stray.md")
+}
+
+func TestNotebook_NoteRevision(t *testing.T) {
+	notebookDir := createGitNotebook(t, nil)
+	ctx := context.Background()
+
+	writeGitNote(t, notebookDir, "plan.md", "v1\n")
+	gitRun(t, notebookDir, "", "add", ".")
+	gitRun(t, notebookDir, "", "commit", "-q", "-m", "v1")
+	gitRun(t, notebookDir, "", "tag", "first")
+	writeGitNote(t, notebookDir, "plan.md", "v2\n")
+	gitRun(t, notebookDir, "", "commit", "-q", "-am", "v2")
+
+	svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+
+	content, commit, err := notebook.NoteRevision(ctx, "plan.md", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1\n", string(content), "a clean note compares with the commit before its last")
+	assert.Equal(t, "v1", commit.Subject)
+
+	writeGitNote(t, notebookDir, "plan.md", "v3\n")
+	content, _, err = notebook.NoteRevision(ctx, "plan.md", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v2\n", string(content), "a modified note compares with its last commit")
+
+	content, _, err = notebook.NoteRevision(ctx, "plan.md", "first")
+	require.NoError(t, err)
+	assert.Equal(t, "v1\n", string(content))
+
+	_, _, err = notebook.NoteRevision(ctx, "plan.md", "HEAD~2")
+	assert.ErrorContains(t, err, "did not exist at HEAD~2")
+	_, _, err = notebook.NoteRevision(ctx, "plan.md", "nope")
+	assert.ErrorContains(t, err, `unknown revision "nope"`)
+	_, _, err = notebook.NoteRevision(ctx, "missing.md", "")
+	assert.ErrorContains(t, err, "has no commits")
+	assert.True(t, strings.HasPrefix(commit.Hash, commit.Short()))
+
+	outside, err := NewNotebookService(createTestConfigService(t, t.TempDir(), nil)).Open(createTestNotebook(t, t.TempDir(), "plain"))
+	require.NoError(t, err)
+	_, err = outside.NoteHistory(ctx, "plan.md")
+	assert.ErrorContains(t, err, "not in a git repository")
+}
+
+func TestFileTimes(t *testing.T) {
+	mod := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	first, last := mod.AddDate(0, -2, 0), mod.AddDate(0, -1, 0)
+	times := map[string]CommitTimes{"a/b.md": {First: first, Last: last}}
+
+	created, modified := fileTimes(times, "a/b.md", mod)
+	assert.Equal(t, first, created)
+	assert.Equal(t, last, modified)
+	times["a/b.md"] = CommitTimes{First: first, Last: last, Dirty: true}
+	created, modified = fileTimes(times, "a/b.md", mod)
+	assert.Equal(t, first, created)
+	assert.Equal(t, mod, modified, "uncommitted edits are newer than the last commit")
+	created, modified = fileTimes(times, "a/b.md", last.AddDate(0, 0, -1))
+	assert.Equal(t, last, modified)
+	created, modified = fileTimes(times, "c.md", mod)
+	assert.Equal(t, mod, created)
+	assert.Equal(t, mod, modified)
+}
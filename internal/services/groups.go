@@ -0,0 +1,60 @@
+package services
+
+import (
+	"path/filepath"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// GroupDefaults is what the notebook groups contribute to a new note.
+type GroupDefaults struct {
+	// Groups lists the names of the matching groups, in config order.
+	Groups []string `json:"groups"`
+	// Metadata is the merged frontmatter of all matching groups.
+	Metadata map[string]any `json:"metadata"`
+	// Template is the template of the last matching group that sets one.
+	Template string `json:"template,omitempty"`
+}
+
+// Matches reports whether relPath matches any of the group's globs.
+func (g NotebookGroup) Matches(relPath string) bool {
+	return core.MatchAnyGlob(g.Globs, filepath.ToSlash(relPath))
+}
+
+// MatchGroups returns the groups whose globs match relPath, in config order.
+func (c *StoredNotebookConfig) MatchGroups(relPath string) []NotebookGroup {
+	var matched []NotebookGroup
+	for _, group := range c.Groups {
+		if group.Matches(relPath) {
+			matched = append(matched, group)
+		}
+	}
+	return matched
+}
+
+// GroupDefaults merges the groups matching relPath, a path relative to the
+// notebook root.
+//
+// Groups apply in the order they are listed in .jot.json, so later groups
+// override earlier ones: list broad groups (like the "Default" group jot
+// creates) first and specific ones after. A later group's metadata key
+// replaces an earlier one's, and the last matching group with a template
+// provides the template.
+func (c *StoredNotebookConfig) GroupDefaults(relPath string) GroupDefaults {
+	defaults := GroupDefaults{
+		Groups:   []string{},
+		Metadata: map[string]any{},
+	}
+
+	for _, group := range c.MatchGroups(relPath) {
+		defaults.Groups = append(defaults.Groups, group.Name)
+		for key, value := range group.Metadata {
+			defaults.Metadata[key] = value
+		}
+		if group.Template != "" {
+			defaults.Template = group.Template
+		}
+	}
+
+	return defaults
+}
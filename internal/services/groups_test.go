@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupDefaults_LaterGroupsOverride(t *testing.T) {
+	cfg := StoredNotebookConfig{
+		Groups: []NotebookGroup{
+			{Name: "Default", Globs: []string{"**/*.md"}, Metadata: map[string]any{"status": "draft", "author": "team"}},
+			{Name: "Meetings", Globs: []string{"meetings/**"}, Metadata: map[string]any{"status": "scheduled", "type": "meeting"}, Template: "meeting"},
+			{Name: "Standups", Globs: []string{"meetings/standup-*.md"}, Metadata: map[string]any{"type": "standup"}},
+		},
+	}
+
+	defaults := cfg.GroupDefaults("meetings/standup-2024-01-15.md")
+	assert.Equal(t, []string{"Default", "Meetings", "Standups"}, defaults.Groups)
+	assert.Equal(t, map[string]any{"status": "scheduled", "author": "team", "type": "standup"}, defaults.Metadata)
+	assert.Equal(t, "meeting", defaults.Template)
+
+	defaults = cfg.GroupDefaults("ideas.md")
+	assert.Equal(t, []string{"Default"}, defaults.Groups)
+	assert.Equal(t, map[string]any{"status": "draft", "author": "team"}, defaults.Metadata)
+	assert.Empty(t, defaults.Template)
+}
+
+func TestGroupDefaults_NoGroups(t *testing.T) {
+	cfg := StoredNotebookConfig{}
+
+	defaults := cfg.GroupDefaults("note.md")
+	assert.Empty(t, defaults.Groups)
+	assert.Empty(t, defaults.Metadata)
+}
+
+func TestMatchGroups(t *testing.T) {
+	cfg := StoredNotebookConfig{
+		Groups: []NotebookGroup{
+			{Name: "Projects", Globs: []string{"projects/**", "clients/*/projects/**"}},
+			{Name: "Top", Globs: []string{"*.md"}},
+		},
+	}
+
+	matched := cfg.MatchGroups("clients/acme/projects/plan.md")
+	assert.Len(t, matched, 1)
+	assert.Equal(t, "Projects", matched[0].Name)
+
+	matched = cfg.MatchGroups("readme.md")
+	assert.Len(t, matched, 1)
+	assert.Equal(t, "Top", matched[0].Name)
+}
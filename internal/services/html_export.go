@@ -0,0 +1,553 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// HTMLTemplateDir holds templates overriding the built-in HTML export
+// templates, relative to the notebook directory.
+const HTMLTemplateDir = ".jot/html"
+
+//go:embed templates/html
+var htmlTemplateFiles embed.FS
+
+// HTMLExportOptions controls exporting a notebook as a static site.
+type HTMLExportOptions struct {
+	// Notes are the notes to publish; nil publishes every note. Links to
+	// notes left out render as plain text.
+	Notes []Note
+	// Views are the saved views to publish as pages.
+	Views []HTMLView
+	// TemplateDir holds .gohtml templates replacing the built-in ones of
+	// the same name, and files such as style.css copied to the site root.
+	TemplateDir string
+}
+
+// HTMLView is a saved view and the notes it returned.
+type HTMLView struct {
+	Name        string
+	Description string
+	Notes       []Note
+}
+
+// HTMLExport reports what an HTML export wrote.
+type HTMLExport struct {
+	OutDir      string   `json:"out_dir"`
+	Notes       int      `json:"notes"`
+	Tags        int      `json:"tags"`
+	Views       int      `json:"views"`
+	Attachments int      `json:"attachments"`
+	Encrypted   []string `json:"encrypted,omitempty"`
+}
+
+// HTMLPage is the data every page template gets.
+type HTMLPage struct {
+	// Site is the notebook name.
+	Site string
+	// Kind is "index", "note", "tags", "tag" or "view".
+	Kind  string
+	Title string
+	// Root is the relative URL of the site root from the page, such as
+	// "../", for links to shared files.
+	Root string
+	// Views links to every published view, for navigation.
+	Views []HTMLLink
+
+	// Path, Metadata, Content, Tags and Backlinks describe the note of a
+	// note page.
+	Path      string
+	Metadata  map[string]any
+	Content   template.HTML
+	Tags      []HTMLLink
+	Backlinks []HTMLLink
+
+	// Notes lists the notes of the index, a tag or a view.
+	Notes []HTMLLink
+	// Description is the description of a view.
+	Description string
+}
+
+// HTMLLink is a link to another page, relative to the current one. Count is
+// the number of notes of a tag on the tags page.
+type HTMLLink struct {
+	Title string
+	URL   string
+	Count int
+}
+
+// htmlSearchEntry is a note in search-index.json.
+type htmlSearchEntry struct {
+	Title string   `json:"title"`
+	URL   string   `json:"url"`
+	Path  string   `json:"path"`
+	Tags  []string `json:"tags"`
+	Text  string   `json:"text"`
+}
+
+// htmlWikiLinkPattern also matches Obsidian style embeds, which are
+// published as links.
+var htmlWikiLinkPattern = regexp.MustCompile(`!?\[\[([^\]]+)\]\]`)
+
+// wikiLinkScheme marks the destinations of wiki links while converting
+// them to markdown links, so they resolve as wiki links.
+const wikiLinkScheme = "jot-wiki:"
+
+// htmlSite renders the pages of an export.
+type htmlSite struct {
+	nb        *Notebook
+	outDir    string
+	templates *template.Template
+	markdown  goldmark.Markdown
+	graph     *LinkGraph
+	published map[string]*Note
+	// tagPages maps tags to page paths
+	tagPages    map[string]string
+	views       []HTMLLink
+	attachments map[string]bool
+}
+
+// ExportHTML renders the notebook as a static site in outDir: a page per
+// note with its tags and backlinks, tag pages, a page per view, an index
+// page and search-index.json. Links between notes point to their pages.
+// Encrypted notes are never published.
+func (n *Notebook) ExportHTML(ctx context.Context, outDir string, opts HTMLExportOptions) (*HTMLExport, error) {
+	all, err := n.Notes.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	notes := opts.Notes
+	if notes == nil {
+		notes = all
+	}
+
+	templates, err := loadHTMLTemplates(opts.TemplateDir)
+	if err != nil {
+		return nil, err
+	}
+	site := &htmlSite{
+		nb:        n,
+		outDir:    outDir,
+		templates: templates,
+		markdown: goldmark.New(
+			goldmark.WithExtensions(extension.GFM),
+			goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		),
+		// Links resolve against every note, so a link to an unpublished
+		// note doesn't resolve to a published one of the same name
+		graph:       NewLinkGraph(all),
+		published:   make(map[string]*Note, len(notes)),
+		tagPages:    make(map[string]string),
+		attachments: make(map[string]bool),
+	}
+
+	result := &HTMLExport{OutDir: outDir}
+	for i := range notes {
+		note := &notes[i]
+		if enc := n.Encryption(); enc != nil {
+			if encrypted, _ := enc.IsEncryptedPath(note.File.Relative); encrypted {
+				result.Encrypted = append(result.Encrypted, note.File.Relative)
+				continue
+			}
+		}
+		site.published[note.File.Relative] = note
+	}
+	paths := make([]string, 0, len(site.published))
+	for p := range site.published {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	tagNotes := make(map[string][]string)
+	for _, p := range paths {
+		for _, tag := range metadataTags(site.published[p].Metadata) {
+			tagNotes[tag] = append(tagNotes[tag], p)
+		}
+	}
+	tags := make([]string, 0, len(tagNotes))
+	for tag := range tagNotes {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	// _tags/index.html lists the tags
+	taken := map[string]bool{"index": true}
+	for _, tag := range tags {
+		slug := core.Slugify(tag)
+		if slug == "" {
+			slug = "tag"
+		}
+		name := slug
+		for i := 2; taken[name]; i++ {
+			name = fmt.Sprintf("%s-%d", slug, i)
+		}
+		taken[name] = true
+		site.tagPages[tag] = htmlTagDir + name + ".html"
+	}
+
+	viewPages := make([]string, len(opts.Views))
+	taken = make(map[string]bool)
+	for i, view := range opts.Views {
+		name := core.Slugify(view.Name)
+		if name == "" || taken[name] {
+			name = fmt.Sprintf("view-%d", i+1)
+		}
+		taken[name] = true
+		viewPages[i] = htmlViewDir + name + ".html"
+		site.views = append(site.views, HTMLLink{Title: view.Name, URL: viewPages[i]})
+	}
+
+	// Generated pages live under reserved directories, but a note could
+	// still be written there, or be index.md
+	generated := map[string]bool{"index.html": true, htmlTagDir + "index.html": true}
+	for _, page := range site.tagPages {
+		generated[page] = true
+	}
+	for _, page := range viewPages {
+		generated[page] = true
+	}
+	for _, p := range paths {
+		if page := htmlPagePath(p); generated[page] {
+			return nil, fmt.Errorf("the page of note %s would be replaced by the generated %s; rename the note or leave it out with --where", p, page)
+		}
+	}
+
+	var search []htmlSearchEntry
+	for _, p := range paths {
+		note := site.published[p]
+		text, err := site.writeNote(note)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		search = append(search, htmlSearchEntry{
+			Title: note.DisplayName(),
+			URL:   htmlURL(htmlPagePath(p)),
+			Path:  p,
+			Tags:  metadataTags(note.Metadata),
+			Text:  text,
+		})
+	}
+	result.Notes = len(paths)
+
+	tagLinks := make([]HTMLLink, len(tags))
+	for i, tag := range tags {
+		page := site.tagPages[tag]
+		tagLinks[i] = HTMLLink{Title: tag, URL: path.Base(page), Count: len(tagNotes[tag])}
+		if err := site.writePage(page, "tag.gohtml", &HTMLPage{Kind: "tag", Title: tag, Notes: site.noteLinks(page, tagNotes[tag])}); err != nil {
+			return nil, err
+		}
+	}
+	if err := site.writePage(htmlTagDir+"index.html", "tags.gohtml", &HTMLPage{Kind: "tags", Title: "Tags", Tags: tagLinks}); err != nil {
+		return nil, err
+	}
+	result.Tags = len(tags)
+
+	for i, view := range opts.Views {
+		var viewNotes []string
+		for _, note := range view.Notes {
+			if site.published[note.File.Relative] != nil {
+				viewNotes = append(viewNotes, note.File.Relative)
+			}
+		}
+		page := &HTMLPage{Kind: "view", Title: view.Name, Description: view.Description, Notes: site.noteLinks(viewPages[i], viewNotes)}
+		if err := site.writePage(viewPages[i], "view.gohtml", page); err != nil {
+			return nil, err
+		}
+	}
+	result.Views = len(opts.Views)
+
+	if err := site.writePage("index.html", "index.gohtml", &HTMLPage{Kind: "index", Notes: site.noteLinks("index.html", paths)}); err != nil {
+		return nil, err
+	}
+	if search == nil {
+		search = []htmlSearchEntry{}
+	}
+	index, err := json.Marshal(search)
+	if err != nil {
+		return nil, err
+	}
+	if err := site.writeFile("search-index.json", index); err != nil {
+		return nil, err
+	}
+
+	if err := site.copyAssets(opts.TemplateDir); err != nil {
+		return nil, err
+	}
+	for p := range site.attachments {
+		data, err := n.Storage.Read(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read attachment %s: %w", p, err)
+		}
+		if err := site.writeFile(p, data); err != nil {
+			return nil, err
+		}
+	}
+	result.Attachments = len(site.attachments)
+	return result, nil
+}
+
+// loadHTMLTemplates parses the built-in templates, then the .gohtml files
+// of dir, which replace the templates of the same name.
+func loadHTMLTemplates(dir string) (*template.Template, error) {
+	templates, err := template.New("").ParseFS(htmlTemplateFiles, "templates/html/*.gohtml")
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		return templates, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.gohtml"))
+	if err != nil || len(files) == 0 {
+		return templates, err
+	}
+	templates, err = templates.ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid HTML template: %w", err)
+	}
+	return templates, nil
+}
+
+// copyAssets writes the built-in files such as style.css, then the
+// non-template files of dir.
+func (s *htmlSite) copyAssets(dir string) error {
+	builtin, _ := fs.Sub(htmlTemplateFiles, "templates/html")
+	assets := []fs.FS{builtin}
+	if dir != "" {
+		assets = append(assets, os.DirFS(dir))
+	}
+	for _, fsys := range assets {
+		entries, err := fs.ReadDir(fsys, ".")
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".gohtml") {
+				continue
+			}
+			data, err := fs.ReadFile(fsys, entry.Name())
+			if err != nil {
+				return err
+			}
+			if err := s.writeFile(entry.Name(), data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeNote renders the page of a note and returns its text for the
+// search index.
+func (s *htmlSite) writeNote(note *Note) (string, error) {
+	source := note.File.Relative
+	page := htmlPagePath(source)
+	content, text, err := s.renderMarkdown(source, note.Content)
+	if err != nil {
+		return "", err
+	}
+
+	var tags []HTMLLink
+	for _, tag := range metadataTags(note.Metadata) {
+		tags = append(tags, HTMLLink{Title: tag, URL: htmlRelURL(page, s.tagPages[tag])})
+	}
+	var backlinks []string
+	for _, link := range s.graph.Backlinks(source) {
+		if s.published[link.Source] != nil && (len(backlinks) == 0 || backlinks[len(backlinks)-1] != link.Source) {
+			backlinks = append(backlinks, link.Source)
+		}
+	}
+
+	return text, s.writePage(page, "note.gohtml", &HTMLPage{
+		Kind:      "note",
+		Title:     note.DisplayName(),
+		Path:      source,
+		Metadata:  note.Metadata,
+		Content:   template.HTML(content),
+		Tags:      tags,
+		Backlinks: s.noteLinks(page, backlinks),
+	})
+}
+
+// renderMarkdown renders a note body as HTML and plain text. Wiki links
+// and markdown links to published notes point to their pages; links to
+// other notes become plain text. Files the note links to are copied along.
+func (s *htmlSite) renderMarkdown(source, content string) (string, string, error) {
+	content = mapProse(content, func(prose string) string {
+		return htmlWikiLinkPattern.ReplaceAllStringFunc(prose, func(match string) string {
+			target := htmlWikiLinkPattern.FindStringSubmatch(match)[1]
+			label := target
+			if i := strings.Index(target, "|"); i >= 0 {
+				target, label = target[:i], target[i+1:]
+			}
+			return fmt.Sprintf("[%s](<%s%s>)", label, wikiLinkScheme, strings.TrimSpace(target))
+		})
+	})
+
+	src := []byte(content)
+	doc := s.markdown.Parser().Parse(text.NewReader(src))
+	var unlink []ast.Node
+	var words []string
+	err := ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := node.(type) {
+		case *ast.Text:
+			words = append(words, strings.Fields(string(n.Segment.Value(src)))...)
+		case *ast.CodeBlock, *ast.FencedCodeBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				words = append(words, strings.Fields(string(segment.Value(src)))...)
+			}
+		case *ast.Link:
+			dest, ok := s.rewriteLink(source, string(n.Destination))
+			if !ok {
+				unlink = append(unlink, n)
+			}
+			n.Destination = []byte(dest)
+		case *ast.Image:
+			dest, _ := s.rewriteLink(source, string(n.Destination))
+			n.Destination = []byte(dest)
+		}
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return "", "", err
+	}
+	for _, link := range unlink {
+		parent := link.Parent()
+		for child := link.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			parent.InsertBefore(parent, link, child)
+			child = next
+		}
+		parent.RemoveChild(parent, link)
+	}
+
+	var buf bytes.Buffer
+	if err := s.markdown.Renderer().Render(&buf, src, doc); err != nil {
+		return "", "", err
+	}
+	return buf.String(), strings.Join(words, " "), nil
+}
+
+// rewriteLink returns the destination of a link in the page of the note at
+// source, and false when it points to a note that isn't published or a
+// wiki link doesn't resolve.
+func (s *htmlSite) rewriteLink(source, dest string) (string, bool) {
+	kind := LinkMarkdown
+	if rest, ok := strings.CutPrefix(dest, wikiLinkScheme); ok {
+		kind, dest = LinkWiki, rest
+	}
+	if kind == LinkMarkdown && (dest == "" || strings.HasPrefix(dest, "#") || isExternalLink(dest)) {
+		return dest, true
+	}
+
+	target, anchor := dest, ""
+	if i := strings.Index(dest, "#"); i >= 0 {
+		target, anchor = dest[:i], dest[i:]
+		if kind == LinkWiki {
+			// Headings get ids from their text
+			anchor = "#" + core.Slugify(anchor[1:])
+		}
+	}
+	if decoded, err := url.PathUnescape(target); err == nil {
+		target = decoded
+	}
+
+	page := htmlPagePath(source)
+	if resolved := s.graph.Resolve(source, target, kind); resolved != "" {
+		if s.published[resolved] == nil {
+			return "", false
+		}
+		return htmlRelURL(page, htmlPagePath(resolved)) + anchor, true
+	}
+	if kind == LinkWiki {
+		return "", false
+	}
+
+	file := path.Join(path.Dir(source), target)
+	if strings.HasPrefix(target, "/") {
+		file = path.Clean(strings.TrimPrefix(target, "/"))
+	}
+	if file == "." || strings.HasPrefix(file, "../") || strings.HasSuffix(file, ".md") || !s.nb.Storage.Exists(file) {
+		return dest, true
+	}
+	s.attachments[file] = true
+	return htmlRelURL(page, file) + anchor, true
+}
+
+// noteLinks links from page to the pages of the notes at paths.
+func (s *htmlSite) noteLinks(page string, paths []string) []HTMLLink {
+	links := make([]HTMLLink, 0, len(paths))
+	for _, p := range paths {
+		links = append(links, HTMLLink{Title: s.published[p].DisplayName(), URL: htmlRelURL(page, htmlPagePath(p))})
+	}
+	return links
+}
+
+// writePage renders a page at the site path page.
+func (s *htmlSite) writePage(page, name string, data *HTMLPage) error {
+	data.Site = s.nb.Config.Name
+	data.Root = strings.Repeat("../", strings.Count(page, "/"))
+	data.Views = make([]HTMLLink, len(s.views))
+	for i, view := range s.views {
+		data.Views[i] = HTMLLink{Title: view.Title, URL: htmlRelURL(page, view.URL)}
+	}
+
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return s.writeFile(page, buf.Bytes())
+}
+
+func (s *htmlSite) writeFile(rel string, data []byte) error {
+	p := filepath.Join(s.outDir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0644)
+}
+
+// Directories of the generated tag and view pages. The leading underscore
+// keeps them apart from the pages of notes in tags/ or views/ directories.
+const (
+	htmlTagDir  = "_tags/"
+	htmlViewDir = "_views/"
+)
+
+// htmlPagePath is the site path of the page of the note at rel.
+func htmlPagePath(rel string) string {
+	return strings.TrimSuffix(rel, ".md") + ".html"
+}
+
+// htmlRelURL is the URL of the site path to from the page at from.
+func htmlRelURL(from, to string) string {
+	return strings.Repeat("../", strings.Count(from, "/")) + htmlURL(to)
+}
+
+// htmlURL escapes a site path for use in a URL.
+func htmlURL(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
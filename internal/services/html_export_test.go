@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var htmlNotes = map[string]string{
+	"people/alice.md": "---\ntitle: Alice\ntags: [team, index]\n---\n# Alice\n\n" +
+		"Works with [[Bob|bob]] and [[Secret]]. See [the plan](../plan.md#goals), [[plan#Big Goals]] and [docs](https://x.dev).\n\n" +
+		"![pic](../img/a.png) `[[Bob]]`\n",
+	"people/bob.md": "---\ntitle: Bob\ntags: [team]\n---\n# Bob\n\n```\ncode [[Alice]]\n```\n",
+	"plan.md":       "---\ntitle: Plan\ntag: roadmap\n---\n# Plan\n\n## Big Goals\n\n[Alice](people/alice.md)\n",
+	"secret.md":     "---\ntitle: Secret\ntags: [private]\n---\n[[Alice]]\n",
+	"img/a.png":     "png",
+}
+
+func readSite(t *testing.T, dir, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestNotebook_ExportHTML(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, htmlNotes)
+	all, err := nb.Notes.getAllNotes(ctx)
+	require.NoError(t, err)
+	var published []Note
+	for _, note := range all {
+		if note.File.Relative != "secret.md" {
+			published = append(published, note)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "site")
+	result, err := nb.ExportHTML(ctx, out, HTMLExportOptions{
+		Notes: published,
+		Views: []HTMLView{{Name: "Everyone", Description: "All people", Notes: all}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, &HTMLExport{OutDir: out, Notes: 3, Tags: 3, Views: 1, Attachments: 1}, result)
+
+	alice := readSite(t, out, "people/alice.html")
+	assert.Contains(t, alice, `<a href="../people/bob.html">bob</a> and Secret.`, "links to unpublished notes are text")
+	assert.Contains(t, alice, `<a href="../plan.html#goals">the plan</a>`)
+	assert.Contains(t, alice, `<a href="../plan.html#big-goals">plan#Big Goals</a>`)
+	assert.Contains(t, alice, `<a href="https://x.dev">docs</a>`)
+	assert.Contains(t, alice, `<img src="../img/a.png" alt="pic"> <code>[[Bob]]</code>`)
+	assert.Contains(t, alice, `<a class="tag" href="../_tags/index-2.html">#index</a>`)
+	assert.Contains(t, alice, `<li><a href="../plan.html">Plan</a></li>`)
+	assert.NotContains(t, alice, "secret.html", "backlinks from unpublished notes are left out")
+	assert.Contains(t, readSite(t, out, "people/bob.html"), "code [[Alice]]")
+	assert.Equal(t, "png", readSite(t, out, "img/a.png"))
+
+	assert.Contains(t, readSite(t, out, "_tags/index.html"), `<a href="team.html">#team</a> <span class="count">2</span>`)
+	assert.Contains(t, readSite(t, out, "_tags/team.html"), `<a href="../people/alice.html">Alice</a>`)
+	assert.Contains(t, readSite(t, out, "plan.html"), `<a class="tag" href="_tags/roadmap.html">#roadmap</a>`, "a singular tag field is a tag")
+	assert.Contains(t, readSite(t, out, "_tags/roadmap.html"), `<a href="../plan.html">Plan</a>`)
+	view := readSite(t, out, "_views/everyone.html")
+	assert.Contains(t, view, "All people")
+	assert.NotContains(t, view, "Secret")
+	index := readSite(t, out, "index.html")
+	assert.Contains(t, index, `<a href="_views/everyone.html">Everyone</a>`)
+	assert.Contains(t, index, `<li><a href="plan.html">Plan</a></li>`)
+	assert.FileExists(t, filepath.Join(out, "style.css"))
+
+	var search []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(readSite(t, out, "search-index.json")), &search))
+	require.Len(t, search, 3)
+	assert.Equal(t, map[string]any{"title": "Plan", "url": "plan.html", "path": "plan.md", "tags": []any{"roadmap"}, "text": "Plan Big Goals Alice"}, search[2])
+}
+
+func TestNotebook_ExportHTMLTemplates(t *testing.T) {
+	nb := openTestNotebook(t, htmlNotes)
+	templates := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(templates, "note.gohtml"), []byte(`{{ .Title }}|{{ .Metadata.title }}|{{ .Content }}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(templates, "style.css"), []byte("body{}"), 0644))
+
+	out := t.TempDir()
+	_, err := nb.ExportHTML(context.Background(), out, HTMLExportOptions{TemplateDir: templates})
+	require.NoError(t, err)
+	assert.Equal(t, "Plan|Plan|<h1 id=\"plan\">Plan</h1>\n<h2 id=\"big-goals\">Big Goals</h2>\n<p><a href=\"people/alice.html\">Alice</a></p>\n", readSite(t, out, "plan.html"))
+	assert.Equal(t, "body{}", readSite(t, out, "style.css"))
+	assert.Contains(t, readSite(t, out, "index.html"), "<h1>notes</h1>", "other templates stay built in")
+
+	require.NoError(t, os.WriteFile(filepath.Join(templates, "note.gohtml"), []byte(`{{ .Nope`), 0644))
+	_, err = nb.ExportHTML(context.Background(), out, HTMLExportOptions{TemplateDir: templates})
+	assert.ErrorContains(t, err, "invalid HTML template")
+}
+
+func TestNotebook_ExportHTMLGeneratedPages(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, htmlNotes)
+	require.NoError(t, nb.Storage.Write("tags/team.md", []byte("---\ntitle: Team Tag Notes\ntags: [team]\n---\nAbout the team tag\n")))
+	require.NoError(t, nb.Notes.IndexFile(ctx, "tags/team.md"))
+
+	out := t.TempDir()
+	_, err := nb.ExportHTML(ctx, out, HTMLExportOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, readSite(t, out, "tags/team.html"), "About the team tag", "notes in tags/ keep their pages")
+	assert.Contains(t, readSite(t, out, "_tags/team.html"), `<a href="../tags/team.html">Team Tag Notes</a>`)
+
+	for _, rel := range []string{"index.md", "_tags/team.md"} {
+		require.NoError(t, nb.Storage.Write(rel, []byte("---\ntitle: Clash\n---\nClash\n")))
+		require.NoError(t, nb.Notes.IndexFile(ctx, rel))
+		_, err = nb.ExportHTML(ctx, t.TempDir(), HTMLExportOptions{})
+		assert.ErrorContains(t, err, "the page of note "+rel+" would be replaced")
+		require.NoError(t, nb.Storage.Remove(rel))
+		require.NoError(t, nb.Notes.GetIndex().Remove(ctx, rel))
+	}
+}
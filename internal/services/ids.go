@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Note ID formats accepted in the "id_format" field of .jot.json.
+const (
+	// IDFormatULID generates 26 character ULIDs, sortable by creation time.
+	IDFormatULID = "ulid"
+	// IDFormatTimestamp generates zettelkasten-style YYYYMMDDHHMMSS IDs.
+	IDFormatTimestamp = "timestamp"
+)
+
+// timestampIDLayout is the time layout of timestamp IDs.
+const timestampIDLayout = "20060102150405"
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ValidIDFormat reports whether format is a supported note ID format.
+func ValidIDFormat(format string) bool {
+	return format == IDFormatULID || format == IDFormatTimestamp
+}
+
+// NewULID returns a ULID for t: 48 bits of milliseconds followed by 80
+// random bits, encoded as 26 Crockford base32 characters.
+func NewULID(t time.Time) (string, error) {
+	var b [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := 5; i >= 0; i-- {
+		b[i] = byte(ms)
+		ms >>= 8
+	}
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to generate id: %w", err)
+	}
+
+	// 128 bits as 26 characters of 5 bits, the first holding only 3
+	var out [26]byte
+	var acc uint64
+	bits := 2 // left padding so 130 bits divide into 26 groups
+	idx := 0
+	for _, v := range b {
+		acc = acc<<8 | uint64(v)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			out[idx] = crockford[(acc>>uint(bits))&31]
+			idx++
+		}
+	}
+	return string(out[:]), nil
+}
+
+// newNoteID generates an ID in format for a note created at t that is not
+// in taken. Timestamp IDs move forward a second at a time until free.
+func newNoteID(format string, t time.Time, taken map[string]bool) (string, error) {
+	switch format {
+	case IDFormatULID:
+		for {
+			id, err := NewULID(t)
+			if err != nil {
+				return "", err
+			}
+			if !taken[strings.ToLower(id)] {
+				return id, nil
+			}
+		}
+	case IDFormatTimestamp:
+		for {
+			id := t.Format(timestampIDLayout)
+			if !taken[strings.ToLower(id)] {
+				return id, nil
+			}
+			t = t.Add(time.Second)
+		}
+	default:
+		return "", fmt.Errorf("unknown id format %q (expected %s or %s)", format, IDFormatULID, IDFormatTimestamp)
+	}
+}
+
+// extractID returns the "id" frontmatter field as a string. Timestamp IDs
+// written without quotes parse as numbers.
+func extractID(metadata map[string]any) string {
+	switch v := metadata["id"].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case int, int64, uint64:
+		return fmt.Sprint(v)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+// noteIDs returns the lowercased IDs of notes.
+func noteIDs(notes []Note) map[string]bool {
+	ids := make(map[string]bool, len(notes))
+	for i := range notes {
+		if id := notes[i].ID; id != "" {
+			ids[strings.ToLower(id)] = true
+		}
+	}
+	return ids
+}
+
+// NewID generates a note ID in format for a note created at t that no
+// note in the notebook uses yet.
+func (s *NoteService) NewID(ctx context.Context, format string, t time.Time) (string, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return "", err
+	}
+	return newNoteID(format, t, noteIDs(notes))
+}
+
+// PlanIDBackfill plans adding IDs in format to the notes at paths that have
+// none, or to every such note when paths is empty. Each ID derives from the
+// note's creation time. Unlike field updates, "modified" is not stamped since
+// the note's content does not change.
+func (s *NoteService) PlanIDBackfill(ctx context.Context, format string, paths []string) ([]*NoteChange, error) {
+	if !ValidIDFormat(format) {
+		return nil, fmt.Errorf("unknown id format %q (expected %s or %s)", format, IDFormatULID, IDFormatTimestamp)
+	}
+
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortNotesByPath(notes)
+
+	selected := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		selected[p] = true
+	}
+
+	taken := noteIDs(notes)
+	var changes []*NoteChange
+	for _, note := range notes {
+		if note.ID != "" || (len(paths) > 0 && !selected[note.File.Relative]) {
+			continue
+		}
+
+		created := extractTime(note.Metadata, "created", time.Now())
+		id, err := newNoteID(format, created, taken)
+		if err != nil {
+			return nil, err
+		}
+		taken[strings.ToLower(id)] = true
+
+		content, err := s.storage.Read(note.File.Relative)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		updated, err := UpdateFrontmatter(content, map[string]any{"id": id}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", note.File.Relative, err)
+		}
+
+		changes = append(changes, &NoteChange{
+			Path:    note.File.Relative,
+			OldPath: note.File.Relative,
+			Before:  string(content),
+			After:   string(updated),
+		})
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,91 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+func TestNewULID(t *testing.T) {
+	earlier, err := services.NewULID(time.UnixMilli(1714555800000))
+	require.NoError(t, err)
+	later, err := services.NewULID(time.UnixMilli(1714555800001))
+	require.NoError(t, err)
+	again, err := services.NewULID(time.UnixMilli(1714555800001))
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), earlier)
+	assert.Equal(t, later[:10], again[:10], "the first 10 characters encode the time")
+	assert.Less(t, earlier, later, "ULIDs sort by time")
+	assert.NotEqual(t, later, again, "ULIDs carry random bits")
+}
+
+var idNotes = map[string]string{
+	"first.md":  "---\ntitle: First\ncreated: 2024-05-01T09:30:00Z\n---\n\n# First\n",
+	"second.md": "---\ncreated: 2024-05-01T09:30:00Z\n---\n\nSee [[first]].\n",
+	"third.md":  "---\nid: \"20240501093000\"\n---\n\n# Third\n",
+}
+
+func TestNoteService_PlanIDBackfill(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, idNotes)
+
+	changes, err := svc.PlanIDBackfill(ctx, services.IDFormatTimestamp, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 2, "notes with an id are skipped")
+
+	// Both notes were created at the same second as the existing id
+	assert.Equal(t, "notes/first.md", changes[0].Path)
+	assert.Contains(t, changes[0].After, "id: \"20240501093001\"\n")
+	assert.Contains(t, changes[1].After, "id: \"20240501093002\"\n")
+	assert.NotContains(t, changes[0].After, "modified:", "backfill does not stamp modified")
+
+	for _, change := range changes {
+		require.NoError(t, svc.ApplyChange(ctx, change))
+	}
+	content, err := os.ReadFile(filepath.Join(notebookDir, "notes", "first.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "id: \"20240501093001\"")
+
+	notes, err := svc.ResolveNote(ctx, "20240501093001")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "notes/first.md", notes[0].File.Relative)
+	assert.Equal(t, "20240501093001", notes[0].ID)
+
+	changes, err = svc.PlanIDBackfill(ctx, services.IDFormatULID, []string{"notes/first.md"})
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+
+	_, err = svc.PlanIDBackfill(ctx, "uuid", nil)
+	assert.ErrorContains(t, err, `unknown id format "uuid"`)
+}
+
+func TestNoteService_NewID(t *testing.T) {
+	svc, _ := testutil.NewNoteService(t, idNotes)
+
+	id, err := svc.NewID(context.Background(), services.IDFormatTimestamp, time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "20240501093001", id)
+}
+
+func TestNotebook_LintResolvesIDLinks(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, idNotes)
+	testutil.CreateTestNote(t, notebookDir, "fourth.md", "---\ntitle: Fourth\n---\n\n[[20240501093000|the third]] and [[20240501093000#top]].\n")
+	require.NoError(t, svc.ReindexFiles(ctx, []string{"notes/fourth.md"}))
+	nb := &services.Notebook{Notes: svc, Storage: bleve.OsStorage(notebookDir)}
+
+	findings, err := nb.Lint(ctx, []string{"broken-link"})
+	require.NoError(t, err)
+	assert.Empty(t, findings, "labelled ID links resolve")
+}
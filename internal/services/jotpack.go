@@ -0,0 +1,514 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+)
+
+// Notebook bundles are zip files holding a manifest and the notebook
+// directory:
+//
+//	jotpack.json            the manifest
+//	notebook/.jot.json      the notebook config
+//	notebook/.jot/...       templates and other notebook files
+//	notebook/<root>/...     notes and attachments, with the trash
+//	notebook/<root>/.jot/index/...  the search index, when bundled
+const (
+	// JotpackExtension is the file extension of notebook bundles.
+	JotpackExtension = ".jotpack"
+	// JotpackFormat is the version of the bundle layout written.
+	JotpackFormat = 1
+
+	jotpackManifestFile = "jotpack.json"
+	jotpackNotebookDir  = "notebook"
+)
+
+// JotpackManifest describes a notebook bundle.
+type JotpackManifest struct {
+	Format  int       `json:"format"`
+	Name    string    `json:"name"`
+	Created time.Time `json:"created"`
+	// Root is the notes directory, relative to the notebook directory.
+	Root  string `json:"root"`
+	Notes int    `json:"notes"`
+	Files int    `json:"files"`
+	// Index reports whether a prebuilt search index is included.
+	Index bool `json:"index"`
+}
+
+// JotpackExportOptions controls bundling a notebook.
+type JotpackExportOptions struct {
+	// Index includes a prebuilt search index of the notes.
+	Index bool
+}
+
+// JotpackExport reports what was bundled.
+type JotpackExport struct {
+	File     string          `json:"file"`
+	Manifest JotpackManifest `json:"manifest"`
+	// DroppedContexts are contexts outside the notebook directory, which
+	// mean nothing on another machine.
+	DroppedContexts []string `json:"dropped_contexts,omitempty"`
+	// Storage is the storage type of a notebook whose notes were bundled
+	// as local files, when it wasn't local.
+	Storage string `json:"storage,omitempty"`
+	// SkippedKeys are age identity files left out of the bundle, relative
+	// to the notebook directory.
+	SkippedKeys []string `json:"skipped_keys,omitempty"`
+}
+
+// ExportJotpack bundles the notebook into file: its config, with contexts
+// made relative to the notebook directory, its templates, and every file
+// of the notes root except the search index and git data. Encrypted notes
+// stay encrypted, and the notebook's key file and any other age identity
+// files are left out so the bundle never carries the key to its notes.
+func (n *Notebook) ExportJotpack(ctx context.Context, file string, opts JotpackExportOptions) (result *JotpackExport, err error) {
+	notebookDir := filepath.Dir(n.Config.Path)
+	bundlePath, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := readRawConfig(n.Config.Path)
+	if err != nil {
+		return nil, err
+	}
+	result = &JotpackExport{File: file}
+	// Notes outside the notebook directory, or in remote storage, are
+	// bundled in a notes directory of their own
+	root := "notes"
+	if rel, err := filepath.Rel(notebookDir, n.Config.Root); err == nil && n.Config.Storage.IsLocal() && (rel == "." || filepath.IsLocal(rel)) {
+		root = filepath.ToSlash(rel)
+	}
+	config["root"] = root
+	if !n.Config.Storage.IsLocal() {
+		result.Storage = n.Config.Storage.StorageType()
+		delete(config, "storage")
+	}
+	var contexts []string
+	for _, c := range n.Config.Contexts {
+		rel, err := filepath.Rel(notebookDir, c)
+		if err != nil || !filepath.IsLocal(rel) && rel != "." {
+			result.DroppedContexts = append(result.DroppedContexts, c)
+			continue
+		}
+		contexts = append(contexts, filepath.ToSlash(rel))
+	}
+	config["contexts"] = contexts
+
+	out, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	bundle := zip.NewWriter(out)
+	defer func() {
+		if closeErr := bundle.Close(); err == nil {
+			err = closeErr
+		}
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(file)
+		}
+	}()
+
+	keyFiles := n.keyFiles()
+	isKey := func(abs, rel string, content []byte) bool {
+		if keyFiles[abs] || isAgeIdentity(content) {
+			result.SkippedKeys = append(result.SkippedKeys, rel)
+			return true
+		}
+		return false
+	}
+
+	manifest := JotpackManifest{Format: JotpackFormat, Name: n.Config.Name, Created: time.Now().UTC(), Root: root}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := addZipFile(bundle, jotpackNotebookDir+"/"+NotebookConfigFile, data, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Templates and other notebook files, unless the notebook directory is
+	// the notes root and they are bundled with the notes
+	if root != "." {
+		jotDir := filepath.Join(notebookDir, ".jot")
+		err := filepath.WalkDir(jotDir, func(p string, entry fs.DirEntry, err error) error {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil || entry.IsDir() {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, _ := filepath.Rel(notebookDir, p)
+			if isKey(p, filepath.ToSlash(rel), content) {
+				return nil
+			}
+			manifest.Files++
+			return addZipFile(bundle, path.Join(jotpackNotebookDir, filepath.ToSlash(rel)), content, info.ModTime())
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Read files as stored so encrypted notes stay encrypted
+	var raw search.Storage = n.Storage
+	if enc := n.Encryption(); enc != nil {
+		raw = enc.Inner()
+	}
+	err = raw.Walk(".", func(rel string, info search.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if info.IsDir {
+			if rel == bleve.IndexDir || path.Base(rel) == ".git" {
+				return search.SkipDir
+			}
+			return nil
+		}
+		if root == "." && rel == NotebookConfigFile {
+			return nil
+		}
+		if n.Config.Storage.IsLocal() && filepath.Join(n.Config.Root, filepath.FromSlash(rel)) == bundlePath {
+			return nil
+		}
+		content, err := raw.Read(rel)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", rel, err)
+		}
+		abs := ""
+		if n.Config.Storage.IsLocal() {
+			abs = filepath.Join(n.Config.Root, filepath.FromSlash(rel))
+		}
+		if isKey(abs, path.Join(root, rel), content) {
+			return nil
+		}
+		if path.Ext(rel) == ".md" && !strings.HasPrefix(rel, ".jot/") {
+			manifest.Notes++
+		}
+		manifest.Files++
+		return addZipFile(bundle, path.Join(jotpackNotebookDir, root, rel), content, info.ModTime)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Index {
+		if err := n.bundleIndex(ctx, bundle, path.Join(jotpackNotebookDir, root)); err != nil {
+			return nil, fmt.Errorf("failed to bundle the search index: %w", err)
+		}
+		manifest.Index = true
+	}
+
+	data, err = json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := addZipFile(bundle, jotpackManifestFile, data, manifest.Created); err != nil {
+		return nil, err
+	}
+	result.Manifest = manifest
+	return result, nil
+}
+
+// bundleIndex writes a Bleve index of the notebook's notes under the
+// notes root of the bundle. Encrypted notes are left out so their content
+// isn't bundled in plain text.
+func (n *Notebook) bundleIndex(ctx context.Context, bundle *zip.Writer, root string) error {
+	tmp, err := os.MkdirTemp("", "jotpack-index-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	source := n.Notes.GetIndex()
+	count, err := source.Count(ctx, search.FindOpts{})
+	if err != nil {
+		return err
+	}
+	results, err := source.Find(ctx, search.FindOpts{Limit: int(count)})
+	if err != nil {
+		return err
+	}
+	idx, err := bleve.NewIndex(bleve.OsStorage(tmp), bleve.DefaultOptions())
+	if err != nil {
+		return err
+	}
+	enc := n.Encryption()
+	for _, item := range results.Items {
+		if enc != nil {
+			if encrypted, _ := enc.IsEncryptedPath(item.Document.Path); encrypted {
+				continue
+			}
+		}
+		if err := idx.Add(ctx, item.Document); err != nil {
+			_ = idx.Close()
+			return err
+		}
+	}
+	if err := idx.Close(); err != nil {
+		return err
+	}
+
+	return filepath.WalkDir(tmp, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(tmp, p)
+		return addZipFile(bundle, path.Join(root, filepath.ToSlash(rel)), content, time.Now())
+	})
+}
+
+func addZipFile(bundle *zip.Writer, name string, content []byte, modTime time.Time) error {
+	w, err := bundle.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// readRawConfig reads .jot.json as a map, keeping fields such as views that
+// StoredNotebookConfig doesn't hold.
+func readRawConfig(configPath string) (map[string]any, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid notebook config: %w", err)
+	}
+	return config, nil
+}
+
+// ReadJotpackManifest reads the manifest of a notebook bundle.
+func ReadJotpackManifest(file string) (*JotpackManifest, error) {
+	bundle, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a notebook bundle: %w", file, err)
+	}
+	defer func() { _ = bundle.Close() }()
+	return readJotpackManifest(&bundle.Reader, file)
+}
+
+func readJotpackManifest(bundle *zip.Reader, file string) (*JotpackManifest, error) {
+	f, err := bundle.Open(jotpackManifestFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a notebook bundle: no %s", file, jotpackManifestFile)
+	}
+	defer func() { _ = f.Close() }()
+	var manifest JotpackManifest
+	if err := json.NewDecoder(f).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if manifest.Format > JotpackFormat {
+		return nil, fmt.Errorf("bundle format %d is newer than this version of jot supports (%d)", manifest.Format, JotpackFormat)
+	}
+	return &manifest, nil
+}
+
+// ImportJotpack unpacks a notebook bundle into target, which must be empty
+// or not exist, and opens it. Contexts are resolved against target, which
+// becomes the only context when the bundle has none. name, when set,
+// renames the notebook.
+func (s *NotebookService) ImportJotpack(file, target, name string, register bool) (*Notebook, *JotpackManifest, error) {
+	bundle, err := zip.OpenReader(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s is not a notebook bundle: %w", file, err)
+	}
+	defer func() { _ = bundle.Close() }()
+	manifest, err := readJotpackManifest(&bundle.Reader, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if entries, err := os.ReadDir(target); err == nil && len(entries) > 0 {
+		return nil, nil, fmt.Errorf("%s is not empty", target)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+
+	// Check the config before writing anything, so a bundle can't point
+	// the notes root outside the target
+	config, err := readJotpackConfig(&bundle.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Unpack next to target and move it into place once complete, so a
+	// failed import leaves nothing behind
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return nil, nil, err
+	}
+	staging, err := os.MkdirTemp(filepath.Dir(target), "."+filepath.Base(target)+"-import-*")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = os.RemoveAll(staging) }()
+	if err := os.Chmod(staging, 0755); err != nil {
+		return nil, nil, err
+	}
+
+	prefix := jotpackNotebookDir + "/"
+	for _, f := range bundle.File {
+		rel, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok || f.FileInfo().IsDir() {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil, nil, fmt.Errorf("bundle entry %s is outside the notebook", f.Name)
+		}
+		if err := extractZipFile(f, filepath.Join(staging, filepath.FromSlash(rel))); err != nil {
+			return nil, nil, fmt.Errorf("failed to extract %s: %w", rel, err)
+		}
+	}
+
+	contexts := []string{}
+	if stored, ok := config["contexts"].([]any); ok {
+		for _, c := range stored {
+			rel, ok := c.(string)
+			if ok && (rel == "." || filepath.IsLocal(filepath.FromSlash(rel))) {
+				contexts = append(contexts, filepath.Join(target, filepath.FromSlash(rel)))
+			}
+		}
+	}
+	if len(contexts) == 0 {
+		contexts = append(contexts, target)
+	}
+	config["contexts"] = contexts
+	if name != "" {
+		config["name"] = name
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(configFilePath(staging), data, 0644); err != nil {
+		return nil, nil, err
+	}
+	if err := os.Remove(target); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, err
+	}
+	if err := os.Rename(staging, target); err != nil {
+		return nil, nil, err
+	}
+
+	nb, err := s.Open(target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if register {
+		if err := s.configService.RegisterNotebook(target); err != nil {
+			return nil, nil, fmt.Errorf("failed to register notebook: %w", err)
+		}
+	}
+	return nb, manifest, nil
+}
+
+// keyFiles returns the absolute paths of the age identity files the
+// notebook is configured to decrypt with.
+func (n *Notebook) keyFiles() map[string]bool {
+	files := make(map[string]bool)
+	notebookDir := filepath.Dir(n.Config.Path)
+	if override := strings.TrimSpace(os.Getenv(EnvKeyFile)); override != "" {
+		files[resolveKeyPath(override, notebookDir)] = true
+	}
+	if n.Config.Encryption != nil && n.Config.Encryption.KeyFile != "" {
+		files[resolveKeyPath(n.Config.Encryption.KeyFile, notebookDir)] = true
+	}
+	return files
+}
+
+// isAgeIdentity reports whether content holds age secret keys.
+func isAgeIdentity(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "AGE-SECRET-KEY-") || strings.HasPrefix(line, "AGE-PLUGIN-") {
+			return true
+		}
+	}
+	return false
+}
+
+// readJotpackConfig reads the notebook config of a bundle, rejecting notes
+// roots outside the notebook directory. The storage backend is dropped:
+// bundled notes are always local files.
+func readJotpackConfig(bundle *zip.Reader) (map[string]any, error) {
+	f, err := bundle.Open(jotpackNotebookDir + "/" + NotebookConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("bundle has no notebook config")
+	}
+	defer func() { _ = f.Close() }()
+	var config map[string]any
+	if err := json.NewDecoder(f).Decode(&config); err != nil {
+		return nil, fmt.Errorf("invalid notebook config: %w", err)
+	}
+
+	if root, ok := config["root"]; ok {
+		rel, ok := root.(string)
+		if !ok || rel != "." && !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil, fmt.Errorf("bundle notes root %v is outside the notebook", root)
+		}
+	}
+	delete(config, "storage")
+	return config, nil
+}
+
+// extractZipFile writes a bundle entry to dest, keeping its modification
+// time.
+func extractZipFile(f *zip.File, dest string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = r.Close() }()
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	w, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, f.Modified, f.Modified)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotebook_Jotpack(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg := createTestConfigService(t, tmpDir, nil)
+	svc := NewNotebookService(cfg)
+	notebookDir := createTestNotebook(t, tmpDir, "team")
+
+	config, err := readRawConfig(configFilePath(notebookDir))
+	require.NoError(t, err)
+	config["contexts"] = []string{notebookDir, filepath.Join(notebookDir, "sub"), "/elsewhere"}
+	config["views"] = map[string]any{"people": map[string]any{"name": "people", "query": "tag:team"}}
+	writeRawConfig(t, notebookDir, config)
+	require.NoError(t, os.MkdirAll(filepath.Join(notebookDir, TemplateDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(notebookDir, TemplateDir, "meeting.md"), []byte("# {{ .Title }}"), 0644))
+
+	nb, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+	require.NoError(t, nb.Storage.Write("people/alice.md", []byte("---\ntitle: Alice\ntags: [team]\n---\nHi\n")))
+	require.NoError(t, nb.Notes.IndexFile(ctx, "people/alice.md"))
+	require.NoError(t, nb.Storage.Write("img/a.png", []byte("png")))
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(nb.Config.Root, "people/alice.md"), modified, modified))
+
+	file := filepath.Join(tmpDir, "team.jotpack")
+	result, err := nb.ExportJotpack(ctx, file, JotpackExportOptions{Index: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/elsewhere"}, result.DroppedContexts)
+	assert.Equal(t, JotpackManifest{Format: JotpackFormat, Name: "team", Created: result.Manifest.Created, Root: ".notes", Notes: 1, Files: 3, Index: true}, result.Manifest)
+
+	target := filepath.Join(tmpDir, "restored")
+	restored, manifest, err := svc.ImportJotpack(file, target, "", true)
+	require.NoError(t, err)
+	assert.Equal(t, result.Manifest.Name, manifest.Name)
+	assert.Equal(t, "team", restored.Config.Name)
+	assert.Equal(t, filepath.Join(target, ".notes"), restored.Config.Root)
+	assert.Equal(t, []string{target, filepath.Join(target, "sub")}, restored.Config.Contexts)
+	assert.Contains(t, cfg.Store.Notebooks, target)
+
+	config, err = readRawConfig(configFilePath(target))
+	require.NoError(t, err)
+	assert.Contains(t, config, "views", "views survive the round trip")
+	data, err := os.ReadFile(filepath.Join(target, TemplateDir, "meeting.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# {{ .Title }}", string(data))
+	data, err = restored.Storage.Read("img/a.png")
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+	info, err := os.Stat(filepath.Join(target, ".notes/people/alice.md"))
+	require.NoError(t, err)
+	assert.True(t, modified.Equal(info.ModTime()), "modification times are kept")
+	assert.DirExists(t, filepath.Join(target, ".notes/.jot/index"))
+	notes, err := restored.Notes.getAllNotes(ctx)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "people/alice.md", notes[0].File.Relative)
+
+	_, _, err = svc.ImportJotpack(file, target, "", false)
+	assert.ErrorContains(t, err, "is not empty")
+
+	renamed, _, err := svc.ImportJotpack(file, filepath.Join(tmpDir, "renamed"), "Other", false)
+	require.NoError(t, err)
+	assert.Equal(t, "Other", renamed.Config.Name)
+}
+
+func TestNotebookService_ImportJotpackRejectsEscapingPaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "evil.jotpack")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	bundle := zip.NewWriter(out)
+	require.NoError(t, addZipFile(bundle, jotpackManifestFile, []byte(`{"format":1,"name":"evil"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/.jot.json", []byte(`{"name":"evil","root":"."}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/../escaped.txt", []byte("x"), time.Now()))
+	require.NoError(t, bundle.Close())
+	require.NoError(t, out.Close())
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	_, _, err = svc.ImportJotpack(file, filepath.Join(tmpDir, "target"), "", false)
+	assert.ErrorContains(t, err, "outside the notebook")
+	assert.NoFileExists(t, filepath.Join(tmpDir, "escaped.txt"))
+}
+
+func TestNotebookService_ImportJotpackRejectsEscapingRoot(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "evil.jotpack")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	bundle := zip.NewWriter(out)
+	require.NoError(t, addZipFile(bundle, jotpackManifestFile, []byte(`{"format":1,"name":"evil"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/.jot.json", []byte(`{"name":"evil","root":"../escaped"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/note.md", []byte("# Note"), time.Now()))
+	require.NoError(t, bundle.Close())
+	require.NoError(t, out.Close())
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	target := filepath.Join(tmpDir, "target")
+	_, _, err = svc.ImportJotpack(file, target, "", false)
+	assert.ErrorContains(t, err, "outside the notebook")
+	assert.NoDirExists(t, target, "nothing is extracted")
+}
+
+func TestNotebookService_ImportJotpackDropsStorage(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "remote.jotpack")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	bundle := zip.NewWriter(out)
+	require.NoError(t, addZipFile(bundle, jotpackManifestFile, []byte(`{"format":1,"name":"remote"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/.jot.json", []byte(`{"name":"remote","root":"notes","storage":{"type":"webdav","url":"https://evil.example"}}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/notes/note.md", []byte("# Note"), time.Now()))
+	require.NoError(t, bundle.Close())
+	require.NoError(t, out.Close())
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	target := filepath.Join(tmpDir, "target")
+	nb, _, err := svc.ImportJotpack(file, target, "", false)
+	require.NoError(t, err)
+	assert.True(t, nb.Config.Storage.IsLocal())
+	assert.Equal(t, filepath.Join(target, "notes"), nb.Config.Root)
+	config, err := readRawConfig(configFilePath(target))
+	require.NoError(t, err)
+	assert.NotContains(t, config, "storage")
+}
+
+func TestNotebook_JotpackLeavesOutKeys(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	notebookDir := createTestNotebook(t, tmpDir, "secret")
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(notebookDir, ".jot"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(notebookDir, ".jot", "key.txt"), []byte(identity.String()+"\n"), 0600))
+	config, err := readRawConfig(configFilePath(notebookDir))
+	require.NoError(t, err)
+	config["encryption"] = map[string]any{"key_file": ".jot/key.txt", "globs": []string{"private/**"}}
+	writeRawConfig(t, notebookDir, config)
+
+	nb, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+	require.NoError(t, nb.Storage.Write("private/diary.md", []byte("# Diary\n")))
+	require.NoError(t, nb.Storage.Write("backup.txt", []byte("# created: 2024-01-01\n"+identity.String()+"\n")))
+
+	file := filepath.Join(tmpDir, "secret.jotpack")
+	result, err := nb.ExportJotpack(ctx, file, JotpackExportOptions{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{".jot/key.txt", ".notes/backup.txt"}, result.SkippedKeys)
+
+	bundle, err := zip.OpenReader(file)
+	require.NoError(t, err)
+	defer func() { _ = bundle.Close() }()
+	var names []string
+	for _, f := range bundle.File {
+		names = append(names, f.Name)
+	}
+	assert.Contains(t, names, "notebook/.notes/private/diary.md", "encrypted notes are bundled")
+	assert.NotContains(t, names, "notebook/.jot/key.txt")
+	assert.NotContains(t, names, "notebook/.notes/backup.txt")
+}
+
+func TestNotebookService_ImportJotpackCleansUpOnFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	file := filepath.Join(tmpDir, "broken.jotpack")
+	out, err := os.Create(file)
+	require.NoError(t, err)
+	bundle := zip.NewWriter(out)
+	require.NoError(t, addZipFile(bundle, jotpackManifestFile, []byte(`{"format":1,"name":"broken"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/.jot.json", []byte(`{"name":"broken","root":"notes"}`), time.Now()))
+	require.NoError(t, addZipFile(bundle, "notebook/notes/a.md", []byte("# A"), time.Now()))
+	// a.md is a file, so nothing can be extracted below it
+	require.NoError(t, addZipFile(bundle, "notebook/notes/a.md/b.md", []byte("# B"), time.Now()))
+	require.NoError(t, bundle.Close())
+	require.NoError(t, out.Close())
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	parent := filepath.Join(tmpDir, "imports")
+	_, _, err = svc.ImportJotpack(file, filepath.Join(parent, "target"), "", false)
+	assert.ErrorContains(t, err, "failed to extract")
+	entries, err := os.ReadDir(parent)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "nothing is left behind")
+}
+
+func writeRawConfig(t *testing.T, notebookDir string, config map[string]any) {
+	t.Helper()
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configFilePath(notebookDir), data, 0644))
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// DefaultBoardView is the view "jot kanban" shows without --view.
+const DefaultBoardView = "kanban"
+
+// noGroup is the group key of notes without a value for the grouped field.
+const noGroup = "(none)"
+
+// Board is a grouped view laid out as kanban columns. Moving a card to
+// another column sets the grouped field of its note to the column name.
+type Board struct {
+	View    string        `json:"view"`
+	Field   string        `json:"field"`
+	Columns []BoardColumn `json:"columns"`
+}
+
+// BoardColumn is one column of a board.
+type BoardColumn struct {
+	Name  string `json:"name"`
+	Limit int    `json:"wip_limit,omitempty"` // 0 when the column has no WIP limit
+	Notes []Note `json:"notes"`
+}
+
+// Full reports whether another card would exceed the column's WIP limit.
+func (c *BoardColumn) Full() bool {
+	return c.Limit > 0 && len(c.Notes) >= c.Limit
+}
+
+// OverLimit reports whether the column holds more cards than its WIP limit.
+func (c *BoardColumn) OverLimit() bool {
+	return c.Limit > 0 && len(c.Notes) > c.Limit
+}
+
+// BoardField returns the field a view groups by, which a board of the view
+// writes when cards move.
+func BoardField(view *core.ViewDefinition) (string, error) {
+	if view.IsSpecialView() {
+		return "", fmt.Errorf("view %q cannot be shown as a board", view.Name)
+	}
+	_, directives := SplitViewQuery(view.Query)
+	parsed, err := ParseDirectives(directives)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse directives: %w", err)
+	}
+	switch parsed.GroupBy {
+	case "":
+		return "", fmt.Errorf("view %q has no group: directive to make columns from", view.Name)
+	case "tag", "tags":
+		return "", fmt.Errorf("view %q groups by tags, which cannot be shown as a board", view.Name)
+	}
+	return parsed.GroupBy, nil
+}
+
+// BoardLayout merges the board settings of the notebook and of a view. The
+// view's column order replaces the notebook's and its WIP limits override
+// the notebook's column by column.
+func BoardLayout(notebook *StoredNotebookConfig, view *core.ViewDefinition) core.BoardConfig {
+	layout := core.BoardConfig{WIPLimits: map[string]int{}}
+	for _, cfg := range []*core.BoardConfig{notebook.Board, view.Board} {
+		if cfg == nil {
+			continue
+		}
+		if len(cfg.Columns) > 0 {
+			layout.Columns = cfg.Columns
+		}
+		for column, limit := range cfg.WIPLimits {
+			layout.WIPLimits[column] = limit
+		}
+	}
+	return layout
+}
+
+// BuildBoard lays out the groups of a view result as columns. Columns come
+// in the configured order, falling back to the enum of the field's schema;
+// they are shown even when empty. Other groups follow sorted by name, with
+// notes missing the field last.
+func BuildBoard(notebook *StoredNotebookConfig, view *core.ViewDefinition, results *ViewResults) (*Board, error) {
+	field, err := BoardField(view)
+	if err != nil {
+		return nil, err
+	}
+	layout := BoardLayout(notebook, view)
+
+	order := layout.Columns
+	if len(order) == 0 {
+		if schema := notebook.Schema[field]; schema != nil {
+			for _, value := range schema.Enum {
+				order = append(order, fmt.Sprint(value))
+			}
+		}
+	}
+
+	board := &Board{View: view.Name, Field: field}
+	listed := make(map[string]bool, len(order))
+	for _, name := range order {
+		if listed[name] {
+			continue
+		}
+		listed[name] = true
+		board.Columns = append(board.Columns, BoardColumn{Name: name, Notes: results.Groups[name]})
+	}
+
+	var rest []string
+	for name := range results.Groups {
+		if !listed[name] && name != noGroup {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	if _, ok := results.Groups[noGroup]; ok && !listed[noGroup] {
+		rest = append(rest, noGroup)
+	}
+	for _, name := range rest {
+		board.Columns = append(board.Columns, BoardColumn{Name: name, Notes: results.Groups[name]})
+	}
+
+	for i := range board.Columns {
+		if board.Columns[i].Notes == nil {
+			board.Columns[i].Notes = []Note{}
+		}
+		if limit := layout.WIPLimits[board.Columns[i].Name]; limit > 0 {
+			board.Columns[i].Limit = limit
+		}
+	}
+	return board, nil
+}
+
+// Column returns the column with the given name, matched case-insensitively.
+func (b *Board) Column(name string) *BoardColumn {
+	for i := range b.Columns {
+		if strings.EqualFold(b.Columns[i].Name, name) {
+			return &b.Columns[i]
+		}
+	}
+	return nil
+}
+
+// Find returns the column holding the note at relPath.
+func (b *Board) Find(relPath string) *BoardColumn {
+	for i := range b.Columns {
+		for _, note := range b.Columns[i].Notes {
+			if note.File.Relative == relPath {
+				return &b.Columns[i]
+			}
+		}
+	}
+	return nil
+}
+
+// CheckMove reports why the note at relPath cannot move to column. Moves
+// into a full column are refused unless force is set. A column not on the
+// board starts a new one; restrict the values with an enum in the field
+// schema. It returns the column name as written on the board.
+func (b *Board) CheckMove(relPath, column string, force bool) (string, error) {
+	if column == "" || column == noGroup {
+		return "", fmt.Errorf("invalid column %q", column)
+	}
+
+	target := b.Column(column)
+	if target == nil {
+		return column, nil
+	}
+	if from := b.Find(relPath); from == target {
+		return target.Name, nil
+	}
+	if target.Full() && !force {
+		return "", fmt.Errorf("column %q is at its WIP limit of %d", target.Name, target.Limit)
+	}
+	return target.Name, nil
+}
+
+// PlanBoardMove computes the change moving the note at relPath to column:
+// the board's field is set to the column name. It returns nil when the
+// note is already in that column.
+func (s *NoteService) PlanBoardMove(board *Board, relPath, column string, force bool) (*NoteChange, error) {
+	name, err := board.CheckMove(relPath, column, force)
+	if err != nil {
+		return nil, err
+	}
+	return s.PlanFieldUpdate(relPath, map[string]any{board.Field: name}, nil)
+}
@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/core"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+func boardNote(path string) services.Note {
+	var note services.Note
+	note.File.Relative = path
+	return note
+}
+
+func boardColumnNames(board *services.Board) []string {
+	names := make([]string, len(board.Columns))
+	for i, column := range board.Columns {
+		names[i] = column.Name
+	}
+	return names
+}
+
+func TestBoardField(t *testing.T) {
+	field, err := services.BoardField(&core.ViewDefinition{Name: "kanban", Query: "has:status | group:status sort:title:asc"})
+	require.NoError(t, err)
+	assert.Equal(t, "status", field)
+
+	_, err = services.BoardField(&core.ViewDefinition{Name: "recent", Query: "| sort:modified:desc"})
+	assert.ErrorContains(t, err, `view "recent" has no group: directive`)
+	_, err = services.BoardField(&core.ViewDefinition{Name: "by-tag", Query: "| group:tags"})
+	assert.ErrorContains(t, err, "groups by tags")
+	_, err = services.BoardField(&core.ViewDefinition{Name: "orphans", Type: "special"})
+	assert.ErrorContains(t, err, "cannot be shown as a board")
+}
+
+func TestBuildBoard(t *testing.T) {
+	view := &core.ViewDefinition{Name: "kanban", Query: "has:status | group:status"}
+	results := &services.ViewResults{Groups: map[string][]services.Note{
+		"done":    {boardNote("a.md")},
+		"blocked": {boardNote("b.md")},
+		"(none)":  {boardNote("c.md")},
+		"doing":   {boardNote("d.md"), boardNote("e.md")},
+	}}
+
+	t.Run("schema enum orders columns", func(t *testing.T) {
+		notebook := &services.StoredNotebookConfig{Schema: map[string]*services.FieldSchema{
+			"status": {Enum: []any{"todo", "doing", "done"}},
+		}}
+		board, err := services.BuildBoard(notebook, view, results)
+		require.NoError(t, err)
+		assert.Equal(t, "status", board.Field)
+		assert.Equal(t, []string{"todo", "doing", "done", "blocked", "(none)"}, boardColumnNames(board))
+		assert.Empty(t, board.Columns[0].Notes)
+		assert.NotNil(t, board.Columns[0].Notes, "empty columns list no notes rather than null")
+	})
+
+	t.Run("view board overrides notebook board", func(t *testing.T) {
+		notebook := &services.StoredNotebookConfig{Board: &core.BoardConfig{
+			Columns:   []string{"todo", "doing"},
+			WIPLimits: map[string]int{"doing": 1, "done": 5},
+		}}
+		withBoard := *view
+		withBoard.Board = &core.BoardConfig{
+			Columns:   []string{"doing", "done", "doing"},
+			WIPLimits: map[string]int{"done": 1},
+		}
+
+		board, err := services.BuildBoard(notebook, &withBoard, results)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doing", "done", "blocked", "(none)"}, boardColumnNames(board))
+		assert.Equal(t, 1, board.Columns[0].Limit)
+		assert.True(t, board.Columns[0].OverLimit())
+		assert.Equal(t, 1, board.Columns[1].Limit, "view limits override the notebook's per column")
+		assert.True(t, board.Columns[1].Full())
+		assert.False(t, board.Columns[1].OverLimit())
+	})
+}
+
+func TestBoard_CheckMove(t *testing.T) {
+	board := &services.Board{Field: "status", Columns: []services.BoardColumn{
+		{Name: "todo", Notes: []services.Note{boardNote("a.md")}},
+		{Name: "Doing", Limit: 1, Notes: []services.Note{boardNote("b.md")}},
+	}}
+
+	_, err := board.CheckMove("a.md", "doing", false)
+	assert.ErrorContains(t, err, `column "Doing" is at its WIP limit of 1`)
+	name, err := board.CheckMove("a.md", "doing", true)
+	require.NoError(t, err)
+	assert.Equal(t, "Doing", name, "columns match case-insensitively")
+
+	name, err = board.CheckMove("b.md", "doing", false)
+	require.NoError(t, err, "a card already in a full column can stay there")
+	assert.Equal(t, "Doing", name)
+
+	name, err = board.CheckMove("a.md", "review", false)
+	require.NoError(t, err)
+	assert.Equal(t, "review", name)
+
+	_, err = board.CheckMove("a.md", "(none)", false)
+	assert.ErrorContains(t, err, `invalid column "(none)"`)
+}
+
+func TestNoteService_PlanBoardMove(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	testutil.CreateTestNote(t, notebookDir, "launch.md", "---\ntitle: Launch\nstatus: todo # next up\n---\n\nBody\n")
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	board := &services.Board{Field: "status", Columns: []services.BoardColumn{
+		{Name: "todo", Notes: []services.Note{boardNote("notes/launch.md")}},
+		{Name: "doing"},
+	}}
+
+	change, err := svc.PlanBoardMove(board, "notes/launch.md", "DOING", false)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Contains(t, change.After, "status: doing # next up\n")
+	assert.Contains(t, change.After, "modified:")
+
+	change, err = svc.PlanBoardMove(board, "notes/launch.md", "todo", false)
+	require.NoError(t, err)
+	assert.Nil(t, change, "moving to the current column changes nothing")
+}
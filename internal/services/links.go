@@ -0,0 +1,253 @@
+package services
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LinkKind identifies the syntax a link was written in.
+type LinkKind string
+
+// Supported link syntaxes.
+const (
+	LinkMarkdown    LinkKind = "markdown"    // [text](path.md)
+	LinkWiki        LinkKind = "wiki"        // [[target]] or [[target|label]]
+	LinkFrontmatter LinkKind = "frontmatter" // links: [path.md]
+)
+
+var (
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]+)(?:\s+"[^"]*")?\)`)
+	wikiLinkPattern     = regexp.MustCompile(`\[\[([^\]]+)\]\]`)
+)
+
+// Link is a reference from one note to another.
+type Link struct {
+	// Source is the relative path of the note containing the link.
+	Source string `json:"source"`
+	// Target is the link target as written, without anchors or labels.
+	Target string `json:"target"`
+	// Kind is the syntax the link was written in.
+	Kind LinkKind `json:"kind"`
+	// Resolved is the relative path of the linked note, empty if broken.
+	Resolved string `json:"resolved,omitempty"`
+}
+
+// ExtractLinks returns all internal links in a note, in document order.
+// External URLs and pure anchors are ignored.
+func ExtractLinks(note *Note) []Link {
+	var links []Link
+	source := note.File.Relative
+
+	for _, target := range metadataStrings(note.Metadata, "links") {
+		links = append(links, Link{Source: source, Target: target, Kind: LinkFrontmatter})
+	}
+
+	for _, match := range markdownLinkPattern.FindAllStringSubmatch(note.Content, -1) {
+		target := cleanLinkTarget(match[2])
+		if target == "" || isExternalLink(target) {
+			continue
+		}
+		if decoded, err := url.PathUnescape(target); err == nil {
+			target = decoded
+		}
+		links = append(links, Link{Source: source, Target: target, Kind: LinkMarkdown})
+	}
+
+	for _, match := range wikiLinkPattern.FindAllStringSubmatch(note.Content, -1) {
+		target := match[1]
+		if i := strings.Index(target, "|"); i >= 0 {
+			target = target[:i]
+		}
+		target = cleanLinkTarget(target)
+		if target == "" {
+			continue
+		}
+		links = append(links, Link{Source: source, Target: target, Kind: LinkWiki})
+	}
+
+	return links
+}
+
+// cleanLinkTarget strips anchors and surrounding whitespace from a link target.
+func cleanLinkTarget(target string) string {
+	if i := strings.Index(target, "#"); i >= 0 {
+		target = target[:i]
+	}
+	return strings.TrimSpace(target)
+}
+
+// isExternalLink reports whether a link target points outside the notebook.
+func isExternalLink(target string) bool {
+	return strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:")
+}
+
+// metadataStrings returns a frontmatter field as a list of strings,
+// accepting either a single string or a list.
+func metadataStrings(metadata map[string]any, key string) []string {
+	switch v := metadata[key].(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// NoteAliases returns the alternative names of a note from the "aliases"
+// and "alias" frontmatter fields.
+func NoteAliases(note *Note) []string {
+	return append(metadataStrings(note.Metadata, "aliases"), metadataStrings(note.Metadata, "alias")...)
+}
+
+// noteID returns the stable ID of a note, falling back to its frontmatter
+// for notes that did not come from the index.
+func noteID(note *Note) string {
+	if note.ID != "" {
+		return note.ID
+	}
+	return extractID(note.Metadata)
+}
+
+// LinkGraph indexes the links between all notes of a notebook.
+type LinkGraph struct {
+	paths    map[string]bool
+	ids      map[string]string   // lowercased note ID -> path
+	names    map[string][]string // lowercased stem, basename, title or alias -> paths
+	outgoing map[string][]Link
+	incoming map[string][]Link
+}
+
+// NewLinkGraph builds the link graph for a set of notes.
+func NewLinkGraph(notes []Note) *LinkGraph {
+	g := &LinkGraph{
+		paths:    make(map[string]bool, len(notes)),
+		ids:      make(map[string]string),
+		names:    make(map[string][]string),
+		outgoing: make(map[string][]Link, len(notes)),
+		incoming: make(map[string][]Link),
+	}
+
+	for i := range notes {
+		note := &notes[i]
+		p := note.File.Relative
+		g.paths[p] = true
+		if id := noteID(note); id != "" {
+			g.ids[strings.ToLower(id)] = p
+		}
+
+		stem := strings.TrimSuffix(p, ".md")
+		g.addName(stem, p)
+		g.addName(path.Base(stem), p)
+		if title := extractTitle(note.Metadata); title != "" {
+			g.addName(title, p)
+		}
+		for _, alias := range NoteAliases(note) {
+			g.addName(alias, p)
+		}
+	}
+
+	for i := range notes {
+		note := &notes[i]
+		for _, link := range ExtractLinks(note) {
+			link.Resolved = g.Resolve(link.Source, link.Target, link.Kind)
+			g.outgoing[link.Source] = append(g.outgoing[link.Source], link)
+			if link.Resolved != "" {
+				g.incoming[link.Resolved] = append(g.incoming[link.Resolved], link)
+			}
+		}
+	}
+
+	for target := range g.incoming {
+		links := g.incoming[target]
+		sort.SliceStable(links, func(i, j int) bool { return links[i].Source < links[j].Source })
+	}
+
+	return g
+}
+
+func (g *LinkGraph) addName(name, p string) {
+	key := strings.ToLower(name)
+	for _, existing := range g.names[key] {
+		if existing == p {
+			return
+		}
+	}
+	g.names[key] = append(g.names[key], p)
+}
+
+// Resolve returns the path of the note a link points to, or "" if broken.
+//
+// Markdown links resolve relative to the linking note first, then to the
+// notebook root. Wiki and frontmatter links resolve against the notebook
+// root, then by note ID, then by file name, title or alias. Ambiguous names
+// resolve to the first path in lexical order.
+func (g *LinkGraph) Resolve(source, target string, kind LinkKind) string {
+	candidates := []string{}
+	if kind == LinkMarkdown && !strings.HasPrefix(target, "/") {
+		candidates = append(candidates, path.Join(path.Dir(source), target))
+	}
+	candidates = append(candidates, path.Clean(strings.TrimPrefix(target, "/")))
+
+	for _, c := range candidates {
+		if g.paths[c] {
+			return c
+		}
+		if g.paths[c+".md"] {
+			return c + ".md"
+		}
+	}
+
+	if kind == LinkMarkdown {
+		return ""
+	}
+
+	if p, ok := g.ids[strings.ToLower(target)]; ok {
+		return p
+	}
+
+	matches := g.names[strings.ToLower(strings.TrimSuffix(target, ".md"))]
+	if len(matches) == 0 {
+		return ""
+	}
+	sorted := append([]string(nil), matches...)
+	sort.Strings(sorted)
+	return sorted[0]
+}
+
+// resolvesRelative reports whether a markdown link target resolves relative
+// to the linking note's directory rather than the notebook root.
+func (g *LinkGraph) resolvesRelative(source, target string) bool {
+	candidate := path.Join(path.Dir(source), target)
+	return g.paths[candidate] || g.paths[candidate+".md"]
+}
+
+// Outgoing returns the links written in the note at p.
+func (g *LinkGraph) Outgoing(p string) []Link {
+	return g.outgoing[p]
+}
+
+// Backlinks returns links from other notes pointing to the note at p,
+// ordered by source path.
+func (g *LinkGraph) Backlinks(p string) []Link {
+	var result []Link
+	for _, link := range g.incoming[p] {
+		if link.Source != p {
+			result = append(result, link)
+		}
+	}
+	return result
+}
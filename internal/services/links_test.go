@@ -0,0 +1,124 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testNote(path, content string, metadata map[string]any) Note {
+	note := Note{Content: content, Metadata: metadata}
+	if note.Metadata == nil {
+		note.Metadata = map[string]any{}
+	}
+	note.File.Relative = path
+	note.File.Filepath = path
+	return note
+}
+
+func TestExtractLinks(t *testing.T) {
+	note := testNote("projects/plan.md",
+		"See [spec](spec.md#goals), [[Standup|daily]] and [site](https://example.com).\n[anchor](#top) [[ Retro ]]",
+		map[string]any{"links": []any{"index.md"}})
+
+	links := ExtractLinks(&note)
+	require.Len(t, links, 4)
+
+	assert.Equal(t, Link{Source: "projects/plan.md", Target: "index.md", Kind: LinkFrontmatter}, links[0])
+	assert.Equal(t, Link{Source: "projects/plan.md", Target: "spec.md", Kind: LinkMarkdown}, links[1])
+	assert.Equal(t, Link{Source: "projects/plan.md", Target: "Standup", Kind: LinkWiki}, links[2])
+	assert.Equal(t, Link{Source: "projects/plan.md", Target: "Retro", Kind: LinkWiki}, links[3])
+}
+
+func TestLinkGraph_Resolve(t *testing.T) {
+	notes := []Note{
+		testNote("index.md", "", nil),
+		testNote("projects/plan.md", "", map[string]any{"title": "Project Plan"}),
+		testNote("projects/spec.md", "", nil),
+		testNote("meetings/standup.md", "", map[string]any{"aliases": []any{"daily-sync"}}),
+		testNote("zettel/idea.md", "", map[string]any{"id": 20240501093000}),
+	}
+	notes[1].ID = "01HX3Q5R7M8N9P0QRSTVWXYZ12"
+	g := NewLinkGraph(notes)
+
+	tests := []struct {
+		name     string
+		source   string
+		target   string
+		kind     LinkKind
+		expected string
+	}{
+		{"markdown relative to source", "projects/plan.md", "spec.md", LinkMarkdown, "projects/spec.md"},
+		{"markdown parent dir", "projects/plan.md", "../index.md", LinkMarkdown, "index.md"},
+		{"markdown root fallback", "projects/plan.md", "meetings/standup.md", LinkMarkdown, "meetings/standup.md"},
+		{"markdown without extension", "index.md", "projects/spec", LinkMarkdown, "projects/spec.md"},
+		{"markdown broken", "index.md", "missing.md", LinkMarkdown, ""},
+		{"wiki by file name", "index.md", "standup", LinkWiki, "meetings/standup.md"},
+		{"wiki by title", "index.md", "project plan", LinkWiki, "projects/plan.md"},
+		{"wiki by alias", "index.md", "Daily-Sync", LinkWiki, "meetings/standup.md"},
+		{"wiki by path", "index.md", "projects/spec", LinkWiki, "projects/spec.md"},
+		{"frontmatter path", "index.md", "projects/spec.md", LinkFrontmatter, "projects/spec.md"},
+		{"wiki broken", "index.md", "nowhere", LinkWiki, ""},
+		{"wiki by id", "index.md", "01hx3q5r7m8n9p0qrstvwxyz12", LinkWiki, "projects/plan.md"},
+		{"wiki by unquoted timestamp id", "index.md", "20240501093000", LinkWiki, "zettel/idea.md"},
+		{"frontmatter by id", "index.md", "01HX3Q5R7M8N9P0QRSTVWXYZ12", LinkFrontmatter, "projects/plan.md"},
+		{"markdown does not resolve ids", "index.md", "20240501093000", LinkMarkdown, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, g.Resolve(tt.source, tt.target, tt.kind))
+		})
+	}
+}
+
+func TestLinkGraph_Backlinks(t *testing.T) {
+	notes := []Note{
+		testNote("index.md", "[[plan]] and [plan again](projects/plan.md)", nil),
+		testNote("projects/plan.md", "Self link [[plan]]", nil),
+		testNote("projects/spec.md", "Back to [plan](plan.md)", nil),
+	}
+	g := NewLinkGraph(notes)
+
+	backlinks := g.Backlinks("projects/plan.md")
+	require.Len(t, backlinks, 3)
+	assert.Equal(t, "index.md", backlinks[0].Source)
+	assert.Equal(t, "index.md", backlinks[1].Source)
+	assert.Equal(t, "projects/spec.md", backlinks[2].Source)
+
+	assert.Empty(t, g.Backlinks("projects/spec.md"))
+	assert.Len(t, g.Outgoing("index.md"), 2)
+}
+
+func TestRelatedNotes(t *testing.T) {
+	note := testNote("a.md", "", map[string]any{"tags": []any{"work", "go"}})
+	notes := []Note{
+		note,
+		testNote("b.md", "", map[string]any{"tags": "work"}),
+		testNote("c.md", "", map[string]any{"tags": []any{"go", "Work"}}),
+		testNote("d.md", "", map[string]any{"tags": []any{"home"}}),
+	}
+
+	related := relatedNotes(&note, notes, 5)
+	require.Len(t, related, 2)
+	assert.Equal(t, "c.md", related[0].Path)
+	assert.Equal(t, []string{"go", "Work"}, related[0].SharedTags)
+	assert.Equal(t, "b.md", related[1].Path)
+
+	assert.Len(t, relatedNotes(&note, notes, 1), 1)
+}
+
+func TestFrontmatterFields(t *testing.T) {
+	fields, err := frontmatterFields([]byte("---\nzeta: 1\ntags: [a, b]\ntitle: Hi\n---\nbody"))
+	require.NoError(t, err)
+	assert.Equal(t, []FrontmatterField{
+		{Key: "zeta", Value: "1"},
+		{Key: "tags", Value: "a, b"},
+		{Key: "title", Value: "Hi"},
+	}, fields)
+
+	fields, err = frontmatterFields([]byte("no frontmatter"))
+	require.NoError(t, err)
+	assert.Empty(t, fields)
+}
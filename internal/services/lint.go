@@ -0,0 +1,375 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+// Lint severities, from most to least severe.
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+	LintInfo    = "info"
+)
+
+// LintSeverityRank orders severities so a threshold can be compared: higher
+// is more severe. Unknown severities rank 0.
+func LintSeverityRank(severity string) int {
+	switch severity {
+	case LintError:
+		return 3
+	case LintWarning:
+		return 2
+	case LintInfo:
+		return 1
+	}
+	return 0
+}
+
+// LintRule describes a check run by Lint.
+type LintRule struct {
+	ID          string `json:"id"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Fixable     bool   `json:"fixable"`
+}
+
+// LintRules lists every lint rule.
+var LintRules = []LintRule{
+	{ID: "broken-link", Severity: LintError, Description: "Link to a note that does not exist"},
+	{ID: "invalid-frontmatter", Severity: LintError, Description: "Frontmatter that cannot be parsed as YAML"},
+	{ID: "missing-title", Severity: LintWarning, Description: "Note without a title field", Fixable: true},
+	{ID: "duplicate-title", Severity: LintWarning, Description: "Title shared by several notes"},
+	{ID: "mixed-tag-keys", Severity: LintWarning, Description: `"tag" used where the notebook also uses "tags"`, Fixable: true},
+	{ID: "date-format", Severity: LintWarning, Description: "Date that is not RFC 3339, which jot ignores", Fixable: true},
+	{ID: "ungrouped", Severity: LintInfo, Description: "Note outside every notebook group"},
+}
+
+// LintRuleByID returns the rule with id.
+func LintRuleByID(id string) (LintRule, bool) {
+	for _, rule := range LintRules {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return LintRule{}, false
+}
+
+// LintFinding is a problem found by Lint.
+type LintFinding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+	Fixable  bool   `json:"fixable"`
+
+	// fix rewrites the note content to resolve the finding
+	fix func(content []byte) ([]byte, error)
+}
+
+// lintNote is a note as read for linting.
+type lintNote struct {
+	note     Note
+	content  []byte
+	metadata map[string]any
+	parseErr *FrontmatterError
+}
+
+// Lint checks every note in the notebook against the rules in ids, or all
+// rules when ids is empty. Findings are ordered by path, line and severity.
+func (n *Notebook) Lint(ctx context.Context, ids []string) ([]LintFinding, error) {
+	enabled := make(map[string]bool, len(LintRules))
+	for _, id := range ids {
+		if _, ok := LintRuleByID(id); !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", id)
+		}
+		enabled[id] = true
+	}
+	if len(ids) == 0 {
+		for _, rule := range LintRules {
+			enabled[rule.ID] = true
+		}
+	}
+
+	notes, err := n.Notes.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortNotesByPath(notes)
+
+	var linted []lintNote
+	for _, note := range notes {
+		content, err := n.Storage.Read(note.File.Relative)
+		if errors.Is(err, search.ErrEncrypted) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", note.File.Relative, err)
+		}
+
+		ln := lintNote{note: note, content: content}
+		ln.metadata, _, err = parseFrontmatterStrict(content)
+		if err != nil {
+			errors.As(err, &ln.parseErr)
+			ln.metadata = map[string]any{}
+		}
+		linted = append(linted, ln)
+	}
+
+	var findings []LintFinding
+	add := func(ruleID string, ln lintNote, line int, message string, fix func([]byte) ([]byte, error)) {
+		if !enabled[ruleID] {
+			return
+		}
+		rule, _ := LintRuleByID(ruleID)
+		findings = append(findings, LintFinding{
+			Rule:     rule.ID,
+			Severity: rule.Severity,
+			Path:     ln.note.File.Relative,
+			Line:     line,
+			Message:  message,
+			Fixable:  fix != nil,
+			fix:      fix,
+		})
+	}
+
+	graph := NewLinkGraph(notes)
+	executor := NewSpecialViewExecutor(n.Notes)
+	usesTag, usesTags := false, false
+	for _, ln := range linted {
+		usesTag = usesTag || ln.metadata["tag"] != nil
+		usesTags = usesTags || ln.metadata["tags"] != nil
+	}
+	titles := make(map[string][]lintNote)
+
+	for _, ln := range linted {
+		if ln.parseErr != nil {
+			add("invalid-frontmatter", ln, ln.parseErr.Line, ln.parseErr.Message, nil)
+		}
+
+		for _, link := range executor.findBrokenLinks(&ln.note, graph) {
+			add("broken-link", ln, lineContaining(ln.content, link), fmt.Sprintf("link to %q does not resolve to a note", link), nil)
+		}
+
+		if len(n.Config.Groups) > 0 && len(n.Config.MatchGroups(ln.note.File.Relative)) == 0 {
+			add("ungrouped", ln, 1, "note matches no notebook group", nil)
+		}
+
+		// The remaining rules read frontmatter, which is unknown when it
+		// cannot be parsed
+		if ln.parseErr != nil {
+			continue
+		}
+
+		title := extractTitle(ln.metadata)
+		if title == "" {
+			heading, line := firstHeading(ln.content)
+			message := "note has no title"
+			var fix func([]byte) ([]byte, error)
+			if heading != "" {
+				message = fmt.Sprintf("note has no title (heading on line %d: %q)", line, heading)
+				fix = setFrontmatterFix(map[string]any{"title": heading}, nil)
+			}
+			add("missing-title", ln, 1, message, fix)
+			title = heading
+		}
+		if title != "" {
+			key := strings.ToLower(strings.TrimSpace(title))
+			titles[key] = append(titles[key], ln)
+		}
+
+		if usesTag && usesTags && ln.metadata["tag"] != nil {
+			add("mixed-tag-keys", ln, frontmatterKeyLine(ln.content, "tag"),
+				`use "tags" like the rest of the notebook instead of "tag"`, mergeTagKeysFix)
+		}
+
+		for _, field := range dateFields(n.Config.FieldSchemas(ln.note.File.Relative)) {
+			value, ok := ln.metadata[field].(string)
+			if !ok || value == "" {
+				continue
+			}
+			if _, err := time.Parse(time.RFC3339, value); err == nil {
+				continue
+			}
+			message := fmt.Sprintf("%s %q is not an RFC 3339 date", field, value)
+			var fix func([]byte) ([]byte, error)
+			if parsed, ok := parseLooseDate(value); ok {
+				fixed := parsed.Format(time.RFC3339)
+				message += fmt.Sprintf(" (fix: %s)", fixed)
+				fix = setFrontmatterFix(map[string]any{field: fixed}, nil)
+			}
+			add("date-format", ln, frontmatterKeyLine(ln.content, field), message, fix)
+		}
+	}
+
+	for _, group := range titles {
+		if len(group) < 2 {
+			continue
+		}
+		for _, ln := range group {
+			var others []string
+			for _, other := range group {
+				if other.note.File.Relative != ln.note.File.Relative {
+					others = append(others, other.note.File.Relative)
+				}
+			}
+			title, line := extractTitle(ln.metadata), frontmatterKeyLine(ln.content, "title")
+			if title == "" {
+				title, line = firstHeading(ln.content)
+			}
+			add("duplicate-title", ln, line, fmt.Sprintf("title %q is also used by %s", title, strings.Join(others, ", ")), nil)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return LintSeverityRank(findings[i].Severity) > LintSeverityRank(findings[j].Severity)
+	})
+	return findings, nil
+}
+
+// PlanLintFixes plans the changes that resolve the fixable findings, one per
+// note. Like the ID backfill, fixes leave "modified" alone.
+func (n *Notebook) PlanLintFixes(findings []LintFinding) ([]*NoteChange, error) {
+	byPath := make(map[string][]LintFinding)
+	var paths []string
+	for _, finding := range findings {
+		if finding.fix == nil {
+			continue
+		}
+		if _, ok := byPath[finding.Path]; !ok {
+			paths = append(paths, finding.Path)
+		}
+		byPath[finding.Path] = append(byPath[finding.Path], finding)
+	}
+	sort.Strings(paths)
+
+	var changes []*NoteChange
+	for _, relPath := range paths {
+		content, err := n.Storage.Read(relPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+
+		fixed := content
+		for _, finding := range byPath[relPath] {
+			if fixed, err = finding.fix(fixed); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", relPath, finding.Rule, err)
+			}
+		}
+		if bytes.Equal(content, fixed) {
+			continue
+		}
+
+		changes = append(changes, &NoteChange{
+			Path:    relPath,
+			OldPath: relPath,
+			Before:  string(content),
+			After:   string(fixed),
+		})
+	}
+	return changes, nil
+}
+
+// dateFields returns the frontmatter fields holding RFC 3339 dates: created,
+// modified and fields the notebook schema types as datetime.
+func dateFields(schemas map[string]FieldSchema) []string {
+	fields := []string{"created", "modified"}
+	for _, name := range sortedKeys(schemas) {
+		if schemas[name].Type == FieldTypeDatetime && name != "created" && name != "modified" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// looseDateLayouts are date formats the date-format fix understands.
+var looseDateLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04",
+	"2006/01/02",
+	time.RFC1123Z,
+	time.RFC1123,
+	"2 Jan 2006",
+	"January 2, 2006",
+}
+
+// parseLooseDate parses value with looseDateLayouts, in local time when it
+// has no zone.
+func parseLooseDate(value string) (time.Time, bool) {
+	for _, layout := range looseDateLayouts {
+		if t, err := time.ParseInLocation(layout, strings.TrimSpace(value), time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// setFrontmatterFix returns a fix that sets and removes frontmatter fields.
+func setFrontmatterFix(set map[string]any, unset []string) func([]byte) ([]byte, error) {
+	return func(content []byte) ([]byte, error) {
+		return UpdateFrontmatter(content, set, unset)
+	}
+}
+
+// mergeTagKeysFix moves the "tag" field into the "tags" list.
+func mergeTagKeysFix(content []byte) ([]byte, error) {
+	metadata, _ := parseFrontmatter(content)
+	return UpdateFrontmatter(content, map[string]any{"tags": metadataTags(metadata)}, []string{"tag"})
+}
+
+// frontmatterKeyLine returns the line of a top-level frontmatter key, or 1.
+func frontmatterKeyLine(content []byte, key string) int {
+	lines := strings.Split(string(content), "\n")
+	for i := 1; i < len(lines) && lines[i] != "---"; i++ {
+		if strings.HasPrefix(lines[i], key+":") {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// lineContaining returns the first line of content containing s, or 1.
+func lineContaining(content []byte, s string) int {
+	for i, line := range strings.Split(string(content), "\n") {
+		if strings.Contains(line, s) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// firstHeading returns the text and line of the first level one heading
+// after the frontmatter.
+func firstHeading(content []byte) (string, int) {
+	lines := strings.Split(string(content), "\n")
+	start := 0
+	if _, _, ok := splitFrontmatter(content); ok {
+		for start = 1; lines[start] != "---"; start++ {
+		}
+	}
+	for i := start; i < len(lines); i++ {
+		if heading, ok := strings.CutPrefix(lines[i], "# "); ok {
+			return strings.TrimSpace(heading), i + 1
+		}
+	}
+	return "", 1
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+var lintNotes = map[string]string{
+	"plan.md": "---\ntitle: Plan\ntags: [work]\n---\n\n# Plan\n\nSee [[ghost]].\n",
+	"copy.md": "---\n# legacy key\ntag: old\ncreated: \"2024-05-01 09:30\"\n---\n\n# Plan\n",
+	"bad.md":  "---\ntitle: ok\nstatus: x: y\n---\n\nbody\n",
+	"bare.md": "no title here\n",
+}
+
+var lintConfig = services.NotebookConfig{StoredNotebookConfig: services.StoredNotebookConfig{
+	Groups: []services.NotebookGroup{{Name: "Plans", Globs: []string{"notes/plan.md", "notes/copy.md"}}},
+}}
+
+func formatFindings(findings []services.LintFinding) []string {
+	lines := make([]string, len(findings))
+	for i, f := range findings {
+		lines[i] = fmt.Sprintf("%s:%d %s %s", f.Path, f.Line, f.Severity, f.Rule)
+	}
+	return lines
+}
+
+func TestNotebook_Lint(t *testing.T) {
+	svc, notebookDir := testutil.NewNoteService(t, lintNotes)
+	nb := &services.Notebook{Config: lintConfig, Notes: svc, Storage: bleve.OsStorage(notebookDir)}
+
+	findings, err := nb.Lint(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"notes/bad.md:1 info ungrouped",
+		"notes/bad.md:3 error invalid-frontmatter",
+		"notes/bare.md:1 warning missing-title",
+		"notes/bare.md:1 info ungrouped",
+		"notes/copy.md:1 warning missing-title",
+		"notes/copy.md:3 warning mixed-tag-keys",
+		"notes/copy.md:4 warning date-format",
+		"notes/copy.md:7 warning duplicate-title",
+		"notes/plan.md:2 warning duplicate-title",
+		"notes/plan.md:8 error broken-link",
+	}, formatFindings(findings))
+}
+
+func TestNotebook_LintBrokenLinksResolveLikeBacklinks(t *testing.T) {
+	ctx := context.Background()
+	files := maps.Clone(lintNotes)
+	files["docs/target.md"] = "---\ntitle: Target\n---\n"
+	files["docs/b.md"] = "---\ntitle: B\n---\n[t](target.md) [[Target]] [[target|x]] [[notes/docs/target#top]] [[nowhere|x]]\n"
+	svc, notebookDir := testutil.NewNoteService(t, files)
+	nb := &services.Notebook{Config: lintConfig, Notes: svc, Storage: bleve.OsStorage(notebookDir)}
+
+	findings, err := nb.Lint(ctx, []string{"broken-link"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes/docs/b.md:4 error broken-link", "notes/plan.md:8 error broken-link"}, formatFindings(findings))
+	assert.Equal(t, `link to "nowhere" does not resolve to a note`, findings[0].Message)
+}
+
+func TestNotebook_LintRulesAndFixes(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, lintNotes)
+	nb := &services.Notebook{Config: lintConfig, Notes: svc, Storage: bleve.OsStorage(notebookDir)}
+
+	_, err := nb.Lint(ctx, []string{"spelling"})
+	assert.ErrorContains(t, err, `unknown lint rule "spelling"`)
+
+	findings, err := nb.Lint(ctx, []string{"missing-title", "mixed-tag-keys", "date-format"})
+	require.NoError(t, err)
+	require.Len(t, findings, 4)
+	assert.False(t, findings[0].Fixable, "a note without a heading has no title to add")
+
+	changes, err := nb.PlanLintFixes(findings)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "notes/copy.md", changes[0].Path)
+	assert.Contains(t, changes[0].After, "title: Plan\n")
+	assert.Contains(t, changes[0].After, "tags:\n  - old\n")
+	assert.NotContains(t, changes[0].After, "tag: old")
+	assert.Regexp(t, `created: "2024-05-01T09:30:00`, changes[0].After)
+	assert.NotContains(t, changes[0].After, "modified:")
+
+	require.NoError(t, nb.Notes.ApplyChange(ctx, changes[0]))
+	findings, err = nb.Lint(ctx, []string{"missing-title", "mixed-tag-keys", "date-format"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"notes/bare.md:1 warning missing-title"}, formatFindings(findings))
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NoteChange is a planned rewrite of a single note.
+type NoteChange struct {
+	// Path is where the note lives after the operation.
+	Path string
+	// OldPath is where the note lived before; it differs from Path only for a moved note.
+	OldPath string
+	// Before and After are the full note contents.
+	Before string
+	After  string
+}
+
+// MovePlan describes moving a note and the link rewrites that keep other
+// notes pointing at it.
+type MovePlan struct {
+	From string
+	To   string
+	// Changes lists notes whose content changes, ordered by path.
+	Changes []NoteChange
+}
+
+// PlanMove computes the effect of moving the note at from to to without
+// writing anything. Every markdown link, wikilink and frontmatter "links"
+// entry that resolves to the moved note is rewritten in the style it was
+// written in. Relative links inside the moved note itself are adjusted for
+// its new directory.
+func (s *NoteService) PlanMove(ctx context.Context, from, to string) (*MovePlan, error) {
+	from = filepath.ToSlash(from)
+	to = path.Clean(filepath.ToSlash(to))
+
+	if from == to {
+		return nil, fmt.Errorf("source and destination are the same: %s", from)
+	}
+	if strings.HasPrefix(to, "../") || strings.HasPrefix(to, "/") {
+		return nil, fmt.Errorf("destination is outside the notebook: %s", to)
+	}
+	if !s.storage.Exists(from) {
+		return nil, fmt.Errorf("note not found: %s", from)
+	}
+	if s.storage.Exists(to) {
+		return nil, fmt.Errorf("destination already exists: %s", to)
+	}
+
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortNotesByPath(notes)
+
+	moved := make([]Note, len(notes))
+	copy(moved, notes)
+	for i := range moved {
+		if moved[i].File.Relative == from {
+			moved[i].File.Relative = to
+			moved[i].File.Filepath = to
+		}
+	}
+
+	rw := &linkRewriter{
+		before: NewLinkGraph(notes),
+		after:  NewLinkGraph(moved),
+		from:   from,
+		to:     to,
+	}
+
+	plan := &MovePlan{From: from, To: to}
+	for _, note := range notes {
+		oldPath := note.File.Relative
+		newPath := oldPath
+		if oldPath == from {
+			newPath = to
+		}
+
+		content, err := s.storage.Read(oldPath)
+		if err != nil {
+			s.log.Warn().Err(err).Str("path", oldPath).Msg("skipping note while rewriting links")
+			continue
+		}
+
+		rewritten, err := rw.rewrite(string(content), oldPath, newPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to rewrite links in %s: %w", oldPath, err)
+		}
+		if rewritten != string(content) {
+			plan.Changes = append(plan.Changes, NoteChange{
+				Path:    newPath,
+				OldPath: oldPath,
+				Before:  string(content),
+				After:   rewritten,
+			})
+		}
+	}
+
+	sort.Slice(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Path < plan.Changes[j].Path
+	})
+
+	return plan, nil
+}
+
+// CheckMoves validates a batch of moves, keyed by source path, before any
+// of them is planned or applied, so a bulk move can't stop halfway: every
+// destination must be inside the notebook, free, and not shared with
+// another note of the batch.
+func (s *NoteService) CheckMoves(moves map[string]string) error {
+	froms := make([]string, 0, len(moves))
+	for from := range moves {
+		froms = append(froms, from)
+	}
+	sort.Strings(froms)
+
+	claimed := make(map[string]string, len(moves))
+	for _, from := range froms {
+		to := path.Clean(filepath.ToSlash(moves[from]))
+		if other, ok := claimed[to]; ok {
+			return fmt.Errorf("%s and %s would both move to %s", other, from, to)
+		}
+		claimed[to] = from
+		if strings.HasPrefix(to, "../") || strings.HasPrefix(to, "/") {
+			return fmt.Errorf("destination is outside the notebook: %s", to)
+		}
+		if s.storage.Exists(to) {
+			return fmt.Errorf("destination already exists: %s", to)
+		}
+	}
+	return nil
+}
+
+// ApplyMove writes the link rewrites, renames the note and updates the index.
+func (s *NoteService) ApplyMove(ctx context.Context, plan *MovePlan) error {
+	var movedContent *NoteChange
+	for i := range plan.Changes {
+		change := &plan.Changes[i]
+		if change.OldPath == plan.From {
+			movedContent = change
+			continue
+		}
+		if err := s.storage.Write(change.Path, []byte(change.After)); err != nil {
+			return fmt.Errorf("failed to update %s: %w", change.Path, err)
+		}
+	}
+
+	if err := s.storage.Rename(plan.From, plan.To); err != nil {
+		return fmt.Errorf("failed to move note: %w", err)
+	}
+	if movedContent != nil {
+		if err := s.storage.Write(plan.To, []byte(movedContent.After)); err != nil {
+			return fmt.Errorf("failed to update %s: %w", plan.To, err)
+		}
+	}
+
+	if s.index != nil {
+		if err := s.index.Remove(ctx, plan.From); err != nil {
+			s.log.Warn().Err(err).Str("path", plan.From).Msg("failed to remove moved note from index")
+		}
+		if err := s.IndexFile(ctx, plan.To); err != nil {
+			s.log.Warn().Err(err).Str("path", plan.To).Msg("failed to index moved note")
+		}
+		for _, change := range plan.Changes {
+			if change.Path == plan.To {
+				continue
+			}
+			if err := s.IndexFile(ctx, change.Path); err != nil {
+				s.log.Warn().Err(err).Str("path", change.Path).Msg("failed to re-index note")
+			}
+		}
+	}
+
+	return nil
+}
+
+// linkRewriter rewrites links so they resolve in the "after" graph to the
+// same notes they resolved to in the "before" graph.
+type linkRewriter struct {
+	before *LinkGraph
+	after  *LinkGraph
+	from   string
+	to     string
+}
+
+// want maps a link's original resolution to where that note lives after the move.
+func (rw *linkRewriter) want(resolved string) string {
+	if resolved == rw.from {
+		return rw.to
+	}
+	return resolved
+}
+
+// rewrite returns content with links fixed for a note moving from oldSource
+// to newSource (identical for notes that stay in place).
+func (rw *linkRewriter) rewrite(content, oldSource, newSource string) (string, error) {
+	frontmatter, body, ok := splitFrontmatter([]byte(content))
+
+	newBody := rw.rewriteMarkdown(string(body), oldSource, newSource)
+	newBody = rw.rewriteWiki(newBody, oldSource, newSource)
+
+	if !ok {
+		return newBody, nil
+	}
+
+	newFrontmatter, err := rw.rewriteFrontmatter(string(frontmatter), oldSource, newSource)
+	if err != nil {
+		return "", err
+	}
+
+	return "---\n" + newFrontmatter + "---\n" + newBody, nil
+}
+
+func (rw *linkRewriter) rewriteMarkdown(body, oldSource, newSource string) string {
+	matches := markdownLinkPattern.FindAllStringSubmatchIndex(body, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][4], matches[i][5]
+		raw := body[start:end]
+
+		target := cleanLinkTarget(raw)
+		if target == "" || isExternalLink(target) {
+			continue
+		}
+		decoded := target
+		if d, err := url.PathUnescape(target); err == nil {
+			decoded = d
+		}
+
+		resolved := rw.before.Resolve(oldSource, decoded, LinkMarkdown)
+		if resolved == "" {
+			continue
+		}
+		want := rw.want(resolved)
+		if rw.markdownStillResolves(decoded, oldSource, newSource, want) {
+			continue
+		}
+
+		body = body[:start] + rw.markdownTarget(raw, decoded, oldSource, newSource, want) + body[end:]
+	}
+	return body
+}
+
+// markdownStillResolves reports whether a markdown link needs no rewrite.
+// Links written relative to the note must keep resolving relatively, since
+// other markdown renderers don't fall back to the notebook root.
+func (rw *linkRewriter) markdownStillResolves(decoded, oldSource, newSource, want string) bool {
+	if !strings.HasPrefix(decoded, "/") && rw.before.resolvesRelative(oldSource, decoded) {
+		candidate := path.Join(path.Dir(newSource), decoded)
+		return candidate == want || candidate+".md" == want
+	}
+	return rw.after.Resolve(newSource, decoded, LinkMarkdown) == want
+}
+
+// markdownTarget formats a new markdown link target in the style of the
+// original: relative or root-based, with or without .md, escaped or not,
+// keeping any #anchor.
+func (rw *linkRewriter) markdownTarget(raw, decoded, oldSource, newSource, want string) string {
+	anchor := ""
+	if i := strings.Index(raw, "#"); i >= 0 {
+		anchor = raw[i:]
+	}
+
+	var newPath string
+	switch {
+	case strings.HasPrefix(decoded, "/"):
+		newPath = "/" + want
+	case rw.before.resolvesRelative(oldSource, decoded):
+		newPath = relativeLink(path.Dir(newSource), want)
+	default:
+		newPath = want
+	}
+
+	if !strings.HasSuffix(decoded, ".md") {
+		newPath = strings.TrimSuffix(newPath, ".md")
+	}
+	if strings.Contains(raw, "%") || strings.Contains(newPath, " ") {
+		newPath = (&url.URL{Path: newPath}).EscapedPath()
+	}
+
+	return newPath + anchor
+}
+
+func (rw *linkRewriter) rewriteWiki(body, oldSource, newSource string) string {
+	matches := wikiLinkPattern.FindAllStringSubmatchIndex(body, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		start, end := matches[i][2], matches[i][3]
+		inner := body[start:end]
+
+		// Split "target#heading|label" into the target and the suffix to keep
+		cut := len(inner)
+		if j := strings.IndexAny(inner, "#|"); j >= 0 {
+			cut = j
+		}
+		rawTarget, suffix := inner[:cut], inner[cut:]
+		target := strings.TrimSpace(rawTarget)
+		if target == "" {
+			continue
+		}
+
+		resolved := rw.before.Resolve(oldSource, target, LinkWiki)
+		if resolved == "" {
+			continue
+		}
+		want := rw.want(resolved)
+		if rw.after.Resolve(newSource, target, LinkWiki) == want {
+			continue
+		}
+
+		body = body[:start] + rw.nameTarget(target, newSource, want, LinkWiki) + suffix + body[end:]
+	}
+	return body
+}
+
+// nameTarget picks the shortest wikilink or frontmatter target that resolves
+// to want: the bare file name when the original was a bare name, otherwise
+// the path from the notebook root.
+func (rw *linkRewriter) nameTarget(original, newSource, want string, kind LinkKind) string {
+	keepExt := strings.HasSuffix(original, ".md")
+	format := func(p string) string {
+		if keepExt {
+			return p
+		}
+		return strings.TrimSuffix(p, ".md")
+	}
+
+	if !strings.Contains(original, "/") {
+		name := format(path.Base(want))
+		if rw.after.Resolve(newSource, name, kind) == want {
+			return name
+		}
+	}
+	return format(want)
+}
+
+func (rw *linkRewriter) rewriteFrontmatter(frontmatter, oldSource, newSource string) (string, error) {
+	if !strings.Contains(frontmatter, "links") {
+		return frontmatter, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(frontmatter), &doc); err != nil {
+		// Broken frontmatter is left alone; it can't contain usable links
+		return frontmatter, nil
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return frontmatter, nil
+	}
+
+	mapping := doc.Content[0]
+	changed := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != "links" {
+			continue
+		}
+
+		value := mapping.Content[i+1]
+		entries := []*yaml.Node{value}
+		if value.Kind == yaml.SequenceNode {
+			entries = value.Content
+		}
+
+		for _, entry := range entries {
+			if entry.Kind != yaml.ScalarNode || entry.Value == "" {
+				continue
+			}
+			resolved := rw.before.Resolve(oldSource, entry.Value, LinkFrontmatter)
+			if resolved == "" {
+				continue
+			}
+			want := rw.want(resolved)
+			if rw.after.Resolve(newSource, entry.Value, LinkFrontmatter) == want {
+				continue
+			}
+			entry.Value = rw.nameTarget(entry.Value, newSource, want, LinkFrontmatter)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return frontmatter, nil
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", err
+	}
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// relativeLink returns target relative to the directory dir, both relative
+// to the notebook root.
+func relativeLink(dir, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(dir), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
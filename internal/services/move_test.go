@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+var moveNotes = map[string]string{
+	"plan.md":  "---\ntitle: Plan\n---\n\nSee [spec](projects/spec.md#goals) and [[standup]], [[retro]].\n",
+	"retro.md": "# Retro\n",
+	"standup.md": "---\ntitle: Standup\nlinks:\n  - notes/projects/spec.md # keep\n---\n\n" +
+		"[[Spec Doc|the spec]] and [[notes/projects/spec]].\n",
+	"projects/spec.md": "---\ntitle: Spec Doc\n---\n\nBack to [plan](../plan.md).\n",
+}
+
+func TestNoteService_PlanMove(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, moveNotes)
+
+	plan, err := svc.PlanMove(ctx, "notes/projects/spec.md", "notes/archive/spec-v1.md")
+	require.NoError(t, err)
+
+	changes := map[string]services.NoteChange{}
+	for _, c := range plan.Changes {
+		changes[c.Path] = c
+	}
+	require.Len(t, changes, 2)
+
+	assert.Contains(t, changes["notes/plan.md"].After, "[spec](archive/spec-v1.md#goals) and [[standup]]")
+
+	standup := changes["notes/standup.md"].After
+	assert.Contains(t, standup, "- notes/archive/spec-v1.md # keep")
+	// Title links still resolve after the move and are left alone
+	assert.Contains(t, standup, "[[Spec Doc|the spec]]")
+	assert.Contains(t, standup, "[[notes/archive/spec-v1]]")
+
+	// "../plan.md" still resolves from the new directory
+	assert.NotContains(t, changes, "notes/archive/spec-v1.md")
+}
+
+func TestNoteService_PlanMove_RelativeLinksInMovedNote(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, moveNotes)
+
+	plan, err := svc.PlanMove(ctx, "notes/projects/spec.md", "notes/spec.md")
+	require.NoError(t, err)
+
+	for _, c := range plan.Changes {
+		if c.Path == "notes/spec.md" {
+			assert.Contains(t, c.After, "Back to [plan](plan.md).")
+			return
+		}
+	}
+	t.Fatal("moved note was not rewritten")
+}
+
+func TestNoteService_PlanMove_BareWikiNameStaysShort(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, moveNotes)
+
+	plan, err := svc.PlanMove(ctx, "notes/retro.md", "notes/projects/retro-2024.md")
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "notes/plan.md", plan.Changes[0].Path)
+	assert.Contains(t, plan.Changes[0].After, "[[standup]], [[retro-2024]].")
+}
+
+func TestNoteService_PlanMove_Errors(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, moveNotes)
+
+	_, err := svc.PlanMove(ctx, "notes/missing.md", "notes/other.md")
+	assert.ErrorContains(t, err, "note not found")
+
+	_, err = svc.PlanMove(ctx, "notes/plan.md", "notes/standup.md")
+	assert.ErrorContains(t, err, "destination already exists")
+
+	_, err = svc.PlanMove(ctx, "notes/plan.md", "../escape.md")
+	assert.ErrorContains(t, err, "outside the notebook")
+}
+
+func TestNoteService_CheckMoves(t *testing.T) {
+	svc, _ := testutil.NewNoteService(t, moveNotes)
+
+	assert.NoError(t, svc.CheckMoves(map[string]string{
+		"notes/plan.md":  "notes/archive/plan.md",
+		"notes/retro.md": "notes/archive/retro.md",
+	}))
+
+	err := svc.CheckMoves(map[string]string{
+		"notes/plan.md":          "notes/archive/spec.md",
+		"notes/projects/spec.md": "notes/archive/spec.md",
+	})
+	assert.ErrorContains(t, err, "notes/plan.md and notes/projects/spec.md would both move to notes/archive/spec.md")
+
+	err = svc.CheckMoves(map[string]string{"notes/projects/spec.md": "notes/plan.md"})
+	assert.ErrorContains(t, err, "destination already exists: notes/plan.md")
+
+	err = svc.CheckMoves(map[string]string{"notes/plan.md": "../plan.md"})
+	assert.ErrorContains(t, err, "outside the notebook")
+}
+
+func TestNoteService_ApplyMove(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, moveNotes)
+
+	plan, err := svc.PlanMove(ctx, "notes/projects/spec.md", "notes/archive/spec-v1.md")
+	require.NoError(t, err)
+	require.NoError(t, svc.ApplyMove(ctx, plan))
+
+	assert.NoFileExists(t, filepath.Join(notebookDir, "notes", "projects", "spec.md"))
+	assert.FileExists(t, filepath.Join(notebookDir, "notes", "archive", "spec-v1.md"))
+
+	content, err := os.ReadFile(filepath.Join(notebookDir, "notes", "plan.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "archive/spec-v1.md#goals")
+
+	notes, err := svc.ResolveNote(ctx, "notes/archive/spec-v1.md")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+
+	detail, err := svc.Detail(ctx, "notes/archive/spec-v1.md")
+	require.NoError(t, err)
+	assert.Len(t, detail.Backlinks, 2)
+}
+
+func TestNoteService_ApplyMove_LeavesNoBrokenLinks(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, moveNotes)
+	nb := &services.Notebook{Notes: svc, Storage: bleve.OsStorage(notebookDir)}
+
+	plan, err := svc.PlanMove(ctx, "notes/plan.md", "notes/archive/2024/plan.md")
+	require.NoError(t, err)
+	require.NoError(t, svc.ApplyMove(ctx, plan))
+
+	moved, err := os.ReadFile(filepath.Join(notebookDir, "notes", "archive", "2024", "plan.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(moved), "[spec](../../projects/spec.md#goals)")
+
+	findings, err := nb.Lint(ctx, []string{"broken-link"})
+	require.NoError(t, err)
+	assert.Empty(t, findings, "links rewritten by a move resolve")
+}
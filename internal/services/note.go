@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/rs/zerolog"
+	"github.com/sahilm/fuzzy"
 	"github.com/zenobi-us/jot/internal/core"
 	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/bleve"
 )
 
 // Note represents a markdown note.
@@ -18,8 +21,12 @@ type Note struct {
 		Filepath string `json:"filepath"`
 		Relative string `json:"relative"`
 	} `json:"file"`
+	// ID is the stable note ID from the "id" frontmatter field, if any.
+	ID       string         `json:"id,omitempty"`
 	Content  string         `json:"content"`
 	Metadata map[string]any `json:"metadata"`
+	// Tasks are the note's task list items as indexed.
+	Tasks []search.Task `json:"-"`
 }
 
 // DisplayName returns the display name for the note.
@@ -51,6 +58,8 @@ func documentToNote(doc search.Document) Note {
 
 	note.File.Relative = doc.Path
 	note.File.Filepath = doc.Path // Note: In index, Path is already relative
+	note.ID = doc.ID
+	note.Tasks = doc.Tasks
 
 	// Map Document metadata back to Note metadata
 	if doc.Title != "" {
@@ -80,8 +89,10 @@ func documentToNote(doc search.Document) Note {
 type NoteService struct {
 	configService *ConfigService
 	index         search.Index
+	storage       search.Storage
 	semanticIndex SemanticIndex
 	searchService *SearchService
+	commitTimes   map[string]CommitTimes
 	notebookPath  string
 	log           zerolog.Logger
 }
@@ -91,6 +102,7 @@ func NewNoteService(cfg *ConfigService, index search.Index, notebookPath string)
 	return &NoteService{
 		configService: cfg,
 		index:         index,
+		storage:       bleve.OsStorage(notebookPath),
 		semanticIndex: NewNoopSemanticIndex(),
 		searchService: NewSearchService(),
 		notebookPath:  notebookPath,
@@ -108,6 +120,114 @@ func (s *NoteService) SetSemanticIndex(idx SemanticIndex) {
 	s.semanticIndex = idx
 }
 
+// SetStorage configures the storage backend notes are read from and written to.
+// Passing nil resets to the local filesystem under the notebook path.
+func (s *NoteService) SetStorage(storage search.Storage) {
+	if storage == nil {
+		s.storage = bleve.OsStorage(s.notebookPath)
+		return
+	}
+	s.storage = storage
+}
+
+// SetCommitTimes sets the first and last commit times of notes, used for
+// created/modified when the frontmatter has none.
+func (s *NoteService) SetCommitTimes(times map[string]CommitTimes) {
+	s.commitTimes = times
+}
+
+// Storage returns the storage backend for this notebook.
+func (s *NoteService) Storage() search.Storage {
+	return s.storage
+}
+
+// IndexFile reads a note from storage and adds or replaces it in the index.
+func (s *NoteService) IndexFile(ctx context.Context, relPath string) error {
+	if s.index == nil {
+		return fmt.Errorf("index not initialized")
+	}
+
+	info, err := s.storage.Stat(relPath)
+	if err != nil {
+		return err
+	}
+
+	content, err := s.storage.Read(relPath)
+	if err != nil {
+		return err
+	}
+
+	created, modified := fileTimes(s.commitTimes, relPath, info.ModTime)
+	return s.index.Add(ctx, buildDocument(relPath, content, created, modified))
+}
+
+// ResolveNote finds the notes a user most likely means by query.
+// An exact path (with or without .md) wins, then exact titles or aliases
+// (case-insensitive), then fuzzy matches on title and path, best first.
+func (s *NoteService) ResolveNote(ctx context.Context, query string) ([]Note, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	target := strings.TrimPrefix(filepath.ToSlash(strings.TrimSpace(query)), "./")
+	if target == "" {
+		return nil, fmt.Errorf("empty note reference")
+	}
+
+	for _, note := range notes {
+		if note.File.Relative == target || note.File.Relative == target+".md" {
+			return []Note{note}, nil
+		}
+	}
+	for _, note := range notes {
+		if note.ID != "" && strings.EqualFold(note.ID, target) {
+			return []Note{note}, nil
+		}
+	}
+
+	var titled []Note
+	for _, note := range notes {
+		if strings.EqualFold(note.DisplayName(), target) || matchesAlias(&note, target) {
+			titled = append(titled, note)
+		}
+	}
+	if len(titled) > 0 {
+		sortNotesByPath(titled)
+		return titled, nil
+	}
+
+	candidates := make([]string, len(notes))
+	for i, note := range notes {
+		candidates[i] = note.DisplayName() + " " + note.File.Relative
+	}
+
+	matches := fuzzy.Find(target, candidates)
+	result := make([]Note, len(matches))
+	for i, match := range matches {
+		result[i] = notes[match.Index]
+	}
+
+	return result, nil
+}
+
+// matchesAlias reports whether one of the note's aliases equals name.
+func matchesAlias(note *Note, name string) bool {
+	for _, alias := range NoteAliases(note) {
+		if strings.EqualFold(alias, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortNotesByPath orders notes by relative path.
+func sortNotesByPath(notes []Note) {
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].File.Relative < notes[j].File.Relative
+	})
+}
+
 // GetIndex returns the search index for this notebook.
 // This is needed for view execution context.
 func (s *NoteService) GetIndex() search.Index {
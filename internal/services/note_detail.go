@@ -0,0 +1,246 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRelatedNotes limits the "related notes" footer of a note detail.
+const maxRelatedNotes = 5
+
+// NoteRef identifies a note by path and display title.
+type NoteRef struct {
+	Path  string `json:"path"`
+	Title string `json:"title"`
+}
+
+// RelatedNote is a note sharing tags with another note.
+type RelatedNote struct {
+	NoteRef
+	SharedTags []string `json:"shared_tags"`
+}
+
+// SharedTagList returns the shared tags as a comma separated list.
+func (r RelatedNote) SharedTagList() string {
+	return strings.Join(r.SharedTags, ", ")
+}
+
+// FrontmatterField is a single frontmatter entry in document order.
+type FrontmatterField struct {
+	Key   string
+	Value string
+}
+
+// NoteDetail is everything `notes show` displays about a note.
+type NoteDetail struct {
+	Path        string             `json:"path"`
+	Title       string             `json:"title"`
+	Metadata    map[string]any     `json:"metadata"`
+	Frontmatter []FrontmatterField `json:"-"`
+	Body        string             `json:"body"`
+	Raw         string             `json:"-"`
+	Backlinks   []NoteRef          `json:"backlinks"`
+	Related     []RelatedNote      `json:"related"`
+}
+
+// RawFrontmatter returns the YAML frontmatter exactly as written, without delimiters.
+func (d *NoteDetail) RawFrontmatter() string {
+	frontmatter, _, _ := splitFrontmatter([]byte(d.Raw))
+	return string(frontmatter)
+}
+
+// Detail loads a note from storage together with its backlinks and the
+// notes sharing the most tags with it.
+func (s *NoteService) Detail(ctx context.Context, relPath string) (*NoteDetail, error) {
+	content, err := s.storage.Read(relPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note: %w", err)
+	}
+
+	metadata, body := parseFrontmatter(content)
+	note := Note{Content: body, Metadata: metadata}
+	note.File.Relative = relPath
+	note.File.Filepath = relPath
+
+	fields, err := frontmatterFields(content)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := &NoteDetail{
+		Path:        relPath,
+		Title:       note.DisplayName(),
+		Metadata:    metadata,
+		Frontmatter: fields,
+		Body:        body,
+		Raw:         string(content),
+		Related:     []RelatedNote{},
+	}
+
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	detail.Backlinks = backlinkRefs(notes, relPath)
+	detail.Related = relatedNotes(&note, notes, maxRelatedNotes)
+
+	return detail, nil
+}
+
+// Backlinks returns the notes that link to the note at relPath.
+func (s *NoteService) Backlinks(ctx context.Context, relPath string) ([]NoteRef, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return backlinkRefs(notes, relPath), nil
+}
+
+// OutgoingLinks returns the notes the note at relPath links to, once each
+// in document order. Broken links are left out.
+func (s *NoteService) OutgoingLinks(ctx context.Context, relPath string) ([]NoteRef, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byPath := make(map[string]*Note, len(notes))
+	for i := range notes {
+		byPath[notes[i].File.Relative] = &notes[i]
+	}
+
+	refs := []NoteRef{}
+	seen := make(map[string]bool)
+	for _, link := range NewLinkGraph(notes).Outgoing(relPath) {
+		if link.Resolved == "" || seen[link.Resolved] {
+			continue
+		}
+		seen[link.Resolved] = true
+		refs = append(refs, NoteRef{Path: link.Resolved, Title: byPath[link.Resolved].DisplayName()})
+	}
+	return refs, nil
+}
+
+// backlinkRefs lists each note linking to relPath once, ordered by path.
+func backlinkRefs(notes []Note, relPath string) []NoteRef {
+	byPath := make(map[string]*Note, len(notes))
+	for i := range notes {
+		byPath[notes[i].File.Relative] = &notes[i]
+	}
+
+	refs := []NoteRef{}
+	seen := make(map[string]bool)
+	for _, link := range NewLinkGraph(notes).Backlinks(relPath) {
+		if seen[link.Source] {
+			continue
+		}
+		seen[link.Source] = true
+		refs = append(refs, NoteRef{Path: link.Source, Title: byPath[link.Source].DisplayName()})
+	}
+	return refs
+}
+
+// relatedNotes ranks notes by the number of tags they share with note.
+func relatedNotes(note *Note, notes []Note, limit int) []RelatedNote {
+	tags := noteTags(note)
+	related := []RelatedNote{}
+	if len(tags) == 0 {
+		return related
+	}
+
+	own := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		own[strings.ToLower(tag)] = true
+	}
+
+	for i := range notes {
+		other := &notes[i]
+		if other.File.Relative == note.File.Relative {
+			continue
+		}
+
+		var shared []string
+		for _, tag := range noteTags(other) {
+			if own[strings.ToLower(tag)] {
+				shared = append(shared, tag)
+			}
+		}
+		if len(shared) > 0 {
+			related = append(related, RelatedNote{
+				NoteRef:    NoteRef{Path: other.File.Relative, Title: other.DisplayName()},
+				SharedTags: shared,
+			})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if len(related[i].SharedTags) != len(related[j].SharedTags) {
+			return len(related[i].SharedTags) > len(related[j].SharedTags)
+		}
+		return related[i].Path < related[j].Path
+	})
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related
+}
+
+// noteTags returns the tags of a note from the "tags" and "tag" fields.
+// Unlike extractTags it accepts the single-value strings the index returns
+// for one-element lists.
+func noteTags(note *Note) []string {
+	return metadataTags(note.Metadata)
+}
+
+// frontmatterFields returns the top-level frontmatter entries in document
+// order, with values formatted for display.
+func frontmatterFields(content []byte) ([]FrontmatterField, error) {
+	frontmatter, _, ok := splitFrontmatter(content)
+	if !ok {
+		return nil, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(frontmatter, &doc); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	mapping := doc.Content[0]
+	fields := make([]FrontmatterField, 0, len(mapping.Content)/2)
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		fields = append(fields, FrontmatterField{
+			Key:   mapping.Content[i].Value,
+			Value: formatYAMLValue(mapping.Content[i+1]),
+		})
+	}
+	return fields, nil
+}
+
+// formatYAMLValue renders a YAML node as a single display line.
+func formatYAMLValue(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.SequenceNode:
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			items[i] = formatYAMLValue(item)
+		}
+		return strings.Join(items, ", ")
+	default:
+		out, err := yaml.Marshal(node)
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(strings.ReplaceAll(string(out), "\n", " "))
+	}
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// TemplateDir holds note template files, relative to the notebook directory.
+// A file named <name>.md is available as template <name>.
+const TemplateDir = ".jot/templates"
+
+// maxIncludeDepth bounds nested includes so a template cannot include itself forever.
+const maxIncludeDepth = 8
+
+// templateExtensions are the file extensions tried when resolving template files.
+var templateExtensions = []string{".md", ".tmpl", ".gotmpl"}
+
+// TemplateData is the data passed to a note template.
+type TemplateData struct {
+	// Title is the note title.
+	Title string
+	// Notebook is the notebook name.
+	Notebook string
+	// Path is the note path relative to the notebook root.
+	Path string
+	// Dir is the directory part of Path ("." for the root).
+	Dir string
+	// Slug is the file name of Path without extension.
+	Slug string
+	// Data holds the --data values given on the command line.
+	Data map[string]interface{}
+	// Now is the time the note is created.
+	Now time.Time
+}
+
+// PromptFunc asks the user for a value. def is shown as the default answer.
+type PromptFunc func(label, def string) (string, error)
+
+// NoteTemplates renders note templates for a notebook.
+//
+// Templates are looked up by name in the notebook's "templates" map first,
+// whose values are either inline template text or a path to a template file
+// relative to the notebook directory, and then in TemplateDir.
+type NoteTemplates struct {
+	// Templates is the notebook's "templates" config map.
+	Templates map[string]string
+	// Dir is the notebook directory holding .jot.json.
+	Dir string
+	// Prompt answers {{prompt}} calls for values not given in Data.
+	// When nil, missing values are an error.
+	Prompt PromptFunc
+}
+
+// NoteTemplates returns the template renderer for the notebook.
+func (n *Notebook) NoteTemplates() *NoteTemplates {
+	return &NoteTemplates{
+		Templates: n.Config.Templates,
+		Dir:       filepath.Dir(n.Config.Path),
+	}
+}
+
+// NewTemplateData builds the template data for a note at relPath.
+func NewTemplateData(notebook, title, relPath string, data map[string]interface{}) TemplateData {
+	relPath = filepath.ToSlash(relPath)
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return TemplateData{
+		Title:    title,
+		Notebook: notebook,
+		Path:     relPath,
+		Dir:      filepath.ToSlash(filepath.Dir(relPath)),
+		Slug:     strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath)),
+		Data:     data,
+		Now:      time.Now(),
+	}
+}
+
+// Has reports whether a template with the given name exists.
+func (t *NoteTemplates) Has(name string) bool {
+	_, err := t.Source(name)
+	return err == nil
+}
+
+// Names returns the names of all available templates, sorted.
+func (t *NoteTemplates) Names() []string {
+	seen := make(map[string]bool)
+	for name := range t.Templates {
+		seen[name] = true
+	}
+	entries, _ := os.ReadDir(filepath.Join(t.Dir, TemplateDir))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		ext := filepath.Ext(entry.Name())
+		for _, known := range templateExtensions {
+			if ext == known {
+				seen[strings.TrimSuffix(entry.Name(), ext)] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Source returns the raw text of the named template.
+func (t *NoteTemplates) Source(name string) (string, error) {
+	if value, ok := t.Templates[name]; ok {
+		if path, ok := t.templateFile(value); ok {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to read template %q: %w", name, err)
+			}
+			return string(content), nil
+		}
+		return value, nil
+	}
+
+	if strings.Contains(name, "..") {
+		return "", fmt.Errorf("template %q not found", name)
+	}
+	base := filepath.Join(t.Dir, TemplateDir, filepath.FromSlash(name))
+	candidates := []string{base}
+	for _, ext := range templateExtensions {
+		candidates = append(candidates, base+ext)
+	}
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read template %q: %w", name, err)
+		}
+		return string(content), nil
+	}
+
+	return "", fmt.Errorf("template %q not found (define it in .jot.json templates or add %s/%s.md)", name, TemplateDir, name)
+}
+
+// templateFile reports whether a templates map value names an existing file.
+// Values spanning several lines are always inline templates.
+func (t *NoteTemplates) templateFile(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.ContainsAny(value, "\n{") {
+		return "", false
+	}
+	path := value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.Dir, filepath.FromSlash(path))
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// Render renders the named template with data.
+func (t *NoteTemplates) Render(name string, data TemplateData) (string, error) {
+	r := &templateRun{templates: t, data: data, answers: map[string]string{}}
+	return r.render(name, 0)
+}
+
+// templateRun holds the state shared by a template and its includes.
+type templateRun struct {
+	templates *NoteTemplates
+	data      TemplateData
+	answers   map[string]string
+}
+
+// render parses and executes the named template.
+func (r *templateRun) render(name string, depth int) (string, error) {
+	if depth > maxIncludeDepth {
+		return "", fmt.Errorf("template %q: includes nested more than %d deep", name, maxIncludeDepth)
+	}
+
+	source, err := r.templates.Source(name)
+	if err != nil {
+		return "", err
+	}
+
+	// Arithmetic and env placeholders shared with views are not valid
+	// text/template syntax, so resolve them before parsing
+	source = resolveDynamicVariables(source, r.data.Now)
+
+	tmpl, err := template.New(name).Funcs(r.funcs(depth)).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, r.data); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// funcs returns the template functions for a template at the given include depth.
+func (r *templateRun) funcs(depth int) template.FuncMap {
+	now := r.data.Now
+	funcs := template.FuncMap{
+		"title":    func() string { return r.data.Title },
+		"notebook": func() string { return r.data.Notebook },
+		"path":     func() string { return r.data.Path },
+		"date": func(layout string) string {
+			return now.Format(layout)
+		},
+		"dateAdd": func(days int, layout string) string {
+			return now.AddDate(0, 0, days).Format(layout)
+		},
+		"lower":   strings.ToLower,
+		"upper":   strings.ToUpper,
+		"slugify": core.Slugify,
+		"default": func(def string, value interface{}) string {
+			if s := fmt.Sprint(value); value != nil && s != "" {
+				return s
+			}
+			return def
+		},
+		"include": func(name string) (string, error) {
+			return r.render(name, depth+1)
+		},
+		"prompt": r.prompt,
+	}
+	for name, value := range dateVariables(now) {
+		value := value
+		funcs[name] = func() string { return value }
+	}
+	return funcs
+}
+
+// prompt returns the value for label from the --data values, asking the user
+// when it is missing. An optional second argument is the default answer.
+func (r *templateRun) prompt(label string, def ...string) (string, error) {
+	for _, key := range promptKeys(label) {
+		if value, ok := r.data.Data[key]; ok {
+			return fmt.Sprint(value), nil
+		}
+	}
+	if answer, ok := r.answers[label]; ok {
+		return answer, nil
+	}
+
+	fallback := ""
+	if len(def) > 0 {
+		fallback = def[0]
+	}
+	if r.templates.Prompt == nil {
+		if len(def) > 0 {
+			return fallback, nil
+		}
+		return "", fmt.Errorf("template needs a value for %q: pass --data %s=...", label, promptKeys(label)[1])
+	}
+
+	answer, err := r.templates.Prompt(label, fallback)
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		answer = fallback
+	}
+	r.answers[label] = answer
+	return answer, nil
+}
+
+// promptKeys returns the --data keys that answer a prompt: the label as
+// written and its lowercase, underscore-separated form.
+func promptKeys(label string) []string {
+	key := strings.ToLower(strings.Join(strings.Fields(label), "_"))
+	return []string{label, key}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func templateData(t *testing.T) TemplateData {
+	t.Helper()
+	data := NewTemplateData("Team", "Sprint Review", "meetings/sprint-review.md", map[string]interface{}{"owner": "alice"})
+	data.Now = time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	return data
+}
+
+func TestNoteTemplates_RendersVariablesAndDates(t *testing.T) {
+	templates := &NoteTemplates{Templates: map[string]string{
+		"meeting": "# {{title}}\n\n{{ .Notebook }} / {{ .Path }} / {{ .Dir }} / {{ .Slug }}\nOwner: {{ .Data.owner }}\nDate: {{today}} due {{today+7}}\nWeek of {{ this_week }}, {{ date \"Jan 2\" }}, {{ dateAdd -1 \"2006-01-02\" }}\n",
+	}}
+
+	out, err := templates.Render("meeting", templateData(t))
+	require.NoError(t, err)
+	assert.Equal(t, "# Sprint Review\n\nTeam / meetings/sprint-review.md / meetings / sprint-review\nOwner: alice\nDate: 2024-01-15 due 2024-01-22\nWeek of 2024-01-15, Jan 15, 2024-01-14\n", out)
+}
+
+func TestNoteTemplates_LoadsFilesAndIncludes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, TemplateDir), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tpl"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, TemplateDir, "footer.md"), []byte("-- {{ .Notebook }}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tpl", "meeting.md"), []byte("# {{ .Title }}\n{{ include \"footer\" }}\n"), 0644))
+
+	templates := &NoteTemplates{Dir: dir, Templates: map[string]string{"meeting": "tpl/meeting.md"}}
+
+	assert.True(t, templates.Has("footer"))
+	assert.False(t, templates.Has("missing"))
+	assert.Equal(t, []string{"footer", "meeting"}, templates.Names())
+
+	out, err := templates.Render("meeting", templateData(t))
+	require.NoError(t, err)
+	assert.Equal(t, "# Sprint Review\n-- Team\n", out)
+
+	_, err = templates.Render("missing", templateData(t))
+	assert.ErrorContains(t, err, `template "missing" not found`)
+}
+
+func TestNoteTemplates_IncludeCycle(t *testing.T) {
+	templates := &NoteTemplates{Templates: map[string]string{"loop": `{{ include "loop" }}`}}
+
+	_, err := templates.Render("loop", templateData(t))
+	assert.ErrorContains(t, err, "nested more than")
+}
+
+func TestNoteTemplates_Prompt(t *testing.T) {
+	var asked []string
+	templates := &NoteTemplates{
+		Templates: map[string]string{
+			"review": `{{ prompt "Owner" }} {{ prompt "Due Date" "soon" }} {{ prompt "Reviewer" }} {{ prompt "Reviewer" }}`,
+		},
+		Prompt: func(label, def string) (string, error) {
+			asked = append(asked, label)
+			if label == "Reviewer" {
+				return "bob", nil
+			}
+			return "", nil
+		},
+	}
+
+	out, err := templates.Render("review", templateData(t))
+	require.NoError(t, err)
+	assert.Equal(t, "alice soon bob bob", out)
+	assert.Equal(t, []string{"Due Date", "Reviewer"}, asked, "values from --data are not asked for and answers are reused")
+}
+
+func TestNoteTemplates_PromptWithoutTerminal(t *testing.T) {
+	templates := &NoteTemplates{Templates: map[string]string{
+		"review": `{{ prompt "Due Date" "soon" }}`,
+		"strict": `{{ prompt "Due Date" }}`,
+	}}
+
+	out, err := templates.Render("review", templateData(t))
+	require.NoError(t, err)
+	assert.Equal(t, "soon", out)
+
+	_, err = templates.Render("strict", templateData(t))
+	assert.ErrorContains(t, err, "--data due_date=")
+}
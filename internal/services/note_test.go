@@ -3,6 +3,7 @@ package services_test
 import (
 	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -1288,3 +1289,114 @@ func TestNoteService_SearchWithFindOpts_ErrorCases(t *testing.T) {
 		assert.Contains(t, err.Error(), "index not initialized")
 	})
 }
+
+func TestNoteService_ResolveNote(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	testutil.CreateTestNoteWithFrontmatter(t, notebookDir, "sprint-planning.md",
+		map[string]interface{}{"title": "Sprint Planning"}, "Plan the sprint.")
+	testutil.CreateTestNoteWithFrontmatter(t, notebookDir, "retro.md",
+		map[string]interface{}{"title": "Sprint Retro"}, "Look back.")
+	require.NoError(t, os.MkdirAll(filepath.Join(notebookDir, "notes", "other"), 0755))
+	testutil.CreateTestNoteWithFrontmatter(t, notebookDir, "other/retro.md",
+		map[string]interface{}{"title": "Sprint Retro"}, "Another retro.")
+
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	t.Run("exact path", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "notes/retro.md")
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "notes/retro.md", notes[0].File.Relative)
+	})
+
+	t.Run("path without extension", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "notes/sprint-planning")
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "Sprint Planning", notes[0].DisplayName())
+	})
+
+	t.Run("exact title is case-insensitive", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "sprint planning")
+		require.NoError(t, err)
+		require.Len(t, notes, 1)
+		assert.Equal(t, "notes/sprint-planning.md", notes[0].File.Relative)
+	})
+
+	t.Run("duplicate titles return all", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "Sprint Retro")
+		require.NoError(t, err)
+		require.Len(t, notes, 2)
+		assert.Equal(t, "notes/other/retro.md", notes[0].File.Relative)
+		assert.Equal(t, "notes/retro.md", notes[1].File.Relative)
+	})
+
+	t.Run("fuzzy", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "sprplan")
+		require.NoError(t, err)
+		require.NotEmpty(t, notes)
+		assert.Equal(t, "notes/sprint-planning.md", notes[0].File.Relative)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		notes, err := svc.ResolveNote(ctx, "zzzzzz")
+		require.NoError(t, err)
+		assert.Empty(t, notes)
+	})
+}
+
+func TestNoteService_IndexFile(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	testutil.CreateTestNoteWithFrontmatter(t, notebookDir, "todo.md",
+		map[string]interface{}{"title": "Old Title"}, "Body.")
+
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	require.NoError(t, svc.Storage().Write("notes/todo.md", []byte("---\ntitle: New Title\n---\n\nBody.\n")))
+	require.NoError(t, svc.IndexFile(ctx, "notes/todo.md"))
+
+	notes, err := svc.SearchNotes(ctx, "", false)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "New Title", notes[0].DisplayName())
+}
+
+func TestNoteService_Detail(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	testutil.CreateTestNote(t, notebookDir, "standup.md",
+		"---\ntitle: Standup\naliases: [daily-sync]\ntags: [work]\n---\n\nDaily notes.\n")
+	testutil.CreateTestNote(t, notebookDir, "plan.md",
+		"---\ntitle: Plan\ntags: [work]\n---\n\nSee [[daily-sync]].\n")
+
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	notes, err := svc.ResolveNote(ctx, "daily-sync")
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+
+	detail, err := svc.Detail(ctx, notes[0].File.Relative)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Standup", detail.Title)
+	assert.Equal(t, "\nDaily notes.\n", detail.Body)
+	assert.Equal(t, "title: Standup\naliases: [daily-sync]\ntags: [work]\n", detail.RawFrontmatter())
+	require.Len(t, detail.Backlinks, 1)
+	assert.Equal(t, "notes/plan.md", detail.Backlinks[0].Path)
+	require.Len(t, detail.Related, 1)
+	assert.Equal(t, "notes/plan.md", detail.Related[0].Path)
+}
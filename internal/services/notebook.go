@@ -4,15 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/spf13/afero"
+	"github.com/zenobi-us/jot/internal/core"
 	"github.com/zenobi-us/jot/internal/search"
 	"github.com/zenobi-us/jot/internal/search/bleve"
 	"gopkg.in/yaml.v3"
@@ -24,6 +28,9 @@ type NotebookGroup struct {
 	Globs    []string       `json:"globs"`
 	Metadata map[string]any `json:"metadata"`
 	Template string         `json:"template,omitempty"`
+	// Schema constrains the frontmatter of notes in the group, on top of
+	// the notebook schema.
+	Schema map[string]*FieldSchema `json:"schema,omitempty"`
 }
 
 // Notebook config version constants.
@@ -35,12 +42,20 @@ const (
 
 // StoredNotebookConfig is what's stored in .jot.json.
 type StoredNotebookConfig struct {
-	ConfigVersion Version           `json:"config_version,omitempty"`
-	Root          string            `json:"root"`
-	Name          string            `json:"name"`
-	Contexts      []string          `json:"contexts,omitempty"`
-	Templates     map[string]string `json:"templates,omitempty"`
-	Groups        []NotebookGroup   `json:"groups,omitempty"`
+	ConfigVersion Version                    `json:"config_version,omitempty"`
+	Root          string                     `json:"root"`
+	Name          string                     `json:"name"`
+	Contexts      []string                   `json:"contexts,omitempty"`
+	Templates     map[string]string          `json:"templates,omitempty"`
+	Groups        []NotebookGroup            `json:"groups,omitempty"`
+	PeriodicNotes map[string]*PeriodicConfig `json:"periodic,omitempty"`
+	IDFormat      string                     `json:"id_format,omitempty"`
+	Schema        map[string]*FieldSchema    `json:"schema,omitempty"`
+	Board         *core.BoardConfig          `json:"board,omitempty"`
+	Git           *GitConfig                 `json:"git,omitempty"`
+	Import        *ImportConfig              `json:"import,omitempty"`
+	Storage       *StorageConfig             `json:"storage,omitempty"`
+	Encryption    *EncryptionConfig          `json:"encryption,omitempty"`
 }
 
 // NotebookConfig includes runtime-resolved paths.
@@ -51,8 +66,14 @@ type NotebookConfig struct {
 
 // Notebook represents a loaded notebook with its services.
 type Notebook struct {
-	Config NotebookConfig
-	Notes  *NoteService
+	Config  NotebookConfig
+	Notes   *NoteService
+	Storage search.Storage `json:"-"`
+
+	changes *changeRecorder
+	// git is the work tree holding the notes, found on first use by GitRepo
+	git     *Git
+	gitOnce sync.Once
 }
 
 // NotebookService manages notebook operations.
@@ -103,14 +124,17 @@ func (s *NotebookService) LoadConfig(path string) (*NotebookConfig, error) {
 
 	// Resolve root path relative to config location
 	rootPath := filepath.Join(path, stored.Root)
-	if _, err := os.Stat(rootPath); err != nil {
-		// Create root directory if it doesn't exist
-		if os.IsNotExist(err) {
-			if mkErr := os.MkdirAll(rootPath, 0755); mkErr != nil {
-				return nil, fmt.Errorf("notes path not found and could not create: %s", rootPath)
+	// Archive and remote backends don't use the local notes directory
+	if stored.Storage.IsLocal() {
+		if _, err := os.Stat(rootPath); err != nil {
+			// Create root directory if it doesn't exist
+			if os.IsNotExist(err) {
+				if mkErr := os.MkdirAll(rootPath, 0755); mkErr != nil {
+					return nil, fmt.Errorf("notes path not found and could not create: %s", rootPath)
+				}
+			} else {
+				return nil, fmt.Errorf("notes path error: %w", err)
 			}
-		} else {
-			return nil, fmt.Errorf("notes path error: %w", err)
 		}
 	}
 
@@ -122,6 +146,14 @@ func (s *NotebookService) LoadConfig(path string) (*NotebookConfig, error) {
 			Contexts:      stored.Contexts,
 			Templates:     stored.Templates,
 			Groups:        stored.Groups,
+			PeriodicNotes: stored.PeriodicNotes,
+			IDFormat:      stored.IDFormat,
+			Schema:        stored.Schema,
+			Board:         stored.Board,
+			Git:           stored.Git,
+			Import:        stored.Import,
+			Storage:       stored.Storage,
+			Encryption:    stored.Encryption,
 		},
 		Path: configPath,
 	}, nil
@@ -134,13 +166,44 @@ func (s *NotebookService) Open(notebookPath string) (*Notebook, error) {
 		return nil, err
 	}
 
+	backend, err := NewStorage(config.Storage, notebookPath, config.Root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notebook storage: %w", err)
+	}
+
+	// Record changes below encryption so auto-commit sees the stored files
+	changes := newChangeRecorder(backend)
+	storage, err := NewEncryptedStorage(changes, config.Encryption, notebookPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure encryption: %w", err)
+	}
+
+	nb := &Notebook{
+		Config:  *config,
+		Storage: storage,
+		changes: changes,
+	}
+
+	// Git is only looked up here when commit dates are on; otherwise on
+	// first use, so commands that don't need it never run git
+	var commitTimes map[string]CommitTimes
+	if config.Git != nil && config.Git.CommitDates {
+		if git := nb.gitRepo(s.log); git != nil {
+			if commitTimes, err = git.CommitTimes(context.Background()); err != nil {
+				s.log.Warn().Err(err).Msg("failed to read commit dates; using file times")
+			}
+		}
+	}
+
 	// Create Bleve index for this notebook
-	idx, err := s.createIndex(config.Root)
+	idx, err := s.createIndex(storage, commitTimes)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create search index: %w", err)
 	}
 
 	noteService := NewNoteService(s.configService, idx, config.Root)
+	noteService.SetStorage(storage)
+	noteService.SetCommitTimes(commitTimes)
 
 	semanticIdx, err := s.createSemanticIndex(config.Root)
 	if err != nil {
@@ -149,67 +212,80 @@ func (s *NotebookService) Open(notebookPath string) (*Notebook, error) {
 	}
 	noteService.SetSemanticIndex(semanticIdx)
 
-	return &Notebook{
-		Config: *config,
-		Notes:  noteService,
-	}, nil
+	nb.Notes = noteService
+	return nb, nil
 }
 
-// createIndex creates and populates a Bleve index for the notebook
-func (s *NotebookService) createIndex(notebookRoot string) (search.Index, error) {
+// GitRepo returns the git work tree holding the notes, or nil when the
+// notes aren't local, aren't in a work tree or git isn't installed. It
+// runs git once, on the first call.
+func (n *Notebook) GitRepo() *Git {
+	log := zerolog.Nop()
+	if n.Notes != nil {
+		log = n.Notes.log
+	}
+	return n.gitRepo(log)
+}
+
+func (n *Notebook) gitRepo(log zerolog.Logger) *Git {
+	n.gitOnce.Do(func() {
+		if !n.Config.Storage.IsLocal() {
+			return
+		}
+		git, err := OpenGit(context.Background(), n.Config.Root)
+		if err != nil {
+			if !errors.Is(err, ErrNotGitRepo) {
+				log.Warn().Err(err).Msg("failed to open git work tree")
+			}
+			return
+		}
+		n.git = git
+	})
+	return n.git
+}
+
+// createIndex creates and populates a Bleve index from the notebook storage.
+// commitTimes, when set, replace file times as the created/modified fallback.
+func (s *NotebookService) createIndex(storage search.Storage, commitTimes map[string]CommitTimes) (search.Index, error) {
 	// For now, use in-memory index
 	// TODO: Consider persistent index for large notebooks
-	storage := bleve.MemStorage()
 	idx, err := bleve.NewIndex(storage, bleve.Options{InMemory: true})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create index: %w", err)
 	}
 
 	// Index all markdown files in the notebook
-	fs := afero.NewOsFs()
-	err = afero.Walk(fs, notebookRoot, func(path string, info os.FileInfo, err error) error {
+	err = storage.Walk(".", func(relPath string, info search.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip directories
-		if info.IsDir() {
+		// Skip directories, and jot's own data (index, trash) entirely
+		if info.IsDir {
+			if filepath.ToSlash(relPath) == ".jot" {
+				return search.SkipDir
+			}
 			return nil
 		}
 
 		// Only process markdown files
-		if filepath.Ext(path) != ".md" {
+		if filepath.Ext(relPath) != ".md" {
 			return nil
 		}
 
-		// Get relative path from notebook root
-		relPath, err := filepath.Rel(notebookRoot, path)
-		if err != nil {
-			s.log.Warn().Err(err).Str("path", path).Msg("failed to get relative path")
+		// Read file content
+		content, err := storage.Read(relPath)
+		if errors.Is(err, search.ErrEncrypted) {
+			s.log.Warn().Str("path", relPath).Msg("skipping encrypted note: no matching key available")
 			return nil
 		}
-
-		// Read file content
-		content, err := afero.ReadFile(fs, path)
 		if err != nil {
-			s.log.Warn().Err(err).Str("path", path).Msg("failed to read file")
+			s.log.Warn().Err(err).Str("path", relPath).Msg("failed to read file")
 			return nil
 		}
 
-		// Parse frontmatter and extract metadata
-		metadata, body := parseFrontmatter(content)
-
-		// Create document
-		doc := search.Document{
-			Path:     relPath,
-			Title:    extractTitle(metadata),
-			Body:     body,
-			Lead:     extractLead(body),
-			Tags:     extractTags(metadata),
-			Metadata: metadata,
-			Created:  extractTime(metadata, "created", info.ModTime()),
-			Modified: extractTime(metadata, "modified", info.ModTime()),
-		}
+		created, modified := fileTimes(commitTimes, relPath, info.ModTime)
+		doc := buildDocument(relPath, content, created, modified)
 
 		// Add to index
 		ctx := context.Background()
@@ -228,6 +304,39 @@ func (s *NotebookService) createIndex(notebookRoot string) (search.Index, error)
 	return idx, nil
 }
 
+// buildDocument parses a note's content into an index document.
+// created and modified are used when the frontmatter has no such dates.
+func buildDocument(relPath string, content []byte, created, modified time.Time) search.Document {
+	// Parse frontmatter and extract metadata
+	metadata, body := parseFrontmatter(content)
+
+	return search.Document{
+		Path:     filepath.ToSlash(relPath),
+		ID:       extractID(metadata),
+		Title:    extractTitle(metadata),
+		Body:     body,
+		Lead:     extractLead(body),
+		Tags:     extractTags(metadata),
+		Metadata: metadata,
+		Created:  extractTime(metadata, "created", created),
+		Modified: extractTime(metadata, "modified", modified),
+		Tasks:    search.ParseTasks(content),
+	}
+}
+
+// fileTimes returns the created and modified fallbacks of a note: its
+// first and last commit times when known, otherwise modTime. A note with
+// uncommitted changes keeps modTime as modified when it is newer.
+func fileTimes(commitTimes map[string]CommitTimes, relPath string, modTime time.Time) (created, modified time.Time) {
+	if t, ok := commitTimes[filepath.ToSlash(relPath)]; ok {
+		if t.Dirty && modTime.After(t.Last) {
+			return t.First, modTime
+		}
+		return t.First, t.Last
+	}
+	return modTime, modTime
+}
+
 // createSemanticIndex initializes semantic retrieval backend for a notebook.
 // Phase 3 starts with a safe noop backend and can be swapped with a real
 // semantic backend implementation without changing callers.
@@ -278,31 +387,58 @@ func extractTime(metadata map[string]any, field string, defaultTime time.Time) t
 }
 
 func parseFrontmatter(content []byte) (map[string]any, string) {
-	// Check for frontmatter delimiter
-	if !bytes.HasPrefix(content, []byte("---\n")) {
+	metadata, body, err := parseFrontmatterStrict(content)
+	if err != nil {
+		// Failed to parse, treat the whole note as body
 		return make(map[string]any), string(content)
 	}
+	return metadata, body
+}
 
-	// Find the end of frontmatter
-	rest := content[4:] // Skip first "---\n"
-	endIdx := bytes.Index(rest, []byte("\n---\n"))
-	if endIdx == -1 {
-		// No closing delimiter, treat as no frontmatter
-		return make(map[string]any), string(content)
+// FrontmatterError describes frontmatter that could not be parsed. Line is
+// the line of the note the problem was found on.
+type FrontmatterError struct {
+	Line    int
+	Message string
+}
+
+func (e *FrontmatterError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// yamlErrorLine matches the line number yaml.v3 puts in its errors.
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// parseFrontmatterStrict is parseFrontmatter that reports frontmatter it
+// cannot parse rather than treating it as body.
+func parseFrontmatterStrict(content []byte) (map[string]any, string, error) {
+	// Check for frontmatter delimiter
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return make(map[string]any), string(content), nil
 	}
 
-	// Extract frontmatter and body
-	frontmatterBytes := rest[:endIdx]
-	bodyBytes := rest[endIdx+5:] // Skip "\n---\n"
+	frontmatterBytes, bodyBytes, ok := splitFrontmatter(content)
+	if !ok {
+		return nil, "", &FrontmatterError{Line: 1, Message: "frontmatter is not closed with ---"}
+	}
 
 	// Parse YAML frontmatter
 	var metadata map[string]any
 	if err := yaml.Unmarshal(frontmatterBytes, &metadata); err != nil {
-		// Failed to parse, return empty metadata
-		return make(map[string]any), string(content)
+		msg := strings.TrimPrefix(err.Error(), "yaml: ")
+		line := 1
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			// yaml counts from the line after the opening ---
+			n, _ := strconv.Atoi(m[1])
+			line, msg = n+1, m[2]
+		}
+		return nil, "", &FrontmatterError{Line: line, Message: msg}
+	}
+	if metadata == nil {
+		metadata = make(map[string]any)
 	}
 
-	return metadata, string(bodyBytes)
+	return metadata, string(bodyBytes), nil
 }
 
 func extractLead(body string) string {
@@ -388,13 +524,19 @@ func (s *NotebookService) Create(name, path string, register bool) (*Notebook, e
 		return nil, err
 	}
 
+	storage, err := NewEncryptedStorage(bleve.OsStorage(notesDir), nil, path)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create Bleve index for this notebook
-	idx, err := s.createIndex(notesDir)
+	idx, err := s.createIndex(storage, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create search index: %w", err)
 	}
 
 	noteService := NewNoteService(s.configService, idx, notesDir)
+	noteService.SetStorage(storage)
 
 	semanticIdx, semErr := s.createSemanticIndex(notesDir)
 	if semErr != nil {
@@ -404,8 +546,9 @@ func (s *NotebookService) Create(name, path string, register bool) (*Notebook, e
 	noteService.SetSemanticIndex(semanticIdx)
 
 	notebook := &Notebook{
-		Config: config,
-		Notes:  noteService,
+		Config:  config,
+		Notes:   noteService,
+		Storage: storage,
 	}
 
 	// Save config
@@ -533,11 +676,19 @@ func (n *Notebook) SaveConfig(register bool, configService *ConfigService) error
 	}
 
 	stored := StoredNotebookConfig{
-		Root:      relRoot,
-		Name:      n.Config.Name,
-		Contexts:  n.Config.Contexts,
-		Templates: n.Config.Templates,
-		Groups:    n.Config.Groups,
+		Root:          relRoot,
+		Name:          n.Config.Name,
+		Contexts:      n.Config.Contexts,
+		Templates:     n.Config.Templates,
+		Groups:        n.Config.Groups,
+		PeriodicNotes: n.Config.PeriodicNotes,
+		IDFormat:      n.Config.IDFormat,
+		Schema:        n.Config.Schema,
+		Storage:       n.Config.Storage,
+		Board:         n.Config.Board,
+		Git:           n.Config.Git,
+		Import:        n.Config.Import,
+		Encryption:    n.Config.Encryption,
 	}
 
 	data, err := json.MarshalIndent(stored, "", "  ")
@@ -551,13 +702,7 @@ func (n *Notebook) SaveConfig(register bool, configService *ConfigService) error
 
 	// Register globally if requested
 	if register {
-		notebooks := configService.Store.Notebooks
-		notebookDir := filepath.Dir(n.Config.Path)
-		if slices.Contains(notebooks, notebookDir) {
-			return nil // Already registered
-		}
-		configService.Store.Notebooks = append(notebooks, notebookDir)
-		return configService.Write(configService.Store)
+		return configService.RegisterNotebook(filepath.Dir(n.Config.Path))
 	}
 
 	return nil
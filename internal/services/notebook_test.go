@@ -1,13 +1,20 @@
 package services
 
 import (
+	"archive/zip"
+	"context"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/bleve"
 )
 
 // Test helper functions
@@ -58,6 +65,24 @@ func createTestConfigService(t *testing.T, tmpDir string, notebooks []string) *C
 	return svc
 }
 
+// openTestNotebook opens a new notebook holding files, keyed by path under
+// its notes root, and indexes the notes among them.
+func openTestNotebook(t *testing.T, files map[string]string) *Notebook {
+	t.Helper()
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	nb, err := NewNotebookService(createTestConfigService(t, tmpDir, nil)).Open(createTestNotebook(t, tmpDir, "notes"))
+	require.NoError(t, err)
+
+	for rel, content := range files {
+		require.NoError(t, nb.Storage.Write(rel, []byte(content)))
+		if strings.HasSuffix(rel, ".md") {
+			require.NoError(t, nb.Notes.IndexFile(ctx, rel))
+		}
+	}
+	return nb
+}
+
 // HasNotebook tests
 
 func TestNotebookService_HasNotebook_ExistsTrue(t *testing.T) {
@@ -769,3 +794,133 @@ func TestNotebookService_Infer_ContextBeforeAncestorWithoutCurrentDir(t *testing
 	require.NotNil(t, notebook)
 	assert.Equal(t, "context-notebook", notebook.Config.Name)
 }
+
+// Storage tests
+
+func TestNotebookService_Open_ArchiveStorage(t *testing.T) {
+	tmpDir := t.TempDir()
+	notebookDir := filepath.Join(tmpDir, "archived")
+	require.NoError(t, os.MkdirAll(notebookDir, 0755))
+
+	archivePath := filepath.Join(notebookDir, "notes.zip")
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("ideas/first.md")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("---\ntitle: First Idea\n---\n\nBody text.\n"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	require.NoError(t, f.Close())
+
+	config := StoredNotebookConfig{
+		Name:    "archived",
+		Root:    ".notes",
+		Storage: &StorageConfig{Type: StorageTypeArchive, Path: "notes.zip"},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(notebookDir, NotebookConfigFile), data, 0644))
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+
+	// Archive storage must not create the local notes root
+	_, err = os.Stat(filepath.Join(notebookDir, ".notes"))
+	assert.True(t, os.IsNotExist(err))
+
+	notes, err := notebook.Notes.SearchNotes(context.Background(), "", false)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "ideas/first.md", notes[0].File.Relative)
+	assert.Equal(t, "First Idea", notes[0].DisplayName())
+
+	err = notebook.Storage.Write("new.md", []byte("x"))
+	assert.ErrorIs(t, err, search.ErrReadOnly)
+}
+
+func TestNewStorage_UnknownType(t *testing.T) {
+	_, err := NewStorage(&StorageConfig{Type: "ftp"}, t.TempDir(), t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestNewStorage_DefaultsToLocal(t *testing.T) {
+	root := t.TempDir()
+	storage, err := NewStorage(nil, root, root)
+	require.NoError(t, err)
+	assert.Equal(t, root, storage.Root())
+}
+
+func TestNotebookService_Open_EncryptedNotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	notebookDir := createTestNotebook(t, tmpDir, "secure")
+
+	identity, err := age.GenerateX25519Identity()
+	require.NoError(t, err)
+	keyFile := filepath.Join(tmpDir, "key.txt")
+	require.NoError(t, os.WriteFile(keyFile, []byte(identity.String()+"\n"), 0600))
+
+	configPath := filepath.Join(notebookDir, NotebookConfigFile)
+	config := StoredNotebookConfig{
+		Name: "secure",
+		Root: ".notes",
+		Encryption: &EncryptionConfig{
+			Globs:   []string{"secrets/**"},
+			KeyFile: keyFile,
+		},
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, data, 0644))
+
+	svc := NewNotebookService(createTestConfigService(t, tmpDir, nil))
+	notebook, err := svc.Open(notebookDir)
+	require.NoError(t, err)
+	require.NotNil(t, notebook.Encryption())
+
+	require.NoError(t, notebook.Storage.Write("secrets/db.md", []byte("---\ntitle: DB Password\n---\n\nhunter2\n")))
+	raw, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "secrets", "db.md"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "hunter2")
+
+	// With the key the note is decrypted and indexed
+	notebook, err = svc.Open(notebookDir)
+	require.NoError(t, err)
+	notes, err := notebook.Notes.SearchNotes(context.Background(), "", false)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, "DB Password", notes[0].DisplayName())
+
+	// Without the key the note is skipped
+	t.Setenv(EnvKeyFile, filepath.Join(tmpDir, "missing.txt"))
+	notebook, err = svc.Open(notebookDir)
+	require.NoError(t, err)
+	notes, err = notebook.Notes.SearchNotes(context.Background(), "", false)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+}
+
+func TestNewEncryptedStorage_Passphrase(t *testing.T) {
+	t.Setenv("TEST_JOT_PASSPHRASE", "correct horse")
+
+	storage, err := NewEncryptedStorage(bleve.MemStorage(), &EncryptionConfig{
+		PassphraseEnv:    "TEST_JOT_PASSPHRASE",
+		ScryptWorkFactor: 10,
+	}, t.TempDir())
+	require.NoError(t, err)
+	assert.True(t, storage.CanEncrypt())
+	assert.True(t, storage.CanDecrypt())
+
+	require.NoError(t, storage.WriteEncrypted("note.md", []byte("secret")))
+	plain, err := storage.Read("note.md")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plain))
+}
+
+func TestNewEncryptedStorage_InvalidRecipient(t *testing.T) {
+	_, err := NewEncryptedStorage(bleve.MemStorage(), &EncryptionConfig{
+		Recipients: []string{"not-a-key"},
+	}, t.TempDir())
+	assert.Error(t, err)
+}
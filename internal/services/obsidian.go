@@ -0,0 +1,541 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ObsidianImport is the plan for importing an Obsidian vault: the converted
+// notes and the attachments to copy. PlanObsidianImport builds it without
+// writing anything, so it doubles as the dry-run report.
+type ObsidianImport struct {
+	// Vault is the absolute path of the vault.
+	Vault string `json:"vault"`
+	// AttachmentFolder is the vault's "attachmentFolderPath" setting from
+	// .obsidian/app.json, empty for the vault root.
+	AttachmentFolder string `json:"attachment_folder"`
+	// Notes are the markdown files of the vault, by path.
+	Notes []ImportedNote `json:"notes"`
+	// Attachments are the other files of the vault, relative to it.
+	Attachments []string `json:"attachments"`
+	// Warnings are problems that left notes unconverted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ImportedNote describes how one note is converted.
+type ImportedNote struct {
+	Path string `json:"path"`
+	// Tags are the inline #tags added to the frontmatter tags.
+	Tags []string `json:"tags,omitempty"`
+	// Aliases are the note's aliases when they had to be normalised.
+	Aliases []string `json:"aliases,omitempty"`
+	// Links counts the [[wikilinks]] rewritten to a vault path because
+	// jot would otherwise resolve them to another note, or not at all.
+	Links int `json:"links,omitempty"`
+	// Embeds counts attachment embeds turned into markdown links.
+	Embeds int `json:"embeds,omitempty"`
+	// Unresolved lists link targets matching no note or attachment.
+	Unresolved []string `json:"unresolved,omitempty"`
+	// Fields lists the frontmatter fields set or normalised.
+	Fields []string `json:"fields,omitempty"`
+
+	Content []byte    `json:"-"`
+	ModTime time.Time `json:"-"`
+	changed bool
+}
+
+// Changed reports whether the import rewrites the note.
+func (n *ImportedNote) Changed() bool {
+	return n.changed
+}
+
+var (
+	// Obsidian tags are letters, digits, "_", "-" and "/" for nesting, with
+	// at least one non-digit
+	inlineTagPattern = regexp.MustCompile(`(^|[\s(])#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+	// ](destination) of markdown links, whose #anchors are not tags
+	linkDestinationPattern = regexp.MustCompile(`\]\([^)]*\)`)
+	// ![[target|label]] embeds and [[target|label]] links
+	obsidianLinkPattern = regexp.MustCompile(`(!?)\[\[([^\]|]+)(?:\|([^\]]*))?\]\]`)
+	codeFencePattern    = regexp.MustCompile("^\\s*(```|~~~)")
+	sizeLabelPattern    = regexp.MustCompile(`^\d+(x\d+)?$`)
+)
+
+// PlanObsidianImport reads a vault and converts its notes for jot:
+//   - inline #tags are added to the frontmatter "tags" list, and "tag" or
+//     string "tags" fields become a list
+//   - "alias" and string "aliases" fields become an "aliases" list
+//   - notes get a "title" from their file name, as Obsidian shows them, and
+//     "created"/"modified" from the file time when missing
+//   - [[wikilinks]] jot would resolve differently than Obsidian (to a note
+//     of the same name in another folder) are rewritten to the vault path
+//   - embeds and links to attachments become markdown links to the file,
+//     found through the vault's attachment folder setting
+//
+// Hidden folders (.obsidian, .trash, .git) are skipped. Notes with
+// frontmatter that doesn't parse are copied unchanged, with a warning.
+func PlanObsidianImport(vault string) (*ObsidianImport, error) {
+	vault, err := filepath.Abs(vault)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(vault); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", vault)
+	}
+	if !fileExists(filepath.Join(vault, ".obsidian")) {
+		return nil, fmt.Errorf("%s is not an Obsidian vault (no .obsidian folder)", vault)
+	}
+
+	plan := &ObsidianImport{Vault: vault, Notes: []ImportedNote{}, Attachments: []string{}}
+	plan.AttachmentFolder = obsidianAttachmentFolder(vault)
+
+	err = filepath.WalkDir(vault, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(d.Name(), ".") && p != vault {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(vault, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !strings.EqualFold(path.Ext(rel), ".md") {
+			plan.Attachments = append(plan.Attachments, rel)
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		plan.Notes = append(plan.Notes, ImportedNote{Path: rel, Content: content, ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := newObsidianResolver(plan)
+	for i := range plan.Notes {
+		note := &plan.Notes[i]
+		original := *note
+		if err := convertObsidianNote(note, resolver); err != nil {
+			// Copy notes jot can't read unchanged rather than fail the vault
+			*note = original
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("%s: copied unchanged: %v", note.Path, err))
+		}
+	}
+	return plan, nil
+}
+
+// obsidianAttachmentFolder reads the attachment folder setting of a vault.
+// "./" prefixed values are relative to each note's folder.
+func obsidianAttachmentFolder(vault string) string {
+	data, err := os.ReadFile(filepath.Join(vault, ".obsidian", "app.json"))
+	if err != nil {
+		return ""
+	}
+	var app struct {
+		AttachmentFolderPath string `json:"attachmentFolderPath"`
+	}
+	if json.Unmarshal(data, &app) != nil {
+		return ""
+	}
+	return strings.Trim(app.AttachmentFolderPath, "/")
+}
+
+// convertObsidianNote converts a note in place, recording what changed.
+func convertObsidianNote(note *ImportedNote, resolver *obsidianResolver) error {
+	metadata, _, err := parseFrontmatterStrict(note.Content)
+	if err != nil {
+		return err
+	}
+	frontmatter, body, hasFrontmatter := splitFrontmatter(note.Content)
+
+	var tags []string
+	newBody := mapProse(string(body), func(text string) string {
+		prose := linkDestinationPattern.ReplaceAllString(text, "]")
+		for _, match := range inlineTagPattern.FindAllStringSubmatch(prose, -1) {
+			tags = append(tags, match[2])
+		}
+		return obsidianLinkPattern.ReplaceAllStringFunc(text, func(link string) string {
+			return resolver.convertLink(note, link)
+		})
+	})
+
+	set := make(map[string]any)
+	var unset []string
+
+	existing := obsidianList(metadata["tags"], true)
+	existing = append(existing, obsidianList(metadata["tag"], true)...)
+	merged := existing
+	for _, tag := range tags {
+		if !hasFold(merged, tag) {
+			merged = append(merged, tag)
+			note.Tags = append(note.Tags, tag)
+		}
+	}
+	_, tagsList := metadata["tags"].([]any)
+	if len(merged) > 0 && (len(note.Tags) > 0 || !tagsList || metadata["tag"] != nil) {
+		set["tags"] = merged
+		note.Fields = append(note.Fields, "tags")
+	}
+	if _, ok := metadata["tag"]; ok {
+		unset = append(unset, "tag")
+	}
+
+	aliases := append(obsidianList(metadata["aliases"], false), obsidianList(metadata["alias"], false)...)
+	_, aliasesList := metadata["aliases"].([]any)
+	if len(aliases) > 0 && (!aliasesList || metadata["alias"] != nil) {
+		set["aliases"] = aliases
+		note.Aliases = aliases
+		note.Fields = append(note.Fields, "aliases")
+	}
+	if _, ok := metadata["alias"]; ok {
+		unset = append(unset, "alias")
+	}
+
+	if extractTitle(metadata) == "" {
+		set["title"] = strings.TrimSuffix(path.Base(note.Path), path.Ext(note.Path))
+		note.Fields = append(note.Fields, "title")
+	}
+	stamp := note.ModTime.Format(time.RFC3339)
+	for _, field := range []string{"created", "modified"} {
+		if _, ok := metadata[field]; !ok {
+			set[field] = stamp
+			note.Fields = append(note.Fields, field)
+		}
+	}
+
+	content := []byte(newBody)
+	if hasFrontmatter {
+		content = append([]byte("---\n"+string(frontmatter)+"---\n"), content...)
+	}
+	if len(set) > 0 || len(unset) > 0 {
+		if content, err = UpdateFrontmatter(content, set, unset); err != nil {
+			return err
+		}
+	}
+
+	note.changed = string(content) != string(note.Content)
+	note.Content = content
+	return nil
+}
+
+// mapProse applies fn to the parts of markdown outside fenced code blocks
+// and inline code spans.
+func mapProse(text string, fn func(string) string) string {
+	lines := strings.SplitAfter(text, "\n")
+	var fence string
+	for i, line := range lines {
+		if m := codeFencePattern.FindStringSubmatch(line); m != nil {
+			switch {
+			case fence == "":
+				fence = m[1]
+			case fence == m[1]:
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		// Odd parts are code spans, unless an unmatched backtick opens them
+		parts := strings.Split(line, "`")
+		for j := range parts {
+			if j%2 == 0 || j+1 == len(parts) {
+				parts[j] = fn(parts[j])
+			}
+		}
+		lines[i] = strings.Join(parts, "`")
+	}
+	return strings.Join(lines, "")
+}
+
+// obsidianList reads a frontmatter list that Obsidian also accepts as a
+// comma separated string, or for tags space separated. Tags lose a leading
+// "#".
+func obsidianList(value any, tags bool) []string {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		items = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || tags && r == ' ' })
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if tags {
+			item = strings.TrimPrefix(item, "#")
+		}
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func hasFold(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// obsidianResolver resolves links the way Obsidian does and compares the
+// result with jot's link graph.
+type obsidianResolver struct {
+	attachmentFolder string
+	notes            []string // vault paths
+	attachments      []string
+	graph            *LinkGraph
+}
+
+func newObsidianResolver(plan *ObsidianImport) *obsidianResolver {
+	r := &obsidianResolver{attachmentFolder: plan.AttachmentFolder, attachments: plan.Attachments}
+
+	// jot resolves [[names]] by title too, which the import sets from the
+	// file name
+	notes := make([]Note, len(plan.Notes))
+	for i, imported := range plan.Notes {
+		r.notes = append(r.notes, imported.Path)
+		metadata, _ := parseFrontmatter(imported.Content)
+		if extractTitle(metadata) == "" {
+			metadata["title"] = strings.TrimSuffix(path.Base(imported.Path), path.Ext(imported.Path))
+		}
+		notes[i].File.Relative = imported.Path
+		notes[i].Metadata = metadata
+	}
+	r.graph = NewLinkGraph(notes)
+	return r
+}
+
+// convertLink rewrites one [[link]] or ![[embed]] written in note.
+func (r *obsidianResolver) convertLink(note *ImportedNote, link string) string {
+	m := obsidianLinkPattern.FindStringSubmatch(link)
+	target, label := strings.TrimSpace(m[2]), m[3]
+	name, anchor := target, ""
+	if i := strings.Index(target, "#"); i >= 0 {
+		name, anchor = target[:i], target[i:]
+	}
+	if name == "" {
+		// A link to a heading of the same note
+		return link
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	if ext != "" && ext != ".md" {
+		attachment := r.resolveAttachment(note.Path, name)
+		if attachment == "" {
+			note.Unresolved = append(note.Unresolved, name)
+			return link
+		}
+		note.Embeds++
+		if label == "" || isSizeLabel(label) {
+			label = path.Base(name)
+		}
+		return fmt.Sprintf("%s[%s](%s)", m[1], label, markdownPath(relativePath(note.Path, attachment)))
+	}
+
+	resolved := r.resolveNote(note.Path, name)
+	if resolved == "" {
+		note.Unresolved = append(note.Unresolved, name)
+		return link
+	}
+	if r.graph.Resolve(note.Path, strings.TrimSpace(name), LinkWiki) == resolved {
+		return link
+	}
+
+	note.Links++
+	if label == "" {
+		label = target
+	}
+	return fmt.Sprintf("%s[[%s%s|%s]]", m[1], strings.TrimSuffix(resolved, path.Ext(resolved)), anchor, label)
+}
+
+// resolveNote finds the note an Obsidian link names: the vault path, else
+// the note with that file name, preferring the linking note's folder and
+// then the shortest path.
+func (r *obsidianResolver) resolveNote(source, name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "/"), ".md")
+	return r.resolve(source, name+".md", r.notes)
+}
+
+// resolveAttachment finds the file an attachment link names, looking in the
+// vault's attachment folder first.
+func (r *obsidianResolver) resolveAttachment(source, name string) string {
+	name = strings.TrimPrefix(name, "/")
+	if !strings.Contains(name, "/") {
+		folder := r.attachmentFolder
+		if rest, ok := strings.CutPrefix(folder, "."); ok {
+			folder = path.Join(path.Dir(source), rest)
+		}
+		candidate := path.Join(folder, name)
+		for _, attachment := range r.attachments {
+			if strings.EqualFold(attachment, candidate) {
+				return attachment
+			}
+		}
+	}
+	return r.resolve(source, name, r.attachments)
+}
+
+func (r *obsidianResolver) resolve(source, name string, paths []string) string {
+	var matches []string
+	for _, p := range paths {
+		if strings.EqualFold(p, name) {
+			return p
+		}
+		if strings.HasSuffix(strings.ToLower(p), "/"+strings.ToLower(name)) {
+			matches = append(matches, p)
+		}
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+	dir := path.Dir(source)
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := path.Dir(matches[i]) == dir, path.Dir(matches[j]) == dir
+		if a != b {
+			return a
+		}
+		if len(matches[i]) != len(matches[j]) {
+			return len(matches[i]) < len(matches[j])
+		}
+		return matches[i] < matches[j]
+	})
+	return matches[0]
+}
+
+// isSizeLabel reports whether an embed label is an image size like "300"
+// or "300x200" rather than text.
+func isSizeLabel(label string) bool {
+	return sizeLabelPattern.MatchString(strings.TrimSpace(label))
+}
+
+// relativePath returns the path of target relative to the folder of source.
+func relativePath(source, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(source)), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// markdownPath escapes a path for a markdown link target.
+func markdownPath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// ImportObsidian writes an import plan. Without a target the vault itself
+// becomes the notebook (created unless it already is one) and its notes are
+// rewritten in place; otherwise a new notebook is created at target and
+// the notes and attachments are copied into it through the notebook's
+// storage and indexed. File times are kept in local storage.
+func (s *NotebookService) ImportObsidian(plan *ObsidianImport, target, name string, register bool) (*Notebook, error) {
+	inPlace := target == ""
+	if inPlace {
+		target = plan.Vault
+	} else {
+		abs, err := filepath.Abs(target)
+		if err != nil {
+			return nil, err
+		}
+		target = abs
+		if entries, err := os.ReadDir(target); err == nil && len(entries) > 0 {
+			return nil, fmt.Errorf("%s is not empty", target)
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if name == "" {
+		name = filepath.Base(plan.Vault)
+	}
+
+	var nb *Notebook
+	var err error
+	if inPlace && s.HasNotebook(target) {
+		nb, err = s.Open(target)
+	} else {
+		nb, err = s.Create(name, target, register)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	write := func(rel string, content []byte, modTime time.Time) error {
+		if err := nb.Storage.Write(rel, content); err != nil {
+			return err
+		}
+		if !nb.Config.Storage.IsLocal() {
+			return nil
+		}
+		dest := filepath.Join(nb.Config.Root, filepath.FromSlash(rel))
+		return os.Chtimes(dest, modTime, modTime)
+	}
+
+	for _, note := range plan.Notes {
+		if inPlace && !note.Changed() {
+			continue
+		}
+		if err := write(note.Path, note.Content, note.ModTime); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", note.Path, err)
+		}
+		if err := nb.Notes.IndexFile(context.Background(), note.Path); err != nil {
+			nb.Notes.log.Warn().Err(err).Str("path", note.Path).Msg("failed to index imported note")
+		}
+	}
+	if !inPlace {
+		for _, attachment := range plan.Attachments {
+			src := filepath.Join(plan.Vault, filepath.FromSlash(attachment))
+			info, err := os.Stat(src)
+			if err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(src)
+			if err != nil {
+				return nil, err
+			}
+			if err := write(attachment, content, info.ModTime()); err != nil {
+				return nil, fmt.Errorf("failed to copy %s: %w", attachment, err)
+			}
+		}
+	}
+	return nb, nil
+}
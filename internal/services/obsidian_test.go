@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createVault writes an Obsidian vault with the given files, all modified
+// at the same time.
+func createVault(t *testing.T, files map[string]string) (string, time.Time) {
+	t.Helper()
+	vault := filepath.Join(t.TempDir(), "vault")
+	modTime := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	for rel, content := range files {
+		p := filepath.Join(vault, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+		require.NoError(t, os.WriteFile(p, []byte(content), 0644))
+		require.NoError(t, os.Chtimes(p, modTime, modTime))
+	}
+	return vault, modTime
+}
+
+func findImported(t *testing.T, plan *ObsidianImport, rel string) *ImportedNote {
+	t.Helper()
+	for i := range plan.Notes {
+		if plan.Notes[i].Path == rel {
+			return &plan.Notes[i]
+		}
+	}
+	t.Fatalf("note %s not in plan", rel)
+	return nil
+}
+
+func TestPlanObsidianImport(t *testing.T) {
+	vault, _ := createVault(t, map[string]string{
+		".obsidian/app.json":  `{"attachmentFolderPath": "attachments/"}`,
+		".trash/old.md":       "# Old\n",
+		"attachments/img.png": "png",
+		"a/Plan.md":           "Plan A\n",
+		"b/Plan.md":           "Plan B\n",
+		"b/Launch.md": "---\ntitle: Launch\naliases: Go live, Release\ntags: work\ncreated: 2024-01-01\nmodified: 2024-01-02\n---\n" +
+			"# Heading\n\nSee [[Plan]], [[Plan#Goals|goals]] and [[Nowhere]].\n" +
+			"![[img.png|300]] #launch #2024 `#code`\n```\n#fenced [[Plan]]\n```\n",
+		"Done.md": "---\ntitle: Done\ntags: [work]\naliases: [Finished]\ncreated: 2024-01-01\nmodified: 2024-01-02\n---\nNothing to do (#later). See [the setup](#setup).\n",
+	})
+
+	plan, err := PlanObsidianImport(vault)
+	require.NoError(t, err)
+	assert.Equal(t, "attachments", plan.AttachmentFolder)
+	assert.Equal(t, []string{"attachments/img.png"}, plan.Attachments)
+	assert.Len(t, plan.Notes, 4, "hidden folders are skipped")
+
+	launch := findImported(t, plan, "b/Launch.md")
+	assert.True(t, launch.Changed())
+	assert.Equal(t, []string{"launch"}, launch.Tags)
+	assert.Equal(t, []string{"Go live", "Release"}, launch.Aliases)
+	assert.Equal(t, 2, launch.Links)
+	assert.Equal(t, 1, launch.Embeds)
+	assert.Equal(t, []string{"Nowhere"}, launch.Unresolved)
+	assert.Equal(t, "---\ntitle: Launch\naliases:\n  - Go live\n  - Release\ntags:\n  - work\n  - launch\ncreated: 2024-01-01\nmodified: 2024-01-02\n---\n"+
+		"# Heading\n\nSee [[b/Plan|Plan]], [[b/Plan#Goals|goals]] and [[Nowhere]].\n"+
+		"![img.png](../attachments/img.png) #launch #2024 `#code`\n```\n#fenced [[Plan]]\n```\n", string(launch.Content))
+
+	done := findImported(t, plan, "Done.md")
+	assert.Equal(t, []string{"later"}, done.Tags, "link anchors are not tags")
+}
+
+func TestPlanObsidianImport_MalformedFrontmatter(t *testing.T) {
+	broken := "---\ntitle: [unclosed\n---\nSee [[Good]] #tag\n"
+	vault, _ := createVault(t, map[string]string{
+		".obsidian/app.json": `{}`,
+		"Broken.md":          broken,
+		"Good.md":            "Fine #ok\n",
+	})
+
+	plan, err := PlanObsidianImport(vault)
+	require.NoError(t, err, "one bad note does not fail the vault")
+	require.Len(t, plan.Warnings, 1)
+	assert.Contains(t, plan.Warnings[0], "Broken.md: copied unchanged")
+
+	bad := findImported(t, plan, "Broken.md")
+	assert.False(t, bad.Changed())
+	assert.Equal(t, broken, string(bad.Content))
+	assert.Empty(t, bad.Tags)
+	good := findImported(t, plan, "Good.md")
+	assert.True(t, good.Changed())
+	assert.Equal(t, []string{"ok"}, good.Tags)
+}
+
+func TestPlanObsidianImport_Defaults(t *testing.T) {
+	vault, modTime := createVault(t, map[string]string{
+		".obsidian/app.json": "{}",
+		"Daily Note.md":      "#journal/daily\n\n[[Other]]\n",
+		"Other.md":           "---\nalias: Another\ntag: \"#x, y\"\n---\n",
+	})
+
+	plan, err := PlanObsidianImport(vault)
+	require.NoError(t, err)
+	assert.Empty(t, plan.AttachmentFolder)
+
+	daily := findImported(t, plan, "Daily Note.md")
+	stamp := modTime.Format(time.RFC3339)
+	assert.Equal(t, "---\ncreated: \""+stamp+"\"\nmodified: \""+stamp+"\"\ntags:\n  - journal/daily\ntitle: Daily Note\n---\n\n#journal/daily\n\n[[Other]]\n", string(daily.Content))
+	assert.Zero(t, daily.Links, "jot already resolves the link")
+
+	other := findImported(t, plan, "Other.md")
+	assert.Equal(t, []string{"Another"}, other.Aliases)
+	assert.Equal(t, []string{"tags", "aliases", "title", "created", "modified"}, other.Fields)
+	assert.Contains(t, string(other.Content), "tags:\n  - x\n  - \"y\"\n")
+	assert.NotContains(t, string(other.Content), "alias:")
+	assert.NotContains(t, string(other.Content), "tag:")
+}
+
+func TestPlanObsidianImport_NotAVault(t *testing.T) {
+	_, err := PlanObsidianImport(t.TempDir())
+	assert.ErrorContains(t, err, "is not an Obsidian vault")
+}
+
+func TestMapProse(t *testing.T) {
+	upper := func(s string) string { return strings.ToUpper(s) }
+	assert.Equal(t, "A `b` C\n```go\nd\n```\nE ~~~\n", mapProse("a `b` c\n```go\nd\n```\ne ~~~\n", upper))
+	assert.Equal(t, "A `B\n", mapProse("a `b\n", upper), "an unmatched backtick is prose")
+	assert.Equal(t, "~~~\n```\nx\n~~~\nY\n", mapProse("~~~\n```\nx\n~~~\ny\n", upper))
+}
+
+func TestNotebookService_ImportObsidian(t *testing.T) {
+	files := map[string]string{
+		".obsidian/app.json":  `{"attachmentFolderPath": "attachments"}`,
+		"attachments/img.png": "png",
+		"notes/Idea.md":       "An idea #someday\n![[img.png]]\n",
+		"Kept.md":             "---\ntitle: Kept\ncreated: 2024-01-01\nmodified: 2024-01-01\n---\nUnchanged\n",
+	}
+
+	t.Run("into a new notebook", func(t *testing.T) {
+		vault, modTime := createVault(t, files)
+		plan, err := PlanObsidianImport(vault)
+		require.NoError(t, err)
+
+		svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+		target := filepath.Join(t.TempDir(), "notes")
+		nb, err := svc.ImportObsidian(plan, target, "", false)
+		require.NoError(t, err)
+		assert.Equal(t, "vault", nb.Config.Name)
+
+		root := nb.Config.Root
+		assert.FileExists(t, filepath.Join(root, "attachments", "img.png"))
+		assert.FileExists(t, filepath.Join(root, "Kept.md"))
+		content, err := os.ReadFile(filepath.Join(root, "notes", "Idea.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "![img.png](../attachments/img.png)")
+		info, err := os.Stat(filepath.Join(root, "notes", "Idea.md"))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(modTime))
+		indexed, err := nb.Notes.getAllNotes(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, indexed, 2, "imported notes are indexed")
+
+		original, err := os.ReadFile(filepath.Join(vault, "notes", "Idea.md"))
+		require.NoError(t, err)
+		assert.Equal(t, files["notes/Idea.md"], string(original), "the vault is untouched")
+
+		_, err = svc.ImportObsidian(plan, target, "", false)
+		assert.ErrorContains(t, err, "is not empty")
+	})
+
+	t.Run("in place", func(t *testing.T) {
+		vault, _ := createVault(t, files)
+		plan, err := PlanObsidianImport(vault)
+		require.NoError(t, err)
+
+		svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+		nb, err := svc.ImportObsidian(plan, "", "Vault", false)
+		require.NoError(t, err)
+		assert.Equal(t, vault, nb.Config.Root)
+		assert.FileExists(t, filepath.Join(vault, ".jot.json"))
+
+		content, err := os.ReadFile(filepath.Join(vault, "notes", "Idea.md"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "tags:\n  - someday\n")
+
+		// Converting again opens the notebook and finds nothing to do
+		plan, err = PlanObsidianImport(vault)
+		require.NoError(t, err)
+		for _, note := range plan.Notes {
+			assert.False(t, note.Changed(), note.Path)
+		}
+		_, err = svc.ImportObsidian(plan, "", "", false)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Periods supported by periodic notes.
+const (
+	PeriodDaily   = "daily"
+	PeriodWeekly  = "weekly"
+	PeriodMonthly = "monthly"
+)
+
+// PeriodicConfig configures one kind of periodic note in .jot.json.
+type PeriodicConfig struct {
+	// Path is a text/template for the note path relative to the notebook
+	// root. See PeriodicPathData for the available fields.
+	Path string `json:"path,omitempty"`
+
+	// Template is the note template used when the note is created.
+	Template string `json:"template,omitempty"`
+}
+
+// defaultPeriodicPaths are used when a period has no configured path.
+var defaultPeriodicPaths = map[string]string{
+	PeriodDaily:   "journal/{{ .Date }}.md",
+	PeriodWeekly:  "journal/{{ .WeekYear }}-W{{ .Week }}.md",
+	PeriodMonthly: "journal/{{ .Year }}-{{ .Month }}.md",
+}
+
+// PeriodicPathData is the data available to periodic path patterns.
+type PeriodicPathData struct {
+	// Date is the start of the period as YYYY-MM-DD.
+	Date string
+	// Year, Month and Day are the zero-padded parts of Date.
+	Year, Month, Day string
+	// Week and WeekYear are the zero-padded ISO 8601 week and its year.
+	Week, WeekYear string
+}
+
+// PeriodicNote identifies the periodic note for a period.
+type PeriodicNote struct {
+	// Period is daily, weekly or monthly.
+	Period string `json:"period"`
+	// Date is the start of the period.
+	Date time.Time `json:"date"`
+	// Path is the note path relative to the notebook root.
+	Path string `json:"path"`
+	// Title is the note title.
+	Title string `json:"title"`
+}
+
+// ValidPeriod reports whether period is a supported period.
+func ValidPeriod(period string) bool {
+	_, ok := defaultPeriodicPaths[period]
+	return ok
+}
+
+// PeriodStart returns the first day of the period containing t, at midnight.
+// Weeks start on Monday.
+func PeriodStart(period string, t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch period {
+	case PeriodWeekly:
+		return getStartOfWeek(day)
+	case PeriodMonthly:
+		return getFirstOfMonth(day)
+	default:
+		return day
+	}
+}
+
+// ShiftPeriod moves the period containing t by n periods and returns its start.
+func ShiftPeriod(period string, t time.Time, n int) time.Time {
+	start := PeriodStart(period, t)
+	switch period {
+	case PeriodWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case PeriodMonthly:
+		return start.AddDate(0, n, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// Periodic returns the configuration for period with defaults applied.
+func (c *StoredNotebookConfig) Periodic(period string) PeriodicConfig {
+	var cfg PeriodicConfig
+	if configured, ok := c.PeriodicNotes[period]; ok && configured != nil {
+		cfg = *configured
+	}
+	if cfg.Path == "" {
+		cfg.Path = defaultPeriodicPaths[period]
+	}
+	return cfg
+}
+
+// PeriodicNote returns the periodic note for the period containing date.
+func (c *StoredNotebookConfig) PeriodicNote(period string, date time.Time) (PeriodicNote, error) {
+	if !ValidPeriod(period) {
+		return PeriodicNote{}, fmt.Errorf("unknown period %q (expected %s, %s or %s)", period, PeriodDaily, PeriodWeekly, PeriodMonthly)
+	}
+
+	start := PeriodStart(period, date)
+	relPath, err := periodicPath(c.Periodic(period).Path, start)
+	if err != nil {
+		return PeriodicNote{}, fmt.Errorf("invalid %s path pattern: %w", period, err)
+	}
+
+	return PeriodicNote{
+		Period: period,
+		Date:   start,
+		Path:   relPath,
+		Title:  periodTitle(period, start),
+	}, nil
+}
+
+// Frontmatter returns the frontmatter a new periodic note carries. created
+// is the start of the period so date queries such as created:>=this-week find
+// the note by the period it covers rather than when it was written.
+func (p PeriodicNote) Frontmatter() map[string]any {
+	return map[string]any{
+		"title":   p.Title,
+		"period":  p.Period,
+		"date":    p.Date.Format("2006-01-02"),
+		"created": p.Date.Format(time.RFC3339),
+	}
+}
+
+// periodicPath renders a path pattern for the period starting at start.
+func periodicPath(pattern string, start time.Time) (string, error) {
+	weekYear, week := start.ISOWeek()
+	data := PeriodicPathData{
+		Date:     start.Format("2006-01-02"),
+		Year:     start.Format("2006"),
+		Month:    start.Format("01"),
+		Day:      start.Format("02"),
+		Week:     fmt.Sprintf("%02d", week),
+		WeekYear: fmt.Sprintf("%04d", weekYear),
+	}
+	return renderPathPattern(pattern, template.FuncMap{"date": start.Format}, data)
+}
+
+// renderPathPattern renders a text/template note path relative to the
+// notebook root, adding .md when missing.
+func renderPathPattern(pattern string, funcs template.FuncMap, data any) (string, error) {
+	tmpl, err := template.New("path").Funcs(funcs).Option("missingkey=error").Parse(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+
+	return cleanNotePath(buf.String())
+}
+
+// cleanNotePath normalises a rendered note path, which must stay inside
+// the notebook, adding .md when missing.
+func cleanNotePath(rendered string) (string, error) {
+	relPath := path.Clean(strings.TrimPrefix(strings.TrimSpace(rendered), "/"))
+	if relPath == "." || relPath == ".." || strings.HasPrefix(relPath, "../") {
+		return "", fmt.Errorf("%q is outside the notebook", relPath)
+	}
+	if !strings.HasSuffix(relPath, ".md") {
+		relPath += ".md"
+	}
+	return relPath, nil
+}
+
+// periodTitle returns the title of a periodic note.
+func periodTitle(period string, start time.Time) string {
+	switch period {
+	case PeriodWeekly:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	case PeriodMonthly:
+		return start.Format("2006-01")
+	default:
+		return start.Format("2006-01-02")
+	}
+}
+
+// PeriodicNotes returns the notes created as periodic notes of the given
+// period, identified by their period and date frontmatter, oldest first.
+func (s *NoteService) PeriodicNotes(ctx context.Context, period string) ([]PeriodicNote, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PeriodicNote
+	for _, note := range notes {
+		if p, _ := note.Metadata["period"].(string); p != period {
+			continue
+		}
+		date, ok := periodicDate(note.Metadata["date"])
+		if !ok {
+			continue
+		}
+		result = append(result, PeriodicNote{
+			Period: period,
+			Date:   PeriodStart(period, date),
+			Path:   note.File.Relative,
+			Title:  note.DisplayName(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Date.Equal(result[j].Date) {
+			return result[i].Path < result[j].Path
+		}
+		return result[i].Date.Before(result[j].Date)
+	})
+	return result, nil
+}
+
+// AdjacentPeriodicNote returns the nearest existing periodic note before
+// (dir < 0) or after (dir > 0) the period containing date.
+func (s *NoteService) AdjacentPeriodicNote(ctx context.Context, period string, date time.Time, dir int) (*PeriodicNote, error) {
+	notes, err := s.PeriodicNotes(ctx, period)
+	if err != nil {
+		return nil, err
+	}
+
+	start := PeriodStart(period, date)
+	if dir < 0 {
+		for i := len(notes) - 1; i >= 0; i-- {
+			if notes[i].Date.Before(start) {
+				return &notes[i], nil
+			}
+		}
+		return nil, fmt.Errorf("no %s note before %s", period, start.Format("2006-01-02"))
+	}
+
+	for i := range notes {
+		if notes[i].Date.After(start) {
+			return &notes[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no %s note after %s", period, start.Format("2006-01-02"))
+}
+
+// periodicDate parses the date frontmatter of a periodic note in local time.
+func periodicDate(value any) (time.Time, bool) {
+	switch v := value.(type) {
+	case time.Time:
+		return time.Date(v.Year(), v.Month(), v.Day(), 0, 0, 0, 0, time.Local), true
+	case string:
+		t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		return t, err == nil
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+func TestPeriodStartAndShift(t *testing.T) {
+	// Wednesday
+	date := time.Date(2024, 5, 1, 15, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, "2024-05-01", services.PeriodStart(services.PeriodDaily, date).Format("2006-01-02"))
+	assert.Equal(t, "2024-04-29", services.PeriodStart(services.PeriodWeekly, date).Format("2006-01-02"))
+	assert.Equal(t, "2024-05-01", services.PeriodStart(services.PeriodMonthly, date).Format("2006-01-02"))
+
+	assert.Equal(t, "2024-04-30", services.ShiftPeriod(services.PeriodDaily, date, -1).Format("2006-01-02"))
+	assert.Equal(t, "2024-05-13", services.ShiftPeriod(services.PeriodWeekly, date, 2).Format("2006-01-02"))
+	assert.Equal(t, "2023-12-01", services.ShiftPeriod(services.PeriodMonthly, date, -5).Format("2006-01-02"))
+}
+
+func TestPeriodicNote_DefaultPaths(t *testing.T) {
+	var cfg services.StoredNotebookConfig
+	date := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		path   string
+		title  string
+	}{
+		{services.PeriodDaily, "journal/2024-12-31.md", "2024-12-31"},
+		// ISO week 1 of 2025 starts on Monday 2024-12-30
+		{services.PeriodWeekly, "journal/2025-W01.md", "2025-W01"},
+		{services.PeriodMonthly, "journal/2024-12.md", "2024-12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			note, err := cfg.PeriodicNote(tt.period, date)
+			require.NoError(t, err)
+			assert.Equal(t, tt.path, note.Path)
+			assert.Equal(t, tt.title, note.Title)
+		})
+	}
+
+	_, err := cfg.PeriodicNote("yearly", date)
+	assert.ErrorContains(t, err, `unknown period "yearly"`)
+}
+
+func TestPeriodicNote_ConfiguredPath(t *testing.T) {
+	cfg := services.StoredNotebookConfig{
+		PeriodicNotes: map[string]*services.PeriodicConfig{
+			services.PeriodDaily:   {Path: `diary/{{ .Year }}/{{ date "01-Jan" }}/{{ .Day }}`, Template: "day"},
+			services.PeriodMonthly: {Path: "../{{ .Year }}.md"},
+		},
+	}
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	note, err := cfg.PeriodicNote(services.PeriodDaily, date)
+	require.NoError(t, err)
+	assert.Equal(t, "diary/2024/05-May/01.md", note.Path)
+	assert.Equal(t, "day", cfg.Periodic(services.PeriodDaily).Template)
+
+	_, err = cfg.PeriodicNote(services.PeriodMonthly, date)
+	assert.ErrorContains(t, err, "outside the notebook")
+}
+
+func TestNoteService_AdjacentPeriodicNote(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	require.NoError(t, os.MkdirAll(filepath.Join(notebookDir, "notes", "journal"), 0755))
+	for _, date := range []string{"2024-04-28", "2024-05-01", "2024-05-06"} {
+		testutil.CreateTestNote(t, notebookDir, "journal/"+date+".md",
+			"---\nperiod: daily\ndate: \""+date+"\"\n---\n\n# "+date+"\n")
+	}
+	testutil.CreateTestNote(t, notebookDir, "journal/2024-W18.md", "---\nperiod: weekly\ndate: \"2024-04-29\"\n---\n")
+
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	notes, err := svc.PeriodicNotes(ctx, services.PeriodDaily)
+	require.NoError(t, err)
+	require.Len(t, notes, 3)
+
+	date := time.Date(2024, 5, 3, 0, 0, 0, 0, time.Local)
+
+	prev, err := svc.AdjacentPeriodicNote(ctx, services.PeriodDaily, date, -1)
+	require.NoError(t, err)
+	assert.Equal(t, "notes/journal/2024-05-01.md", prev.Path)
+
+	next, err := svc.AdjacentPeriodicNote(ctx, services.PeriodDaily, date, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "notes/journal/2024-05-06.md", next.Path)
+
+	_, err = svc.AdjacentPeriodicNote(ctx, services.PeriodDaily, time.Date(2024, 4, 28, 0, 0, 0, 0, time.Local), -1)
+	assert.ErrorContains(t, err, "no daily note before 2024-04-28")
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/parser"
+)
+
+// Record formats written by ExportRecords.
+const (
+	RecordFormatJSONL = "jsonl"
+	RecordFormatCSV   = "csv"
+)
+
+// DefaultRecordFields are the fields of exported records when none are
+// chosen.
+var DefaultRecordFields = []string{"path", "title", "tags", "created", "modified"}
+
+// recordFields are the fields read from the index rather than frontmatter.
+var recordFields = map[string]bool{
+	"path": true, "id": true, "title": true, "tags": true,
+	"created": true, "modified": true, "lead": true, "body": true,
+}
+
+// recordBatchSize is how many notes are read from the index at a time.
+const recordBatchSize = 200
+
+// RecordExportOptions controls exporting notes as records.
+type RecordExportOptions struct {
+	// Where is a filter query selecting the notes. Empty exports all.
+	Where string
+	// Fields are the record fields in order. Empty uses
+	// DefaultRecordFields.
+	Fields []string
+	// Body appends the note body to the fields.
+	Body bool
+}
+
+// ExportRecords writes a record per note to w in format, in path order.
+// Notes are read from the index in batches rather than all at once.
+//
+// Values have the same type in every record: path, id, title, lead and
+// body are strings, tags an array of strings, created and modified RFC3339
+// timestamps. Frontmatter fields are typed by the notebook schema, so date
+// fields are RFC3339 and list fields are arrays even with one item. Missing
+// fields are null in JSONL and empty in CSV, where arrays and objects are
+// written as JSON.
+func (n *Notebook) ExportRecords(ctx context.Context, w io.Writer, format string, opts RecordExportOptions) (int, error) {
+	fields, err := recordFieldList(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	find := search.FindOpts{Sort: search.SortSpec{Field: search.SortByPath, Direction: search.SortAsc}}
+	if where := strings.TrimSpace(resolveTemplateVariables(opts.Where, time.Now())); where != "" {
+		if filter, directives := SplitViewQuery(where); directives != "" {
+			return 0, fmt.Errorf("filter query can't have directives: %q", directives)
+		} else if filter != "" {
+			query, err := parser.New().Parse(filter)
+			if err != nil {
+				return 0, fmt.Errorf("failed to parse filter: %w", err)
+			}
+			find = find.WithQuery(query).WithRawQuery(filter)
+		}
+	}
+
+	var write func(values []any) error
+	var flush func() error
+	switch format {
+	case RecordFormatJSONL:
+		write = func(values []any) error { return writeJSONRecord(w, fields, values) }
+		flush = func() error { return nil }
+	case RecordFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(fields); err != nil {
+			return 0, err
+		}
+		write = func(values []any) error { return writeCSVRecord(cw, values) }
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	default:
+		return 0, fmt.Errorf("unknown record format %q (expected jsonl or csv)", format)
+	}
+
+	index := n.Notes.GetIndex()
+	count := 0
+	for find.Offset = 0; ; find.Offset += recordBatchSize {
+		find.Limit = recordBatchSize
+		results, err := index.Find(ctx, find)
+		if err != nil {
+			return count, fmt.Errorf("search failed: %w", err)
+		}
+		for _, item := range results.Items {
+			if err := write(n.recordValues(item.Document, fields)); err != nil {
+				return count, err
+			}
+			count++
+		}
+		if len(results.Items) < recordBatchSize {
+			break
+		}
+	}
+	return count, flush()
+}
+
+func recordFieldList(opts RecordExportOptions) ([]string, error) {
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = DefaultRecordFields
+	}
+	if opts.Body {
+		fields = append(fields[:len(fields):len(fields)], "body")
+	}
+
+	seen := make(map[string]bool, len(fields))
+	unique := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, fmt.Errorf("empty field name")
+		}
+		if !seen[field] {
+			seen[field] = true
+			unique = append(unique, field)
+		}
+	}
+	return unique, nil
+}
+
+// recordValues returns the values of fields for a note.
+func (n *Notebook) recordValues(doc search.Document, fields []string) []any {
+	var schemas map[string]FieldSchema
+	values := make([]any, len(fields))
+	for i, field := range fields {
+		if recordFields[field] {
+			values[i] = documentField(doc, field)
+			continue
+		}
+		if schemas == nil {
+			schemas = n.Config.FieldSchemas(doc.Path)
+		}
+		values[i] = recordValue(doc.Metadata[field], schemas[field].Type)
+	}
+	return values
+}
+
+func documentField(doc search.Document, field string) any {
+	switch field {
+	case "path":
+		return doc.Path
+	case "id":
+		return doc.ID
+	case "title":
+		return doc.Title
+	case "lead":
+		return doc.Lead
+	case "body":
+		return doc.Body
+	case "tags":
+		if doc.Tags == nil {
+			return []string{}
+		}
+		return doc.Tags
+	case "created":
+		return recordTime(doc.Created)
+	case "modified":
+		return recordTime(doc.Modified)
+	}
+	return nil
+}
+
+func recordTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.Format(time.RFC3339)
+}
+
+// recordValue normalizes a frontmatter value read from the index to the
+// type of its field schema.
+func recordValue(value any, fieldType string) any {
+	switch fieldType {
+	case FieldTypeList:
+		switch v := value.(type) {
+		case nil:
+			return []any{}
+		case []any:
+			return recordValue(v, "")
+		default:
+			return []any{recordValue(v, "")}
+		}
+	case FieldTypeDate, FieldTypeDatetime:
+		if s, ok := value.(string); ok {
+			for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+				if t, err := time.Parse(layout, s); err == nil {
+					return t.Format(time.RFC3339)
+				}
+			}
+		}
+	case FieldTypeNumber, FieldTypeInteger, FieldTypeBoolean:
+		if s, ok := value.(string); ok {
+			return ParseFieldValue(s)
+		}
+	}
+
+	switch v := value.(type) {
+	case time.Time:
+		return recordTime(v)
+	case []any:
+		items := make([]any, len(v))
+		for i, item := range v {
+			items[i] = recordValue(item, "")
+		}
+		return items
+	}
+	return value
+}
+
+// writeJSONRecord writes values as a JSON object on one line, with keys in
+// field order.
+func writeJSONRecord(w io.Writer, fields []string, values []any) error {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(field)
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field, err)
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteString("}\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func writeCSVRecord(w *csv.Writer, values []any) error {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			cells[i] = v
+		case bool:
+			cells[i] = strconv.FormatBool(v)
+		case float64:
+			cells[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		case int, int64, uint64:
+			cells[i] = fmt.Sprint(v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			cells[i] = string(data)
+		}
+	}
+	return w.Write(cells)
+}
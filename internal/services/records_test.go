@@ -0,0 +1,75 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var recordNotes = map[string]string{
+	"a.md": "---\ntitle: A\ntags: [work, x]\ncreated: 2024-01-02T03:04:05Z\nstatus: open\ndue: 2024-03-01\nrefs: [one]\nn: 3\n---\nBody \"A\"\n",
+	"b.md": "---\ntitle: B\ncreated: 2024-02-03T00:00:00Z\nstatus: done\n---\nBody B\n",
+}
+
+var recordSchema = map[string]*FieldSchema{
+	"due":  {Type: FieldTypeDate},
+	"refs": {Type: FieldTypeList},
+}
+
+func TestNotebook_ExportRecords(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, recordNotes)
+	nb.Config.Schema = recordSchema
+
+	var out bytes.Buffer
+	count, err := nb.ExportRecords(ctx, &out, RecordFormatJSONL, RecordExportOptions{
+		Fields: []string{"path", "tags", "created", "status", "due", "refs", "n", "missing"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t,
+		`{"path":"a.md","tags":["work","x"],"created":"2024-01-02T03:04:05Z","status":"open","due":"2024-03-01T00:00:00Z","refs":["one"],"n":3,"missing":null}`+"\n"+
+			`{"path":"b.md","tags":[],"created":"2024-02-03T00:00:00Z","status":"done","due":null,"refs":[],"n":null,"missing":null}`+"\n",
+		out.String())
+
+	out.Reset()
+	_, err = nb.ExportRecords(ctx, &out, RecordFormatCSV, RecordExportOptions{
+		Where:  "tag:work",
+		Fields: []string{"title", "tags", "n", "title"},
+		Body:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "title,tags,n,body\nA,\"[\"\"work\"\",\"\"x\"\"]\",3,\"Body \"\"A\"\"\n\"\n", out.String())
+
+	_, err = nb.ExportRecords(ctx, &out, RecordFormatCSV, RecordExportOptions{Where: "tag:work | sort:title"})
+	assert.ErrorContains(t, err, "can't have directives")
+	_, err = nb.ExportRecords(ctx, &out, "xml", RecordExportOptions{})
+	assert.ErrorContains(t, err, "unknown record format")
+	_, err = nb.ExportRecords(ctx, &out, RecordFormatJSONL, RecordExportOptions{Fields: []string{"title", ""}})
+	assert.ErrorContains(t, err, "empty field name")
+}
+
+func TestNotebook_ExportRecordsBatches(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, recordNotes)
+	nb.Config.Schema = recordSchema
+	for i := 0; i < recordBatchSize; i++ {
+		rel := fmt.Sprintf("many/%03d.md", i)
+		require.NoError(t, nb.Storage.Write(rel, []byte("# Note\n")))
+		require.NoError(t, nb.Notes.IndexFile(ctx, rel))
+	}
+
+	var out bytes.Buffer
+	count, err := nb.ExportRecords(ctx, &out, RecordFormatJSONL, RecordExportOptions{Fields: []string{"path"}})
+	require.NoError(t, err)
+	assert.Equal(t, recordBatchSize+2, count)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, count)
+	assert.Equal(t, `{"path":"a.md"}`, lines[0])
+	assert.Equal(t, `{"path":"many/199.md"}`, lines[count-1])
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// Row file formats accepted by ReadRows.
+const (
+	RowFormatCSV  = "csv"
+	RowFormatJSON = "json"
+)
+
+// DefaultRowPath is the path pattern of notes imported from rows when none
+// is given.
+const DefaultRowPath = "{{ .slug }}.md"
+
+// RowImportOptions controls importing rows as notes.
+type RowImportOptions struct {
+	// Template names the note template rendering the body of new notes.
+	// Empty uses the template of the groups matching the path, else a
+	// title heading.
+	Template string
+	// PathPattern is a text/template for the path of new notes. Its data
+	// holds the row's columns plus "slug" (the slugified title), "title"
+	// and "row" (the 1-based row number) unless a column has that name.
+	PathPattern string
+	// TitleColumn is the column holding note titles, "title" by default.
+	TitleColumn string
+	// Key is a column identifying notes across imports. A row whose key
+	// value is in the frontmatter field of the same name of an existing
+	// note updates that note's fields in place.
+	Key string
+}
+
+// RowImport is the plan for importing rows. It is built without writing
+// anything, so it doubles as the dry-run report.
+type RowImport struct {
+	File  string    `json:"file"`
+	Notes []RowNote `json:"notes"`
+}
+
+// RowNote describes what happens to one row.
+type RowNote struct {
+	Row    int    `json:"row"`
+	Path   string `json:"path"`
+	Title  string `json:"title,omitempty"`
+	Status string `json:"status"`
+	// Reason says why a row was skipped.
+	Reason string `json:"reason,omitempty"`
+
+	// Change holds the note content before and after, with an empty
+	// Before for new notes.
+	Change *NoteChange `json:"-"`
+	// Violations are the notebook schema errors the note would have.
+	Violations core.ValidationErrors `json:"-"`
+}
+
+// leadingZeroPattern matches numbers written with leading zeros, such as
+// zip codes or account numbers, which stay strings.
+var leadingZeroPattern = regexp.MustCompile(`^[+-]?0\d`)
+
+// ReadRows reads the rows of a CSV file with a header line, or a JSON array
+// of objects. CSV cells are typed like --data values, so "2" is a number and
+// "true" a boolean; JSON values keep their types.
+func ReadRows(file, format string) ([]map[string]any, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	switch format {
+	case RowFormatCSV:
+		return readCSVRows(f)
+	case RowFormatJSON:
+		return readJSONRows(f)
+	default:
+		return nil, fmt.Errorf("unknown row format %q (expected csv or json)", format)
+	}
+}
+
+func readCSVRows(r io.Reader) ([]map[string]any, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("no header line")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		if header[i] == "" {
+			return nil, fmt.Errorf("column %d has no name", i+1)
+		}
+	}
+
+	var rows []map[string]any
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		row := make(map[string]any, len(header))
+		for i, cell := range record {
+			if i >= len(header) {
+				line, _ := reader.FieldPos(i)
+				return nil, fmt.Errorf("line %d has more cells than the header", line)
+			}
+			row[header[i]] = coerceCell(strings.TrimSpace(cell))
+		}
+		rows = append(rows, row)
+	}
+}
+
+// coerceCell types a CSV cell like ParseFieldValue, except that numbers
+// with leading zeros keep their digits.
+func coerceCell(cell string) any {
+	if leadingZeroPattern.MatchString(cell) {
+		return cell
+	}
+	return ParseFieldValue(cell)
+}
+
+func readJSONRows(r io.Reader) ([]map[string]any, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var items []map[string]any
+	if err := decoder.Decode(&items); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+	rows := make([]map[string]any, len(items))
+	for i, item := range items {
+		rows[i] = jsonValue(item).(map[string]any)
+	}
+	return rows, nil
+}
+
+// jsonValue turns json.Numbers into ints or floats, recursively.
+func jsonValue(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return int(i)
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = jsonValue(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = jsonValue(v[key])
+		}
+	}
+	return value
+}
+
+// PlanRowImport plans a note for each row. New notes are rendered with the
+// template at the path pattern and get the row's values as frontmatter.
+// With a key column, rows matching an existing note set its fields instead.
+// Rows whose path is taken are skipped.
+func (n *Notebook) PlanRowImport(ctx context.Context, file string, rows []map[string]any, opts RowImportOptions) (*RowImport, error) {
+	if opts.PathPattern == "" {
+		opts.PathPattern = DefaultRowPath
+	}
+	if opts.TitleColumn == "" {
+		opts.TitleColumn = "title"
+	}
+	pathTemplate, err := template.New("path").Funcs(template.FuncMap{"slug": core.Slugify}).Option("missingkey=error").Parse(opts.PathPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern: %w", err)
+	}
+	templates := n.NoteTemplates()
+	if opts.Template != "" && !templates.Has(opts.Template) {
+		return nil, fmt.Errorf("template %q not found", opts.Template)
+	}
+
+	notes, err := n.Notes.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string][]string)
+	taken := make(map[string]int)
+	for _, note := range notes {
+		taken[note.File.Relative] = 0
+		if opts.Key != "" {
+			if value, ok := note.Metadata[opts.Key]; ok && value != nil {
+				key := fmt.Sprint(value)
+				byKey[key] = append(byKey[key], note.File.Relative)
+			}
+		}
+	}
+	ids := noteIDs(notes)
+	keyRows := make(map[string]int)
+
+	plan := &RowImport{File: file, Notes: []RowNote{}}
+	for i, row := range rows {
+		number := i + 1
+		note := RowNote{Row: number, Status: ImportNew}
+		fields := rowFields(row)
+		if title, ok := fields[opts.TitleColumn]; ok {
+			note.Title = fmt.Sprint(title)
+		}
+
+		if opts.Key != "" {
+			value, ok := fields[opts.Key]
+			if !ok {
+				return nil, fmt.Errorf("row %d has no %q value", number, opts.Key)
+			}
+			key := fmt.Sprint(value)
+			if previous, ok := keyRows[key]; ok {
+				return nil, fmt.Errorf("rows %d and %d have the same %s %q", previous, number, opts.Key, key)
+			}
+			keyRows[key] = number
+
+			switch matches := byKey[key]; len(matches) {
+			case 0:
+			case 1:
+				note.Path = matches[0]
+				if err := n.planRowUpdate(&note, fields, opts); err != nil {
+					return nil, err
+				}
+				plan.Notes = append(plan.Notes, note)
+				continue
+			default:
+				return nil, fmt.Errorf("row %d: %s %q matches several notes: %s", number, opts.Key, key, strings.Join(matches, ", "))
+			}
+		}
+
+		relPath, err := rowPath(pathTemplate, row, note.Title, number)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", number, err)
+		}
+		note.Path = relPath
+		if previous, ok := taken[relPath]; ok {
+			note.Status = ImportSkipped
+			note.Reason = "note already exists"
+			if previous > 0 {
+				note.Reason = fmt.Sprintf("row %d has the same path", previous)
+			}
+			plan.Notes = append(plan.Notes, note)
+			continue
+		}
+		// Files the index doesn't know, like attachments, aren't overwritten either
+		if n.Storage.Exists(relPath) {
+			note.Status = ImportSkipped
+			note.Reason = "file already exists"
+			plan.Notes = append(plan.Notes, note)
+			continue
+		}
+		taken[relPath] = number
+
+		if err := n.planRowNote(&note, row, fields, opts, templates, ids); err != nil {
+			return nil, fmt.Errorf("row %d: %w", number, err)
+		}
+		plan.Notes = append(plan.Notes, note)
+	}
+	return plan, nil
+}
+
+// rowPath renders the path pattern for a row.
+func rowPath(pattern *template.Template, row map[string]any, title string, number int) (string, error) {
+	data := make(map[string]any, len(row)+3)
+	for key, value := range row {
+		if value == nil {
+			value = ""
+		}
+		data[key] = value
+	}
+	for key, value := range map[string]any{"slug": core.Slugify(title), "title": title, "row": number} {
+		if _, ok := data[key]; !ok {
+			data[key] = value
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := pattern.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	relPath, err := cleanNotePath(buf.String())
+	if err != nil {
+		return "", err
+	}
+	if path.Base(relPath) == ".md" {
+		return "", fmt.Errorf("path %q has no file name (is the title empty?)", relPath)
+	}
+	return relPath, nil
+}
+
+// rowFields returns the values of a row to store as frontmatter, without
+// empty cells.
+func rowFields(row map[string]any) map[string]any {
+	fields := make(map[string]any, len(row))
+	for key, value := range row {
+		if value != nil && value != "" {
+			fields[key] = value
+		}
+	}
+	return fields
+}
+
+// planRowNote renders a new note for a row. The template sees every column,
+// with empty cells as empty strings, while the frontmatter gets the fields.
+func (n *Notebook) planRowNote(note *RowNote, row, fields map[string]any, opts RowImportOptions, templates *NoteTemplates, ids map[string]bool) error {
+	groupDefaults := n.Config.GroupDefaults(note.Path)
+	for key, value := range groupDefaults.Metadata {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+	now := time.Now()
+	fields["created"] = now.Format(time.RFC3339)
+	if note.Title != "" {
+		fields["title"] = note.Title
+	}
+	if n.Config.IDFormat != "" && fields["id"] == nil {
+		id, err := newNoteID(n.Config.IDFormat, now, ids)
+		if err != nil {
+			return fmt.Errorf("failed to generate note id: %w", err)
+		}
+		ids[strings.ToLower(id)] = true
+		fields["id"] = id
+	}
+
+	name := opts.Template
+	if name == "" && templates.Has(groupDefaults.Template) {
+		name = groupDefaults.Template
+	}
+	body := "\n"
+	if note.Title != "" {
+		body = fmt.Sprintf("# %s\n\n", note.Title)
+	}
+	if name != "" {
+		data := make(map[string]any, len(row)+len(fields))
+		for key, value := range row {
+			if value == nil {
+				value = ""
+			}
+			data[key] = value
+		}
+		for key, value := range fields {
+			data[key] = value
+		}
+		var err error
+		body, err = templates.Render(name, NewTemplateData(n.Config.Name, note.Title, note.Path, data))
+		if err != nil {
+			return err
+		}
+	}
+
+	content, err := UpdateFrontmatter([]byte(body), fields, nil)
+	if err != nil {
+		return fmt.Errorf("template %q: %w", name, err)
+	}
+	note.Change = &NoteChange{Path: note.Path, OldPath: note.Path, After: string(content)}
+	note.Violations = n.Config.ValidateContent(note.Path, content)
+	return nil
+}
+
+// planRowUpdate sets the row's values on an existing note. Fields the row
+// leaves empty keep their value.
+func (n *Notebook) planRowUpdate(note *RowNote, set map[string]any, opts RowImportOptions) error {
+	if note.Title != "" && opts.TitleColumn != "title" {
+		set["title"] = note.Title
+	}
+
+	change, err := n.Notes.PlanFieldUpdate(note.Path, set, nil)
+	if err != nil {
+		return fmt.Errorf("row %d: %w", note.Row, err)
+	}
+	if change == nil {
+		note.Status = ImportUnchanged
+		return nil
+	}
+	note.Status = ImportUpdated
+	note.Change = change
+	note.Violations = NewViolations(
+		n.Config.ValidateContent(change.OldPath, []byte(change.Before)),
+		n.Config.ValidateContent(change.Path, []byte(change.After)),
+	)
+	return nil
+}
+
+// ApplyRowImport writes the new and updated notes of a plan and indexes
+// them.
+func (n *Notebook) ApplyRowImport(ctx context.Context, plan *RowImport) error {
+	for _, note := range plan.Notes {
+		if note.Change == nil {
+			continue
+		}
+		if err := n.Notes.ApplyChange(ctx, note.Change); err != nil {
+			return fmt.Errorf("row %d: %w", note.Row, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeRows(t *testing.T, name, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, []byte(content), 0644))
+	return file
+}
+
+func TestReadRows(t *testing.T) {
+	rows, err := ReadRows(writeRows(t, "c.csv", "\ufeffid, name ,zip,active,score\n7,Acme,02134,true,1.5\n8,Globex,,false\n"), RowFormatCSV)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{
+		{"id": 7, "name": "Acme", "zip": "02134", "active": true, "score": 1.5},
+		{"id": 8, "name": "Globex", "zip": "", "active": false},
+	}, rows)
+
+	rows, err = ReadRows(writeRows(t, "r.json", `[{"id": 3, "score": 2.5, "tags": ["a", 1], "owner": null}]`), RowFormatJSON)
+	require.NoError(t, err)
+	assert.Equal(t, []map[string]any{{"id": 3, "score": 2.5, "tags": []any{"a", 1}, "owner": nil}}, rows)
+
+	_, err = ReadRows(writeRows(t, "c.csv", "id\n1,2\n"), RowFormatCSV)
+	assert.ErrorContains(t, err, "more cells than the header")
+	_, err = ReadRows(writeRows(t, "r.json", `{"id": 1}`), RowFormatJSON)
+	assert.ErrorContains(t, err, "expected an array of objects")
+}
+
+var rowTemplates = map[string]string{"customer": "# {{ .Title }}\n\nZip: {{ .Data.zip }}\n"}
+
+func TestNotebook_RowImport(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, nil)
+	nb.Config.Templates = rowTemplates
+	opts := RowImportOptions{Template: "customer", PathPattern: "customers/{{ .slug }}.md", TitleColumn: "name", Key: "id"}
+	rows := []map[string]any{
+		{"id": 7, "name": "Acme Corp", "zip": "02134"},
+		{"id": 8, "name": "Globex", "zip": ""},
+	}
+
+	plan, err := nb.PlanRowImport(ctx, "c.csv", rows, opts)
+	require.NoError(t, err)
+	require.Len(t, plan.Notes, 2)
+	assert.Equal(t, "customers/acme-corp.md", plan.Notes[0].Path)
+	assert.Equal(t, ImportNew, plan.Notes[0].Status)
+	assert.Contains(t, plan.Notes[0].Change.After, "id: 7\nname: Acme Corp\ntitle: Acme Corp\nzip: \"02134\"\n---\n\n# Acme Corp\n\nZip: 02134\n")
+	assert.Contains(t, plan.Notes[1].Change.After, "# Globex\n\nZip: \n")
+	assert.NotContains(t, plan.Notes[1].Change.After, "zip:", "empty cells aren't stored")
+	require.NoError(t, nb.ApplyRowImport(ctx, plan))
+
+	// Rows are matched to notes by key, wherever the notes are
+	require.NoError(t, nb.Storage.Rename("customers/globex.md", "archive/globex.md"))
+	require.NoError(t, nb.Notes.ReindexFiles(ctx, []string{"customers/globex.md", "archive/globex.md"}))
+	rows = []map[string]any{
+		{"id": 7, "name": "Acme Corp", "zip": "02134"},
+		{"id": 8, "name": "Globex", "zip": 10001},
+		{"id": 9, "name": "Acme Corp", "zip": ""},
+	}
+	plan, err = nb.PlanRowImport(ctx, "c.csv", rows, opts)
+	require.NoError(t, err)
+	assert.Equal(t, ImportUnchanged, plan.Notes[0].Status)
+	assert.Equal(t, ImportUpdated, plan.Notes[1].Status)
+	assert.Equal(t, "archive/globex.md", plan.Notes[1].Path)
+	assert.Equal(t, ImportSkipped, plan.Notes[2].Status)
+	assert.Equal(t, "note already exists", plan.Notes[2].Reason)
+	require.NoError(t, nb.ApplyRowImport(ctx, plan))
+
+	content, err := nb.Storage.Read("archive/globex.md")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "zip: 10001\n")
+	assert.Contains(t, string(content), "# Globex\n\nZip: \n", "the body is kept")
+
+	_, err = nb.PlanRowImport(ctx, "c.csv", []map[string]any{{"id": 1, "name": "A"}, {"id": 1, "name": "B"}}, opts)
+	assert.ErrorContains(t, err, `rows 1 and 2 have the same id "1"`)
+	_, err = nb.PlanRowImport(ctx, "c.csv", []map[string]any{{"name": "A"}}, opts)
+	assert.ErrorContains(t, err, `row 1 has no "id" value`)
+}
+
+func TestNotebook_RowImportPaths(t *testing.T) {
+	ctx := context.Background()
+	nb := openTestNotebook(t, nil)
+	nb.Config.Templates = rowTemplates
+	rows := []map[string]any{
+		{"title": "Outage", "year": 2024, "team": nil},
+		{"title": "Outage", "year": 2024, "team": "ops"},
+		{"title": "", "year": 2024},
+	}
+
+	plan, err := nb.PlanRowImport(ctx, "r.json", rows[:2], RowImportOptions{PathPattern: "{{ .year }}/{{ .team }}/{{ .slug }}-{{ .row }}"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024/outage-1.md", plan.Notes[0].Path)
+	assert.Equal(t, "2024/ops/outage-2.md", plan.Notes[1].Path)
+	assert.Equal(t, "# Outage\n\n", plan.Notes[0].Change.After[len(plan.Notes[0].Change.After)-len("# Outage\n\n"):])
+
+	plan, err = nb.PlanRowImport(ctx, "r.json", rows[:2], RowImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ImportSkipped, plan.Notes[1].Status)
+	assert.Equal(t, "row 1 has the same path", plan.Notes[1].Reason)
+
+	// A file the index doesn't know about is not overwritten
+	require.NoError(t, nb.Storage.Write(plan.Notes[0].Path, []byte("Not indexed\n")))
+	plan, err = nb.PlanRowImport(ctx, "r.json", rows[:1], RowImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, ImportSkipped, plan.Notes[0].Status)
+	assert.Equal(t, "file already exists", plan.Notes[0].Reason)
+
+	_, err = nb.PlanRowImport(ctx, "r.json", rows[2:], RowImportOptions{})
+	assert.ErrorContains(t, err, "has no file name")
+	_, err = nb.PlanRowImport(ctx, "r.json", rows, RowImportOptions{PathPattern: "{{ .missing }}"})
+	assert.ErrorContains(t, err, `no entry for key "missing"`)
+	_, err = nb.PlanRowImport(ctx, "r.json", rows, RowImportOptions{PathPattern: "../{{ .slug }}"})
+	assert.ErrorContains(t, err, "outside the notebook")
+	_, err = nb.PlanRowImport(ctx, "r.json", rows, RowImportOptions{Template: "nope"})
+	assert.ErrorContains(t, err, `template "nope" not found`)
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/archive"
+	"github.com/zenobi-us/jot/internal/search/bleve"
+	"github.com/zenobi-us/jot/internal/search/webdav"
+)
+
+// Storage backend types accepted in the "storage.type" field of .jot.json.
+const (
+	StorageTypeLocal   = "local"
+	StorageTypeArchive = "archive"
+	StorageTypeWebDAV  = "webdav"
+)
+
+// StorageConfig selects the backend a notebook reads and writes notes through.
+// A nil or empty config means the local filesystem under the notebook root.
+type StorageConfig struct {
+	// Type is one of "local" (default), "archive" or "webdav".
+	Type string `json:"type"`
+
+	// Path is the archive file for "archive" storage, relative to the notebook directory.
+	Path string `json:"path,omitempty"`
+
+	// URL is the collection URL for "webdav" storage.
+	URL string `json:"url,omitempty"`
+
+	// Username is the basic-auth user for "webdav" storage.
+	Username string `json:"username,omitempty"`
+
+	// PasswordEnv names the environment variable holding the webdav password.
+	// Passwords are never stored in .jot.json.
+	PasswordEnv string `json:"password_env,omitempty"`
+}
+
+// StorageType returns the configured backend type, defaulting to local.
+func (c *StorageConfig) StorageType() string {
+	if c == nil || c.Type == "" {
+		return StorageTypeLocal
+	}
+	return c.Type
+}
+
+// IsLocal reports whether the config uses the local filesystem.
+func (c *StorageConfig) IsLocal() bool {
+	return c.StorageType() == StorageTypeLocal
+}
+
+// NewStorage builds the search.Storage for a notebook.
+// notebookDir is the directory holding .jot.json and root is the resolved
+// notes root used by local storage.
+func NewStorage(cfg *StorageConfig, notebookDir, root string) (search.Storage, error) {
+	switch cfg.StorageType() {
+	case StorageTypeLocal:
+		return bleve.OsStorage(root), nil
+
+	case StorageTypeArchive:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("archive storage requires a path")
+		}
+		archivePath := cfg.Path
+		if !filepath.IsAbs(archivePath) {
+			archivePath = filepath.Join(notebookDir, archivePath)
+		}
+		return archive.Open(archivePath)
+
+	case StorageTypeWebDAV:
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webdav storage requires a url")
+		}
+		opts := webdav.Options{Username: cfg.Username}
+		if cfg.PasswordEnv != "" {
+			opts.Password = os.Getenv(cfg.PasswordEnv)
+		}
+		return webdav.New(cfg.URL, opts)
+
+	default:
+		return nil, fmt.Errorf("unknown storage type %q (expected %s, %s or %s)",
+			cfg.Type, StorageTypeLocal, StorageTypeArchive, StorageTypeWebDAV)
+	}
+}
@@ -0,0 +1,425 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/encrypt"
+)
+
+// SyncOptions configures Notebook.Sync.
+type SyncOptions struct {
+	// Message is the subject of the commit of local changes.
+	Message string
+	// NoPush skips pushing after the pull.
+	NoPush bool
+}
+
+// SyncResult reports what Notebook.Sync did.
+type SyncResult struct {
+	// Upstream is the remote branch synced with, like "origin/main".
+	Upstream string `json:"upstream"`
+	// Committed is true when local changes were committed first.
+	Committed bool `json:"committed"`
+	// Pulled lists the notes the pull added, changed or deleted.
+	Pulled []string `json:"pulled"`
+	// Merged lists the notes whose conflicts were resolved automatically.
+	Merged []string `json:"merged"`
+	// Pushed is true when the push ran.
+	Pushed bool `json:"pushed"`
+}
+
+// SyncConflictError is returned by Notebook.Sync when a pull conflicts in
+// ways jot cannot resolve. The rebase is aborted, leaving the local commit
+// in place.
+type SyncConflictError struct {
+	Paths []string
+}
+
+func (e *SyncConflictError) Error() string {
+	return fmt.Sprintf("cannot merge %s automatically; resolve with git pull --rebase", strings.Join(e.Paths, ", "))
+}
+
+// Sync commits local changes under the notes root, pulls with rebase and
+// pushes. Frontmatter conflicts are merged field by field and bodies line
+// by line; notes changed by the pull are re-indexed.
+func (n *Notebook) Sync(ctx context.Context, opts SyncOptions) (*SyncResult, error) {
+	g := n.GitRepo()
+	if g == nil {
+		return nil, fmt.Errorf("notebook %q is not in a git repository", n.Config.Name)
+	}
+
+	upstream, err := g.Upstream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &SyncResult{Upstream: upstream, Pulled: []string{}, Merged: []string{}}
+
+	message := opts.Message
+	if message == "" {
+		message = "jot sync"
+	}
+	if result.Committed, err = g.CommitAll(ctx, message); err != nil {
+		return nil, err
+	}
+
+	before, err := g.ResolveRev(ctx, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	if result.Merged, err = g.PullRebase(ctx); err != nil {
+		return nil, err
+	}
+
+	if result.Pulled, err = g.ChangedNotes(ctx, before, "HEAD"); err != nil {
+		return nil, err
+	}
+	if err := n.Notes.ReindexFiles(ctx, result.Pulled); err != nil {
+		return nil, err
+	}
+
+	if !opts.NoPush {
+		if _, err := g.run(ctx, "push", "-q"); err != nil {
+			return nil, err
+		}
+		result.Pushed = true
+	}
+	return result, nil
+}
+
+// Upstream returns the remote branch the current branch tracks.
+func (g *Git) Upstream(ctx context.Context) (string, error) {
+	out, err := g.run(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}")
+	if err != nil {
+		return "", fmt.Errorf("the current branch has no upstream; push it once with: git push -u <remote> <branch>")
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// CommitAll commits every change under the notes root except jot's own
+// data in .jot (trash). It reports false when there was nothing to commit.
+func (g *Git) CommitAll(ctx context.Context, message string) (bool, error) {
+	root := g.repoPath(".")
+	pathspec := []string{"--", root, ":(exclude)" + path.Join(root, ".jot")}
+
+	if _, err := g.run(ctx, append([]string{"add", "-A"}, pathspec...)...); err != nil {
+		return false, err
+	}
+	if _, err := g.run(ctx, append([]string{"diff", "--cached", "--quiet"}, pathspec...)...); err == nil {
+		return false, nil
+	}
+	if _, err := g.run(ctx, append([]string{"commit", "-q", "-m", message}, pathspec...)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// PullRebase pulls the upstream branch and rebases local commits onto it,
+// merging conflicting notes with MergeNote. It returns the notes merged
+// that way. Unresolvable conflicts abort the rebase.
+func (g *Git) PullRebase(ctx context.Context) ([]string, error) {
+	merged := []string{}
+	_, err := g.run(ctx, "pull", "-q", "--rebase", "--autostash")
+	for err != nil {
+		conflicted, cerr := g.conflicted(ctx)
+		if cerr != nil || len(conflicted) == 0 {
+			// Not stopped on a conflict: a network or other git failure
+			return nil, err
+		}
+
+		var unresolved []string
+		for _, repoPath := range conflicted {
+			relPath, ok := g.resolveConflict(ctx, repoPath)
+			if !ok {
+				unresolved = append(unresolved, repoPath)
+				continue
+			}
+			merged = append(merged, relPath)
+		}
+		if len(unresolved) > 0 {
+			if _, aerr := g.run(ctx, "rebase", "--abort"); aerr != nil {
+				return nil, aerr
+			}
+			return nil, &SyncConflictError{Paths: unresolved}
+		}
+
+		// A commit whose changes the upstream already has becomes empty
+		if _, qerr := g.run(ctx, "diff", "--cached", "--quiet"); qerr == nil {
+			_, err = g.run(ctx, "rebase", "--skip")
+		} else {
+			_, err = g.run(ctx, "rebase", "--continue")
+		}
+	}
+
+	sort.Strings(merged)
+	return slices.Compact(merged), nil
+}
+
+// conflicted lists the unmerged paths of the work tree.
+func (g *Git) conflicted(ctx context.Context) ([]string, error) {
+	out, err := g.run(ctx, "diff", "--name-only", "--diff-filter=U")
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(out, "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths, nil
+}
+
+// resolveConflict merges the conflicting versions of a note and stages
+// the result. It reports false for files it cannot merge: anything but
+// plain markdown notes, notes deleted on one side and overlapping edits.
+func (g *Git) resolveConflict(ctx context.Context, repoPath string) (string, bool) {
+	relPath, ok := g.notePath(repoPath)
+	if !ok || filepath.Ext(relPath) != ".md" {
+		return "", false
+	}
+
+	// Stage 1 is the common ancestor (missing when both sides added the
+	// note), 2 and 3 the two sides
+	base, _ := g.Show(ctx, ":1", relPath)
+	ours, err := g.Show(ctx, ":2", relPath)
+	if err != nil {
+		return "", false
+	}
+	theirs, err := g.Show(ctx, ":3", relPath)
+	if err != nil {
+		return "", false
+	}
+	if encrypt.IsEncrypted(ours) || encrypt.IsEncrypted(theirs) {
+		return "", false
+	}
+
+	content, ok, err := g.MergeNote(ctx, base, ours, theirs)
+	if err != nil || !ok {
+		return "", false
+	}
+	if err := os.WriteFile(filepath.Join(g.root, filepath.FromSlash(relPath)), content, 0644); err != nil {
+		return "", false
+	}
+	if _, err := g.run(ctx, "add", "--", repoPath); err != nil {
+		return "", false
+	}
+	return relPath, true
+}
+
+// ChangedNotes lists the notes added, changed or deleted between two
+// commits. Renames show as a deletion and an addition.
+func (g *Git) ChangedNotes(ctx context.Context, from, to string) ([]string, error) {
+	out, err := g.run(ctx, "diff", "--name-only", "--no-renames", from, to, "--", g.repoPath("."))
+	if err != nil {
+		return nil, err
+	}
+	notes := []string{}
+	for _, line := range strings.Split(out, "\n") {
+		if relPath, ok := g.notePath(line); ok && line != "" && filepath.Ext(relPath) == ".md" {
+			notes = append(notes, relPath)
+		}
+	}
+	return notes, nil
+}
+
+// MergeNote merges two versions of a note changed from base. Frontmatter
+// is merged field by field (see mergeFrontmatter) and the body line by line
+// with "git merge-file". It reports false when changes overlap.
+func (g *Git) MergeNote(ctx context.Context, base, ours, theirs []byte) ([]byte, bool, error) {
+	baseMeta, _, err := parseFrontmatterStrict(base)
+	if err != nil {
+		baseMeta = map[string]any{}
+	}
+	oursMeta, _, err := parseFrontmatterStrict(ours)
+	if err != nil {
+		return nil, false, nil
+	}
+	theirsMeta, _, err := parseFrontmatterStrict(theirs)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	set, unset, conflicts := mergeFrontmatter(baseMeta, oursMeta, theirsMeta)
+	if len(conflicts) > 0 {
+		return nil, false, nil
+	}
+
+	_, baseBody, _ := splitFrontmatter(base)
+	oursFrontmatter, oursBody, hasFrontmatter := splitFrontmatter(ours)
+	_, theirsBody, _ := splitFrontmatter(theirs)
+	body, ok, err := g.mergeText(ctx, baseBody, oursBody, theirsBody)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	content := body
+	if hasFrontmatter {
+		content = append([]byte("---\n"+string(oursFrontmatter)+"---\n"), body...)
+	}
+	if len(set) > 0 || len(unset) > 0 {
+		if content, err = UpdateFrontmatter(content, set, unset); err != nil {
+			return nil, false, nil
+		}
+	}
+	return content, true, nil
+}
+
+// mergeText merges two texts changed from base line by line. It reports
+// false when their changes overlap.
+func (g *Git) mergeText(ctx context.Context, base, ours, theirs []byte) ([]byte, bool, error) {
+	switch {
+	case string(ours) == string(theirs), string(theirs) == string(base):
+		return ours, true, nil
+	case string(ours) == string(base):
+		return theirs, true, nil
+	}
+
+	dir, err := os.MkdirTemp("", "jot-merge-*")
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	files := make([]string, 3)
+	for i, content := range [][]byte{ours, base, theirs} {
+		files[i] = filepath.Join(dir, fmt.Sprint(i))
+		if err := os.WriteFile(files[i], content, 0600); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// merge-file exits with the number of conflicts, or negative on error
+	out, err := exec.CommandContext(ctx, "git", append([]string{"merge-file", "-p"}, files...)...).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 && exitErr.ExitCode() < 128 {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("git merge-file: %w", err)
+	}
+	return out, true, nil
+}
+
+// mergeFrontmatter merges frontmatter fields changed from base on both
+// sides. A field changed on one side only takes that change. Fields both
+// sides changed differently merge when they are lists, like tags (the
+// union, minus items either side removed), or "modified" (the later
+// date); anything else is a conflict. It returns the changes to apply to
+// ours.
+func mergeFrontmatter(base, ours, theirs map[string]any) (set map[string]any, unset, conflicts []string) {
+	set = make(map[string]any)
+
+	var keys []string
+	for _, m := range []map[string]any{base, ours, theirs} {
+		for key := range m {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range slices.Compact(keys) {
+		b, inBase := base[key]
+		o, inOurs := ours[key]
+		t, inTheirs := theirs[key]
+
+		switch {
+		case inOurs == inTheirs && reflect.DeepEqual(o, t):
+			// Same on both sides
+		case inOurs == inBase && reflect.DeepEqual(o, b):
+			// Only theirs changed it
+			if inTheirs {
+				set[key] = t
+			} else {
+				unset = append(unset, key)
+			}
+		case inTheirs == inBase && reflect.DeepEqual(t, b):
+			// Only ours changed it
+		case inOurs && inTheirs:
+			merged, ok := mergeFieldValues(key, b, o, t)
+			if !ok {
+				conflicts = append(conflicts, key)
+			} else if !reflect.DeepEqual(merged, o) {
+				set[key] = merged
+			}
+		default:
+			// Changed on one side, removed on the other
+			conflicts = append(conflicts, key)
+		}
+	}
+	return set, unset, conflicts
+}
+
+// mergeFieldValues merges a field both sides changed, reporting false when
+// the values cannot be combined.
+func mergeFieldValues(key string, base, ours, theirs any) (any, bool) {
+	if key == "modified" {
+		oursTime := extractTime(map[string]any{key: ours}, key, time.Time{})
+		theirsTime := extractTime(map[string]any{key: theirs}, key, time.Time{})
+		if oursTime.IsZero() || theirsTime.IsZero() {
+			return nil, false
+		}
+		if theirsTime.After(oursTime) {
+			return theirs, true
+		}
+		return ours, true
+	}
+
+	oursList, ok := ours.([]any)
+	if !ok {
+		return nil, false
+	}
+	theirsList, ok := theirs.([]any)
+	if !ok {
+		return nil, false
+	}
+	baseList, _ := base.([]any)
+
+	removed := func(item any) bool {
+		return containsValue(baseList, item) && (!containsValue(oursList, item) || !containsValue(theirsList, item))
+	}
+	merged := []any{}
+	for _, item := range append(append([]any{}, oursList...), theirsList...) {
+		if !removed(item) && !containsValue(merged, item) {
+			merged = append(merged, item)
+		}
+	}
+	return merged, true
+}
+
+func containsValue(list []any, value any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReindexFiles updates the index for notes changed outside jot: existing
+// notes are re-read, missing ones dropped.
+func (s *NoteService) ReindexFiles(ctx context.Context, relPaths []string) error {
+	for _, relPath := range relPaths {
+		if !s.storage.Exists(relPath) {
+			if err := s.index.Remove(ctx, relPath); err != nil && !errors.Is(err, search.ErrNotFound) {
+				return err
+			}
+			continue
+		}
+		if err := s.IndexFile(ctx, relPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
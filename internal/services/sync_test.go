@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zenobi-us/jot/internal/search"
+)
+
+func TestMergeFrontmatter(t *testing.T) {
+	base := map[string]any{
+		"title":    "Plan",
+		"status":   "todo",
+		"owner":    "sam",
+		"tags":     []any{"work", "q2"},
+		"modified": "2024-01-01T00:00:00Z",
+	}
+
+	t.Run("one-sided and combinable changes merge", func(t *testing.T) {
+		ours := map[string]any{
+			"title":    "Plan",
+			"status":   "doing",
+			"tags":     []any{"work", "q2", "urgent"},
+			"modified": "2024-02-01T00:00:00Z",
+		}
+		theirs := map[string]any{
+			"title":    "Launch plan",
+			"status":   "todo",
+			"owner":    "sam",
+			"tags":     []any{"work", "q3"},
+			"modified": "2024-03-01T00:00:00Z",
+			"priority": 1,
+		}
+
+		set, unset, conflicts := mergeFrontmatter(base, ours, theirs)
+		assert.Empty(t, conflicts)
+		assert.Empty(t, unset, "owner was only removed by ours")
+		assert.Equal(t, map[string]any{
+			"title":    "Launch plan",
+			"tags":     []any{"work", "urgent", "q3"},
+			"modified": "2024-03-01T00:00:00Z",
+			"priority": 1,
+		}, set, "q2 was removed by theirs")
+	})
+
+	t.Run("removal on one side", func(t *testing.T) {
+		theirs := map[string]any{"title": "Plan", "status": "todo", "tags": []any{"work", "q2"}, "modified": "2024-01-01T00:00:00Z"}
+		_, unset, conflicts := mergeFrontmatter(base, base, theirs)
+		assert.Empty(t, conflicts)
+		assert.Equal(t, []string{"owner"}, unset)
+	})
+
+	t.Run("different values for the same field conflict", func(t *testing.T) {
+		ours := map[string]any{"title": "Plan", "status": "doing", "owner": "sam"}
+		theirs := map[string]any{"title": "Plan", "status": "done"}
+		_, _, conflicts := mergeFrontmatter(base, ours, theirs)
+		assert.Equal(t, []string{"status"}, conflicts)
+	})
+}
+
+func TestGit_MergeNote(t *testing.T) {
+	notebookDir := createGitNotebook(t, nil)
+	g, err := OpenGit(context.Background(), filepath.Join(notebookDir, ".notes"))
+	require.NoError(t, err)
+
+	base := "---\ntitle: Plan\ntags: [work] # keep\n---\n\none\ntwo\nthree\nfour\nfive\n"
+	ours := "---\ntitle: Plan\ntags: [work, urgent] # keep\n---\n\nONE\ntwo\nthree\nfour\nfive\n"
+	theirs := "---\ntitle: Plan\ntags: [work, q3]\nstatus: done\n---\n\none\ntwo\nthree\nfour\nFIVE\n"
+
+	merged, ok, err := g.MergeNote(context.Background(), []byte(base), []byte(ours), []byte(theirs))
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "---\ntitle: Plan\ntags: [work, urgent, q3] # keep\nstatus: done\n---\n\nONE\ntwo\nthree\nfour\nFIVE\n", string(merged))
+
+	overlapping := strings.Replace(theirs, "\none\n", "\nuno\n", 1)
+	_, ok, err = g.MergeNote(context.Background(), []byte(base), []byte(ours), []byte(overlapping))
+	require.NoError(t, err)
+	assert.False(t, ok, "both sides changed the first line")
+}
+
+// cloneNotebook clones remote into a new directory with its own identity.
+func cloneNotebook(t *testing.T, remote, name string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	gitRun(t, filepath.Dir(dir), "", "clone", "-q", remote, dir)
+	gitRun(t, dir, "", "config", "user.name", name)
+	gitRun(t, dir, "", "config", "user.email", name+"@example.com")
+	return dir
+}
+
+func TestNotebook_Sync(t *testing.T) {
+	ctx := context.Background()
+	origin := createGitNotebook(t, nil)
+	writeGitNote(t, origin, "plan.md", "---\ntitle: Plan\ntags: [work]\nstatus: todo\n---\n\none\ntwo\nthree\nfour\nfive\n")
+	gitRun(t, origin, "", "add", ".")
+	gitRun(t, origin, "", "commit", "-q", "-m", "Add plan")
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	gitRun(t, origin, "", "clone", "-q", "--bare", origin, remote)
+	alice := cloneNotebook(t, remote, "alice")
+	bob := cloneNotebook(t, remote, "bob")
+
+	svc := NewNotebookService(createTestConfigService(t, t.TempDir(), nil))
+	open := func(dir string) *Notebook {
+		nb, err := svc.Open(dir)
+		require.NoError(t, err)
+		return nb
+	}
+
+	// Alice tags the plan and edits its first line
+	writeGitNote(t, alice, "plan.md", "---\ntitle: Plan\ntags: [work, urgent]\nstatus: todo\n---\n\nONE\ntwo\nthree\nfour\nfive\n")
+	result, err := open(alice).Sync(ctx, SyncOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.Committed)
+	assert.True(t, result.Pushed)
+	assert.Empty(t, result.Pulled)
+
+	// Bob tags it too, edits the last line and adds a note
+	bobNotes := open(bob)
+	writeGitNote(t, bob, "plan.md", "---\ntitle: Plan\ntags: [work, q3]\nstatus: todo\n---\n\none\ntwo\nthree\nfour\nFIVE\n")
+	writeGitNote(t, bob, "new.md", "# New\n")
+	result, err = bobNotes.Sync(ctx, SyncOptions{Message: "Bob's notes"})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(result.Upstream, "origin/"), result.Upstream)
+	assert.Equal(t, []string{"plan.md"}, result.Merged)
+	assert.Equal(t, []string{"plan.md"}, result.Pulled)
+
+	content, err := os.ReadFile(filepath.Join(bob, ".notes", "plan.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Plan\ntags: [work, urgent, q3]\nstatus: todo\n---\n\nONE\ntwo\nthree\nfour\nFIVE\n", string(content))
+	assert.Equal(t, "Bob's notes", strings.TrimSpace(gitRun(t, bob, "", "log", "-1", "--format=%s")))
+
+	// The pull was re-indexed in place
+	results, err := bobNotes.Notes.index.Find(ctx, search.FindOpts{Tags: []string{"urgent"}})
+	require.NoError(t, err)
+	assert.Len(t, results.Items, 1)
+
+	result, err = open(alice).Sync(ctx, SyncOptions{NoPush: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new.md", "plan.md"}, result.Pulled)
+	assert.False(t, result.Pushed)
+
+	// Conflicting statuses cannot be merged; Alice keeps her commit
+	writeGitNote(t, alice, "plan.md", strings.Replace(string(content), "status: todo", "status: doing", 1))
+	_, err = open(alice).Sync(ctx, SyncOptions{})
+	require.NoError(t, err)
+	writeGitNote(t, bob, "plan.md", strings.Replace(string(content), "status: todo", "status: done", 1))
+	_, err = open(bob).Sync(ctx, SyncOptions{})
+	var conflict *SyncConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Equal(t, []string{".notes/plan.md"}, conflict.Paths)
+	assert.Empty(t, gitRun(t, bob, "", "status", "--porcelain"), "the rebase was aborted")
+	assert.Equal(t, "jot sync", strings.TrimSpace(gitRun(t, bob, "", "log", "-1", "--format=%s")))
+}
+
+func TestNotebook_SyncWithoutUpstream(t *testing.T) {
+	nb, err := NewNotebookService(createTestConfigService(t, t.TempDir(), nil)).Open(createGitNotebook(t, nil))
+	require.NoError(t, err)
+	_, err = nb.Sync(context.Background(), SyncOptions{})
+	assert.ErrorContains(t, err, "has no upstream")
+}
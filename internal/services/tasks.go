@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/search/parser"
+)
+
+// Ways of grouping tasks.
+const (
+	TaskGroupNote = "note"
+	TaskGroupDue  = "due"
+)
+
+// TaskNoDueGroup is the due date group of tasks without a due date.
+const TaskNoDueGroup = "no due date"
+
+// NoteTask is a task list item together with the note it belongs to.
+type NoteTask struct {
+	// ID identifies the task for "jot tasks done". It is derived from the
+	// note path and the task text, so it survives ticking the box and edits
+	// elsewhere in the note.
+	ID string `json:"id"`
+	search.Task
+
+	Path     string         `json:"path"`
+	Title    string         `json:"title"`
+	NoteID   string         `json:"note_id,omitempty"`
+	NoteTags []string       `json:"note_tags,omitempty"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+var (
+	taskCheckboxPattern = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+)\[ \]`)
+	taskDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	taskDateNamePattern = regexp.MustCompile(`^([a-z][a-z_-]*?)([+-]\d+)?$`)
+)
+
+// Tasks returns the task list items of every note, ordered by note path and
+// line.
+func (s *NoteService) Tasks(ctx context.Context) ([]NoteTask, error) {
+	notes, err := s.getAllNotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sortNotesByPath(notes)
+
+	var tasks []NoteTask
+	for i := range notes {
+		tasks = append(tasks, noteTasks(&notes[i], notes[i].Tasks)...)
+	}
+	return tasks, nil
+}
+
+// noteTasks attaches the note and a task ID to each of its tasks.
+func noteTasks(note *Note, tasks []search.Task) []NoteTask {
+	ids := taskIDs(note.File.Relative, tasks)
+	result := make([]NoteTask, len(tasks))
+	for i, task := range tasks {
+		result[i] = NoteTask{
+			ID:       ids[i],
+			Task:     task,
+			Path:     note.File.Relative,
+			Title:    note.DisplayName(),
+			NoteID:   noteID(note),
+			NoteTags: metadataTags(note.Metadata),
+			Metadata: note.Metadata,
+		}
+	}
+	return result
+}
+
+// taskIDs derives an ID for each task of the note at relPath. Tasks with the
+// same text are told apart by their order.
+func taskIDs(relPath string, tasks []search.Task) []string {
+	ids := make([]string, len(tasks))
+	seen := make(map[string]int)
+	for i, task := range tasks {
+		text := strings.Join(strings.Fields(task.Text), " ")
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s\n%s\n%d", relPath, text, seen[text])))
+		seen[text]++
+		ids[i] = hex.EncodeToString(sum[:])[:7]
+	}
+	return ids
+}
+
+// FilterTasks returns the tasks matching the filter DSL query where,
+// evaluated against each task and its note. Dates are relative to now.
+func FilterTasks(tasks []NoteTask, where string, now time.Time) ([]NoteTask, error) {
+	if strings.TrimSpace(where) == "" {
+		return tasks, nil
+	}
+
+	query, err := parser.New().Parse(where)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []NoteTask
+	for _, task := range tasks {
+		ok, err := matchTaskExprs(query.Expressions, task, now)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = append(result, task)
+		}
+	}
+	return result, nil
+}
+
+// TaskQueryMentions reports whether the filter DSL query where uses field.
+func TaskQueryMentions(where, field string) bool {
+	query, err := parser.New().Parse(where)
+	if err != nil {
+		return false
+	}
+
+	var mentions func(search.Expr) bool
+	mentions = func(expr search.Expr) bool {
+		switch e := expr.(type) {
+		case search.FieldExpr:
+			return e.Field == field
+		case search.ExistsExpr:
+			return e.Field == field
+		case search.NotExpr:
+			return mentions(e.Expr)
+		case search.AndExpr:
+			for _, inner := range e.Expressions {
+				if mentions(inner) {
+					return true
+				}
+			}
+		case search.OrExpr:
+			return mentions(e.Left) || mentions(e.Right)
+		}
+		return false
+	}
+
+	for _, expr := range query.Expressions {
+		if mentions(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchTaskExprs(exprs []search.Expr, task NoteTask, now time.Time) (bool, error) {
+	for _, expr := range exprs {
+		ok, err := matchTaskExpr(expr, task, now)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func matchTaskExpr(expr search.Expr, task NoteTask, now time.Time) (bool, error) {
+	switch e := expr.(type) {
+	case search.TermExpr:
+		return containsFold(task.Text, e.Value), nil
+	case search.FieldExpr:
+		return matchTaskField(e, task, now)
+	case search.DateExpr:
+		date := extractTime(task.Metadata, e.Field, time.Time{})
+		if date.IsZero() {
+			return false, nil
+		}
+		return matchTaskDate(date.Format("2006-01-02"), e.Op, e.Value, now)
+	case search.ExistsExpr:
+		return taskHasField(task, e.Field) != e.Negated, nil
+	case search.NotExpr:
+		ok, err := matchTaskExpr(e.Expr, task, now)
+		return !ok, err
+	case search.AndExpr:
+		return matchTaskExprs(e.Expressions, task, now)
+	case search.OrExpr:
+		ok, err := matchTaskExpr(e.Left, task, now)
+		if err != nil || ok {
+			return ok, err
+		}
+		return matchTaskExpr(e.Right, task, now)
+	}
+	return false, fmt.Errorf("unsupported task filter: %T", expr)
+}
+
+func matchTaskField(e search.FieldExpr, task NoteTask, now time.Time) (bool, error) {
+	switch e.Field {
+	case "status":
+		switch strings.ToLower(e.Value) {
+		case "open", "todo":
+			return !task.Done, nil
+		case "done":
+			return task.Done, nil
+		}
+		return false, fmt.Errorf("unknown task status %q (expected open or done)", e.Value)
+	case "due":
+		if task.Due == "" {
+			return false, nil
+		}
+		return matchTaskDate(task.Due, e.Op, e.Value, now)
+	case "owner":
+		return containsStringFold(task.Owners, strings.TrimPrefix(e.Value, "@")), nil
+	case "tag":
+		value := strings.TrimPrefix(e.Value, "#")
+		return containsStringFold(task.Tags, value) || containsStringFold(task.NoteTags, value), nil
+	case "path":
+		return matchTaskPath(task.Path, e.Value), nil
+	case "title":
+		return containsFold(task.Title, e.Value), nil
+	case "body":
+		return containsFold(task.Text, e.Value), nil
+	case "id":
+		return strings.EqualFold(task.ID, e.Value) || (task.NoteID != "" && strings.EqualFold(task.NoteID, e.Value)), nil
+	}
+	return false, fmt.Errorf("field %q is not supported for tasks", e.Field)
+}
+
+func taskHasField(task NoteTask, field string) bool {
+	switch field {
+	case "due":
+		return task.Due != ""
+	case "owner":
+		return len(task.Owners) > 0
+	case "tag", "tags":
+		return len(task.Tags) > 0 || len(task.NoteTags) > 0
+	case "id":
+		return task.NoteID != ""
+	}
+	return !isEmptyFieldValue(task.Metadata[field])
+}
+
+// matchTaskPath matches a note path exactly, as a directory prefix or as a
+// glob.
+func matchTaskPath(notePath, pattern string) bool {
+	if notePath == pattern || strings.HasPrefix(notePath, strings.TrimSuffix(pattern, "/")+"/") {
+		return true
+	}
+	matched, _ := path.Match(pattern, notePath)
+	return matched
+}
+
+// matchTaskDate compares a YYYY-MM-DD date with a filter value. Week and
+// month names cover the whole period, so "due:this-week" matches any day of
+// the week and "due:<this-week" only days before it.
+func matchTaskDate(date string, op search.CompareOp, value string, now time.Time) (bool, error) {
+	from, to, err := resolveTaskDateRange(value, now)
+	if err != nil {
+		return false, err
+	}
+
+	switch op {
+	case search.OpGt:
+		return date >= to, nil
+	case search.OpGte:
+		return date >= from, nil
+	case search.OpLt:
+		return date < from, nil
+	case search.OpLte:
+		return date < to, nil
+	default:
+		return date >= from && date < to, nil
+	}
+}
+
+// resolveTaskDateRange resolves a date filter value to the half-open range
+// [from, to) of YYYY-MM-DD dates. Besides dates it accepts "tomorrow" and the
+// view date variables written with hyphens ("today", "this-week",
+// "next-month", "today+3", "this-week-1").
+func resolveTaskDateRange(value string, now time.Time) (string, string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	start := ""
+	span := "day"
+	if taskDatePattern.MatchString(value) {
+		start = value
+	} else if value == "tomorrow" {
+		start = now.AddDate(0, 0, 1).Format("2006-01-02")
+	} else if m := taskDateNamePattern.FindStringSubmatch(value); m != nil {
+		name := strings.ReplaceAll(m[1], "-", "_")
+		start = resolveTemplateVariables("{{"+name+m[2]+"}}", now)
+		switch {
+		case strings.HasSuffix(name, "week"):
+			span = "week"
+		case strings.HasSuffix(name, "month"):
+			span = "month"
+		}
+	}
+
+	from, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return "", "", fmt.Errorf("unrecognized date %q (use YYYY-MM-DD, today, tomorrow, this-week, next-month or today+N)", value)
+	}
+
+	var to time.Time
+	switch span {
+	case "week":
+		to = from.AddDate(0, 0, 7)
+	case "month":
+		to = getFirstOfMonth(from).AddDate(0, 1, 0)
+	default:
+		to = from.AddDate(0, 0, 1)
+	}
+	return from.Format("2006-01-02"), to.Format("2006-01-02"), nil
+}
+
+// GroupTasks groups tasks by note path or by due date. Tasks without a due
+// date go to TaskNoDueGroup.
+func GroupTasks(tasks []NoteTask, by string) (map[string][]NoteTask, error) {
+	groups := make(map[string][]NoteTask)
+	for _, task := range tasks {
+		var key string
+		switch by {
+		case TaskGroupNote:
+			key = task.Path
+		case TaskGroupDue:
+			key = task.Due
+			if key == "" {
+				key = TaskNoDueGroup
+			}
+		default:
+			return nil, fmt.Errorf("cannot group tasks by %q (expected note or due)", by)
+		}
+		groups[key] = append(groups[key], task)
+	}
+	return groups, nil
+}
+
+// SortedTaskGroupKeys returns group keys in display order: paths and dates
+// ascending, with TaskNoDueGroup last.
+func SortedTaskGroupKeys(groups map[string][]NoteTask) []string {
+	keys := sortedKeys(groups)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[j] == TaskNoDueGroup && keys[i] != TaskNoDueGroup
+	})
+	return keys
+}
+
+// PlanTaskDone ticks the box of a task without writing anything. ref is a
+// task ID or "path:line". When the task is already done the change is nil.
+func (s *NoteService) PlanTaskDone(ctx context.Context, ref string) (*NoteChange, *NoteTask, error) {
+	relPath, line := "", 0
+	if i := strings.LastIndex(ref, ":"); i > 0 {
+		if n, err := strconv.Atoi(ref[i+1:]); err == nil {
+			relPath, line = ref[:i], n
+		}
+	}
+
+	if relPath == "" {
+		tasks, err := s.Tasks(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, task := range tasks {
+			if strings.EqualFold(task.ID, ref) {
+				relPath = task.Path
+				break
+			}
+		}
+		if relPath == "" {
+			return nil, nil, fmt.Errorf("task not found: %s", ref)
+		}
+	}
+
+	// The file is parsed again rather than trusting the index, so a stale
+	// line number never ticks the wrong box.
+	var found *NoteTask
+	change, err := s.planRewrite(relPath, func(content []byte) ([]byte, error) {
+		note := Note{}
+		note.File.Relative = relPath
+		note.Metadata, _ = parseFrontmatter(content)
+
+		for _, task := range noteTasks(&note, search.ParseTasks(content)) {
+			if (line > 0 && task.Line == line) || (line == 0 && strings.EqualFold(task.ID, ref)) {
+				found = &task
+				break
+			}
+		}
+		if found == nil {
+			if line > 0 {
+				return nil, fmt.Errorf("no task on line %d", line)
+			}
+			return nil, fmt.Errorf("task %s is no longer in the note", ref)
+		}
+		if found.Done {
+			return content, nil
+		}
+
+		lines := strings.Split(string(content), "\n")
+		lines[found.Line-1] = taskCheckboxPattern.ReplaceAllString(lines[found.Line-1], "${1}[x]")
+		found.Done = true
+		return []byte(strings.Join(lines, "\n")), nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return change, found, nil
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func containsStringFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/search"
+	"github.com/zenobi-us/jot/internal/services"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+func TestParseTasks(t *testing.T) {
+	content := "---\ntitle: Plan\n---\n# Plan\n\n" +
+		"- [ ] Write the spec due:2024-05-01 @sam @Alex #Writing\n" +
+		"- [x] Kick off\n" +
+		"  * [X] Nested done\n" +
+		"1. [ ] Numbered item\n" +
+		"- [ ]\n" +
+		"- plain item with [ ] inside\n" +
+		"mail me@example.com\n" +
+		"```\n- [ ] not a task\n```\n"
+
+	tasks := search.ParseTasks([]byte(content))
+	require.Len(t, tasks, 4)
+
+	assert.Equal(t, search.Task{
+		Line:   6,
+		Text:   "Write the spec due:2024-05-01 @sam @Alex #Writing",
+		Due:    "2024-05-01",
+		Owners: []string{"sam", "Alex"},
+		Tags:   []string{"writing"},
+	}, tasks[0])
+	assert.True(t, tasks[1].Done)
+	assert.Equal(t, 8, tasks[2].Line)
+	assert.True(t, tasks[2].Done, "an uppercase X ticks the box")
+	assert.Equal(t, "Numbered item", tasks[3].Text)
+}
+
+func TestFilterTasks(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) // a Wednesday
+	tasks := []services.NoteTask{
+		{ID: "a", Path: "work/plan.md", Title: "Plan", NoteTags: []string{"work"},
+			Task: search.Task{Text: "Overdue", Due: "2024-04-30", Owners: []string{"sam"}}},
+		{ID: "b", Path: "work/plan.md", Title: "Plan", NoteTags: []string{"work"},
+			Task: search.Task{Text: "Today", Due: "2024-05-01", Tags: []string{"urgent"}}},
+		{ID: "c", Path: "home.md", Title: "Home",
+			Task: search.Task{Text: "Next week", Due: "2024-05-08", Owners: []string{"alex"}}},
+		{ID: "d", Path: "home.md", Title: "Home",
+			Task: search.Task{Text: "Finished", Done: true}},
+	}
+
+	ids := func(where string) []string {
+		t.Helper()
+		filtered, err := services.FilterTasks(tasks, where, now)
+		require.NoError(t, err)
+		var result []string
+		for _, task := range filtered {
+			result = append(result, task.ID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"a", "b", "c"}, ids("status:open"))
+	assert.Equal(t, []string{"d"}, ids("status:done"))
+	assert.Equal(t, []string{"a"}, ids("due:<today"))
+	assert.Equal(t, []string{"b"}, ids("due:today"))
+	assert.Equal(t, []string{"a", "b"}, ids("due:this-week"))
+	assert.Equal(t, []string{"c"}, ids("due:>this-week"))
+	assert.Equal(t, []string{"b", "c"}, ids("due:>=today due:<=today+7"))
+	assert.Equal(t, []string{"c"}, ids("due:2024-05-08"))
+	assert.Equal(t, []string{"a", "c"}, ids("has:owner"))
+	assert.Equal(t, []string{"d"}, ids("missing:due"))
+	assert.Equal(t, []string{"a"}, ids("owner:@SAM"))
+	assert.Equal(t, []string{"a", "b"}, ids("tag:work"), "note tags apply to their tasks")
+	assert.Equal(t, []string{"b"}, ids("tag:#urgent"))
+	assert.Equal(t, []string{"a", "b"}, ids("path:work"))
+	assert.Equal(t, []string{"c", "d"}, ids("title:home"))
+	assert.Equal(t, []string{"b", "c"}, ids("owner:alex OR tag:urgent"))
+	assert.Equal(t, []string{"c", "d"}, ids("-tag:work"))
+	assert.Equal(t, []string{"c"}, ids("week"))
+
+	_, err := services.FilterTasks(tasks, "status:blocked", now)
+	assert.ErrorContains(t, err, `unknown task status "blocked"`)
+	_, err = services.FilterTasks(tasks, "due:someday", now)
+	assert.ErrorContains(t, err, `unrecognized date "someday"`)
+
+	assert.True(t, services.TaskQueryMentions("tag:work -status:done", "status"))
+	assert.False(t, services.TaskQueryMentions("tag:work", "status"))
+}
+
+func TestGroupTasks(t *testing.T) {
+	tasks := []services.NoteTask{
+		{Path: "b.md", Task: search.Task{Due: "2024-05-02"}},
+		{Path: "a.md"},
+		{Path: "a.md", Task: search.Task{Due: "2024-05-01"}},
+	}
+
+	byNote, err := services.GroupTasks(tasks, services.TaskGroupNote)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.md", "b.md"}, services.SortedTaskGroupKeys(byNote))
+	assert.Len(t, byNote["a.md"], 2)
+
+	byDue, err := services.GroupTasks(tasks, services.TaskGroupDue)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-05-01", "2024-05-02", services.TaskNoDueGroup}, services.SortedTaskGroupKeys(byDue))
+
+	_, err = services.GroupTasks(tasks, "owner")
+	assert.ErrorContains(t, err, `cannot group tasks by "owner"`)
+}
+
+func TestNoteService_Tasks(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	cfg, _ := services.NewConfigServiceWithPath(tmpDir + "/config.json")
+
+	notebookDir := testutil.CreateTestNotebook(t, tmpDir, "test-notebook")
+	testutil.CreateTestNote(t, notebookDir, "plan.md", "---\ntitle: Plan\ntags: [work]\n---\n\n- [ ] Draft @sam\n- [ ] Draft @sam\n- [x] Done already\n")
+	testutil.CreateTestNote(t, notebookDir, "empty.md", "# Nothing to do\n")
+
+	idx := testutil.CreateTestIndex(t, notebookDir)
+	svc := services.NewNoteService(cfg, idx, notebookDir)
+
+	tasks, err := svc.Tasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+	assert.Equal(t, "notes/plan.md", tasks[0].Path)
+	assert.Equal(t, "Plan", tasks[0].Title)
+	assert.Equal(t, []string{"work"}, tasks[0].NoteTags)
+	assert.Equal(t, 6, tasks[0].Line)
+	assert.Len(t, tasks[0].ID, 7)
+	assert.NotEqual(t, tasks[0].ID, tasks[1].ID, "duplicate tasks get distinct IDs")
+
+	change, task, err := svc.PlanTaskDone(ctx, tasks[1].ID)
+	require.NoError(t, err)
+	require.NotNil(t, change)
+	assert.Equal(t, 7, task.Line)
+	assert.Contains(t, change.After, "- [ ] Draft @sam\n- [x] Draft @sam\n")
+	assert.Contains(t, change.After, "modified:")
+	require.NoError(t, svc.ApplyChange(ctx, change))
+
+	content, err := os.ReadFile(filepath.Join(notebookDir, "notes", "plan.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "- [x] Draft @sam")
+
+	// Stamping "modified" moved the last task down to line 9
+	change, task, err = svc.PlanTaskDone(ctx, "notes/plan.md:9")
+	require.NoError(t, err)
+	assert.Nil(t, change, "ticking a done task changes nothing")
+	assert.Equal(t, "Done already", task.Text)
+
+	_, _, err = svc.PlanTaskDone(ctx, "notes/plan.md:1")
+	assert.ErrorContains(t, err, "no task on line 1")
+	_, _, err = svc.PlanTaskDone(ctx, "0000000")
+	assert.ErrorContains(t, err, "task not found: 0000000")
+}
+
+func TestNoteService_TasksSingularTag(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, map[string]string{
+		"legacy.md": "---\ntitle: Legacy\ntag: old\n---\n\n- [ ] Migrate\n",
+	})
+
+	tasks, err := svc.Tasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, []string{"old"}, tasks[0].NoteTags)
+	filtered, err := services.FilterTasks(tasks, "tag:old", time.Now())
+	require.NoError(t, err)
+	assert.Len(t, filtered, 1, "tasks match the note's singular tag field")
+}
@@ -0,0 +1,36 @@
+// Filters the notes listed on the index page with search-index.json.
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  var all = document.getElementById("all");
+  if (!input) return;
+
+  var index = null;
+  fetch("search-index.json")
+    .then(function (response) { return response.json(); })
+    .then(function (notes) { index = notes; search(); });
+
+  function search() {
+    var words = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (!index || words.length === 0) {
+      results.hidden = true;
+      all.hidden = false;
+      return;
+    }
+    results.innerHTML = "";
+    index.forEach(function (note) {
+      var text = (note.title + " " + note.tags.join(" ") + " " + note.text).toLowerCase();
+      if (!words.every(function (word) { return text.indexOf(word) >= 0; })) return;
+      var item = document.createElement("li");
+      var link = document.createElement("a");
+      link.href = note.url;
+      link.textContent = note.title;
+      item.appendChild(link);
+      results.appendChild(item);
+    });
+    results.hidden = false;
+    all.hidden = true;
+  }
+
+  input.addEventListener("input", search);
+})();
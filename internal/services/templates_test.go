@@ -297,19 +297,18 @@ func TestTuiRender_NotebookList_Empty(t *testing.T) {
 }
 
 func TestTuiRender_NoteDetail(t *testing.T) {
-	// Create note with file info
-	note := Note{
-		Content:  "This is the note content.",
-		Metadata: map[string]any{"author": "Test User", "tags": "test, example"},
-	}
-	note.File.Relative = "notes/my-note.md"
-	note.File.Filepath = "/path/to/notes/my-note.md"
-
-	ctx := map[string]any{
-		"Title":    "My Note",
-		"File":     note.File,
-		"Metadata": note.Metadata,
-		"Content":  note.Content,
+	ctx := &NoteDetail{
+		Path:  "notes/my-note.md",
+		Title: "My Note",
+		Frontmatter: []FrontmatterField{
+			{Key: "author", Value: "Test User"},
+			{Key: "tags", Value: "test, example"},
+		},
+		Body:      "This is the note content.",
+		Backlinks: []NoteRef{{Path: "notes/index.md", Title: "Index"}},
+		Related: []RelatedNote{
+			{NoteRef: NoteRef{Path: "notes/other.md", Title: "Other"}, SharedTags: []string{"test"}},
+		},
 	}
 
 	result, err := TuiRender("note-detail", ctx)
@@ -317,16 +316,10 @@ func TestTuiRender_NoteDetail(t *testing.T) {
 		t.Fatalf("TuiRender() failed: %v", err)
 	}
 
-	if !strings.Contains(result, "My Note") {
-		t.Errorf("TuiRender() result = %q, want to contain 'My Note'", result)
-	}
-
-	if !strings.Contains(result, "my-note.md") {
-		t.Errorf("TuiRender() result = %q, want to contain 'my-note.md'", result)
-	}
-
-	if !strings.Contains(result, "note content") {
-		t.Errorf("TuiRender() result = %q, want to contain 'note content'", result)
+	for _, want := range []string{"My Note", "my-note.md", "note content", "Test User", "Backlinks", "Index", "Related notes", "Other"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("TuiRender() result = %q, want to contain %q", result, want)
+		}
 	}
 }
 
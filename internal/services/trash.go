@@ -0,0 +1,218 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zenobi-us/jot/internal/core"
+)
+
+// TrashDir is where removed notes are kept, relative to the notebook root.
+// It lives under .jot so the indexer never picks trashed notes up.
+const TrashDir = ".jot/trash"
+
+// trashManifest records where each trashed note came from.
+const trashManifest = TrashDir + "/manifest.json"
+
+// TrashEntry is a note in the notebook trash.
+type TrashEntry struct {
+	// ID identifies the entry and names its file inside the trash.
+	ID string `json:"id"`
+	// Path is the note's original path.
+	Path string `json:"path"`
+	// RemovedAt is when the note was moved to the trash.
+	RemovedAt time.Time `json:"removed_at"`
+}
+
+// TrashPath returns where the entry's content is stored.
+func (e TrashEntry) TrashPath() string {
+	return path.Join(TrashDir, e.ID+".md")
+}
+
+// Trash moves a note into the notebook trash and drops it from the index.
+func (s *NoteService) Trash(ctx context.Context, relPath string) (*TrashEntry, error) {
+	if !s.storage.Exists(relPath) {
+		return nil, fmt.Errorf("note not found: %s", relPath)
+	}
+
+	entries, err := s.readTrashManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	entry := TrashEntry{
+		ID:        s.newTrashID(relPath, now),
+		Path:      relPath,
+		RemovedAt: now,
+	}
+
+	if err := s.storage.Rename(relPath, entry.TrashPath()); err != nil {
+		return nil, fmt.Errorf("failed to move note to trash: %w", err)
+	}
+
+	if err := s.writeTrashManifest(append(entries, entry)); err != nil {
+		// Put the note back rather than leave it in the trash untracked
+		_ = s.storage.Rename(entry.TrashPath(), relPath)
+		return nil, err
+	}
+
+	if s.index != nil {
+		if err := s.index.Remove(ctx, relPath); err != nil {
+			s.log.Warn().Err(err).Str("path", relPath).Msg("failed to remove trashed note from index")
+		}
+	}
+
+	return &entry, nil
+}
+
+// newTrashID builds a readable, unique id from the removal time and note name.
+func (s *NoteService) newTrashID(relPath string, now time.Time) string {
+	name := core.Slugify(strings.TrimSuffix(path.Base(relPath), ".md"))
+	if name == "" {
+		name = "note"
+	}
+	base := now.Format("20060102-150405") + "-" + name
+
+	id := base
+	for i := 2; s.storage.Exists(path.Join(TrashDir, id+".md")); i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	return id
+}
+
+// TrashList returns the trashed notes, most recently removed first.
+func (s *NoteService) TrashList() ([]TrashEntry, error) {
+	entries, err := s.readTrashManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RemovedAt.After(entries[j].RemovedAt)
+	})
+	return entries, nil
+}
+
+// Restore moves a trashed note back to its original path and re-indexes it.
+// query is an entry id or an original path (the .md extension is optional);
+// when a path was trashed several times the most recent copy is restored.
+func (s *NoteService) Restore(ctx context.Context, query string) (*TrashEntry, error) {
+	entries, err := s.TrashList()
+	if err != nil {
+		return nil, err
+	}
+
+	withExt := query
+	if !strings.HasSuffix(withExt, ".md") {
+		withExt += ".md"
+	}
+
+	idx := -1
+	for i, e := range entries {
+		if e.ID == query || e.Path == query || e.Path == withExt {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("no trashed note matches %q", query)
+	}
+	entry := entries[idx]
+
+	if s.storage.Exists(entry.Path) {
+		return nil, fmt.Errorf("cannot restore %s: a note already exists at that path", entry.Path)
+	}
+
+	if err := s.storage.Rename(entry.TrashPath(), entry.Path); err != nil {
+		return nil, fmt.Errorf("failed to restore note: %w", err)
+	}
+
+	if err := s.writeTrashManifest(append(entries[:idx:idx], entries[idx+1:]...)); err != nil {
+		return nil, err
+	}
+
+	if s.index != nil {
+		if err := s.IndexFile(ctx, entry.Path); err != nil {
+			s.log.Warn().Err(err).Str("path", entry.Path).Msg("failed to index restored note")
+		}
+	}
+
+	return &entry, nil
+}
+
+// PurgeTrash permanently deletes trashed notes removed more than olderThan
+// before now. A zero olderThan empties the trash. When a note can't be
+// deleted, the notes purged before it are still dropped from the manifest
+// and returned with the error.
+func (s *NoteService) PurgeTrash(olderThan time.Duration, now time.Time) ([]TrashEntry, error) {
+	entries, err := s.TrashList()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := now.Add(-olderThan)
+	var kept, purged []TrashEntry
+	for i, e := range entries {
+		if olderThan > 0 && e.RemovedAt.After(cutoff) {
+			kept = append(kept, e)
+			continue
+		}
+		if err := s.storage.Remove(e.TrashPath()); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("failed to purge %s: %w", e.Path, err)
+			if len(purged) > 0 {
+				if werr := s.writeTrashManifest(append(kept, entries[i:]...)); werr != nil {
+					return purged, errors.Join(err, werr)
+				}
+			}
+			return purged, err
+		}
+		purged = append(purged, e)
+	}
+
+	if len(purged) > 0 {
+		if err := s.writeTrashManifest(kept); err != nil {
+			return nil, err
+		}
+	}
+
+	return purged, nil
+}
+
+func (s *NoteService) readTrashManifest() ([]TrashEntry, error) {
+	data, err := s.storage.Read(trashManifest)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []TrashEntry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash manifest: %w", err)
+	}
+
+	var entries []TrashEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid trash manifest %s: %w", trashManifest, err)
+	}
+	return entries, nil
+}
+
+func (s *NoteService) writeTrashManifest(entries []TrashEntry) error {
+	if entries == nil {
+		entries = []TrashEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := s.storage.Write(trashManifest, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write trash manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zenobi-us/jot/internal/testutil"
+)
+
+var trashNotes = map[string]string{
+	"standup.md": "# Standup\n",
+	"plan.md":    "See [[standup]].\n",
+}
+
+func TestNoteService_TrashAndRestore(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, trashNotes)
+
+	entry, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	assert.Equal(t, "notes/standup.md", entry.Path)
+
+	assert.NoFileExists(t, filepath.Join(notebookDir, "notes", "standup.md"))
+	assert.FileExists(t, filepath.Join(notebookDir, filepath.FromSlash(entry.TrashPath())))
+
+	notes, err := svc.ResolveNote(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	assert.Empty(t, notes, "trashed note should leave the index")
+
+	entries, err := svc.TrashList()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.ID, entries[0].ID)
+
+	restored, err := svc.Restore(ctx, "notes/standup")
+	require.NoError(t, err)
+	assert.Equal(t, "notes/standup.md", restored.Path)
+	assert.FileExists(t, filepath.Join(notebookDir, "notes", "standup.md"))
+
+	notes, err = svc.ResolveNote(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	assert.Len(t, notes, 1)
+
+	entries, err = svc.TrashList()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNoteService_Restore_Conflicts(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, trashNotes)
+
+	_, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	testutil.CreateTestNote(t, notebookDir, "standup.md", "# New standup\n")
+
+	_, err = svc.Restore(ctx, "notes/standup.md")
+	assert.ErrorContains(t, err, "already exists")
+
+	_, err = svc.Restore(ctx, "notes/unknown.md")
+	assert.ErrorContains(t, err, "no trashed note")
+}
+
+func TestNoteService_Trash_UniqueIDs(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, trashNotes)
+
+	first, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	testutil.CreateTestNote(t, notebookDir, "standup.md", "# Again\n")
+	second, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, first.ID, second.ID)
+}
+
+func TestNoteService_PurgeTrash(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, trashNotes)
+
+	entry, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	_, err = svc.Trash(ctx, "notes/plan.md")
+	require.NoError(t, err)
+
+	purged, err := svc.PurgeTrash(24*time.Hour, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, purged, "recently trashed notes are kept")
+
+	purged, err = svc.PurgeTrash(24*time.Hour, time.Now().Add(48*time.Hour))
+	require.NoError(t, err)
+	assert.Len(t, purged, 2)
+
+	_, err = os.Stat(filepath.Join(notebookDir, filepath.FromSlash(entry.TrashPath())))
+	assert.True(t, os.IsNotExist(err))
+
+	entries, err := svc.TrashList()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNoteService_PurgeTrash_PartialFailure(t *testing.T) {
+	ctx := context.Background()
+	svc, notebookDir := testutil.NewNoteService(t, trashNotes)
+
+	_, err := svc.Trash(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	_, err = svc.Trash(ctx, "notes/plan.md")
+	require.NoError(t, err)
+	entries, err := svc.TrashList()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	first, second := entries[0], entries[1]
+
+	// A non-empty directory in place of the trashed file can't be removed
+	stuck := filepath.Join(notebookDir, filepath.FromSlash(second.TrashPath()))
+	require.NoError(t, os.Remove(stuck))
+	require.NoError(t, os.MkdirAll(filepath.Join(stuck, "child"), 0755))
+
+	purged, err := svc.PurgeTrash(0, time.Now())
+	assert.ErrorContains(t, err, "failed to purge "+second.Path)
+	require.Len(t, purged, 1)
+	assert.Equal(t, first.ID, purged[0].ID)
+
+	entries, err = svc.TrashList()
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "the purged note leaves the manifest")
+	assert.Equal(t, second.ID, entries[0].ID)
+}
+
+func TestNoteService_Backlinks(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := testutil.NewNoteService(t, trashNotes)
+
+	refs, err := svc.Backlinks(ctx, "notes/standup.md")
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	assert.Equal(t, "notes/plan.md", refs[0].Path)
+}
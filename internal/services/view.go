@@ -46,9 +46,9 @@ func NewViewServiceWithConfigPath(cfg *ConfigService, notebookPath string, globa
 	return vs
 }
 
-// initializeBuiltinViews creates all 6 built-in view definitions using DSL query strings.
+// initializeBuiltinViews creates all 7 built-in view definitions using DSL query strings.
 // Views use pipe syntax: "filter DSL | directives"
-// Special views (orphans, broken-links) use Type: "special" for custom execution.
+// Special views (orphans, broken-links, tasks) use Type: "special" for custom execution.
 func (vs *ViewService) initializeBuiltinViews() {
 	// Today view: Notes created or updated today
 	vs.builtinViews["today"] = &core.ViewDefinition{
@@ -91,6 +91,14 @@ func (vs *ViewService) initializeBuiltinViews() {
 		Description: "Notes containing links to non-existent files",
 		Type:        "special",
 	}
+
+	// Tasks view: Open task list items grouped by note (special view)
+	vs.builtinViews["tasks"] = &core.ViewDefinition{
+		Name:        "tasks",
+		Description: "Open tasks grouped by note (--group due for due date)",
+		Query:       "status:open | group:note",
+		Type:        "special",
+	}
 }
 
 // GetView retrieves a view by name, checking hierarchy: notebook > global > built-in
@@ -319,33 +327,46 @@ func (vs *ViewService) loadGlobalView(name string) (*core.ViewDefinition, error)
 
 // ResolveTemplateVariables resolves template variables in a string
 func (vs *ViewService) ResolveTemplateVariables(value string) string {
-	now := time.Now()
-
+	return resolveTemplateVariables(value, time.Now())
+}
+
+// dateVariables returns the static date variables, keyed by name, as of now.
+// Note templates expose the same names as functions.
+func dateVariables(now time.Time) map[string]string {
+	return map[string]string{
+		"today":            now.Format("2006-01-02"),
+		"yesterday":        now.AddDate(0, 0, -1).Format("2006-01-02"),
+		"this_week":        getStartOfWeek(now).Format("2006-01-02"),
+		"this_month":       now.Format("2006-01") + "-01",
+		"start_of_month":   now.Format("2006-01") + "-01",
+		"end_of_month":     getEndOfMonth(now).Format("2006-01-02"),
+		"now":              now.Format(time.RFC3339),
+		"next_week":        getStartOfWeek(now.AddDate(0, 0, 7)).Format("2006-01-02"),
+		"next_month":       getFirstOfMonth(now.AddDate(0, 1, 0)).Format("2006-01-02"),
+		"last_week":        getStartOfWeek(now.AddDate(0, 0, -7)).Format("2006-01-02"),
+		"last_month":       getFirstOfMonth(now.AddDate(0, -1, 0)).Format("2006-01-02"),
+		"quarter":          getCurrentQuarter(now),
+		"year":             now.Format("2006"),
+		"start_of_quarter": getStartOfQuarter(now).Format("2006-01-02"),
+		"end_of_quarter":   getEndOfQuarter(now).Format("2006-01-02"),
+	}
+}
+
+// resolveTemplateVariables resolves {{name}}, date arithmetic and environment
+// placeholders in value relative to now.
+func resolveTemplateVariables(value string, now time.Time) string {
 	// Static replacements (no parsing needed)
-	replacements := map[string]string{
-		"{{today}}":            now.Format("2006-01-02"),
-		"{{yesterday}}":        now.AddDate(0, 0, -1).Format("2006-01-02"),
-		"{{this_week}}":        getStartOfWeek(now).Format("2006-01-02"),
-		"{{this_month}}":       now.Format("2006-01") + "-01",
-		"{{start_of_month}}":   now.Format("2006-01") + "-01",
-		"{{end_of_month}}":     getEndOfMonth(now).Format("2006-01-02"),
-		"{{now}}":              now.Format(time.RFC3339),
-		"{{next_week}}":        getStartOfWeek(now.AddDate(0, 0, 7)).Format("2006-01-02"),
-		"{{next_month}}":       getFirstOfMonth(now.AddDate(0, 1, 0)).Format("2006-01-02"),
-		"{{last_week}}":        getStartOfWeek(now.AddDate(0, 0, -7)).Format("2006-01-02"),
-		"{{last_month}}":       getFirstOfMonth(now.AddDate(0, -1, 0)).Format("2006-01-02"),
-		"{{quarter}}":          getCurrentQuarter(now),
-		"{{year}}":             now.Format("2006"),
-		"{{start_of_quarter}}": getStartOfQuarter(now).Format("2006-01-02"),
-		"{{end_of_quarter}}":   getEndOfQuarter(now).Format("2006-01-02"),
-	}
-
-	for placeholder, replacement := range replacements {
-		value = strings.ReplaceAll(value, placeholder, replacement)
+	for name, replacement := range dateVariables(now) {
+		value = strings.ReplaceAll(value, "{{"+name+"}}", replacement)
 	}
 
 	// Dynamic replacements requiring pattern parsing
+	return resolveDynamicVariables(value, now)
+}
 
+// resolveDynamicVariables resolves the placeholders that need pattern
+// parsing: date arithmetic and environment variables.
+func resolveDynamicVariables(value string, now time.Time) string {
 	// Handle {{today-N}}, {{today+N}} patterns (time arithmetic by days)
 	value = resolveDayArithmetic(value, now)
 
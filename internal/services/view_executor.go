@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/zenobi-us/jot/internal/core"
 	"github.com/zenobi-us/jot/internal/search"
@@ -19,6 +20,10 @@ type ViewResults struct {
 	// Groups contains grouped results (when group directive is used)
 	// Key is the group value (e.g., "todo", "done" for group:status)
 	Groups map[string][]Note
+
+	// TaskGroups contains the task list items of the tasks view, keyed by
+	// note path or due date
+	TaskGroups map[string][]NoteTask
 }
 
 // ViewDirectiveOverrides captures runtime overrides for directives supplied via CLI flags.
@@ -51,7 +56,7 @@ func NewViewExecutor(index search.Index, noteService *NoteService) *ViewExecutor
 func (ve *ViewExecutor) ExecuteView(ctx context.Context, view *core.ViewDefinition, params map[string]string, overrides *ViewDirectiveOverrides, viewService *ViewService) (*ViewResults, error) {
 	// Handle special views
 	if view.IsSpecialView() {
-		return ve.executeSpecialView(ctx, view)
+		return ve.executeSpecialView(ctx, view, overrides)
 	}
 
 	resolvedQuery, err := ve.resolveQueryWithParameters(view, params, viewService)
@@ -291,7 +296,7 @@ func (ve *ViewExecutor) getNoteFieldValue(note Note, field string) string {
 }
 
 // executeSpecialView dispatches to special view executor
-func (ve *ViewExecutor) executeSpecialView(ctx context.Context, view *core.ViewDefinition) (*ViewResults, error) {
+func (ve *ViewExecutor) executeSpecialView(ctx context.Context, view *core.ViewDefinition, overrides *ViewDirectiveOverrides) (*ViewResults, error) {
 	if ve.noteService == nil {
 		return nil, fmt.Errorf("note service not available for special view execution")
 	}
@@ -313,11 +318,42 @@ func (ve *ViewExecutor) executeSpecialView(ctx context.Context, view *core.ViewD
 		}
 		return &ViewResults{Notes: convertMapSliceToNotes(results)}, nil
 
+	case "tasks":
+		return ve.executeTasksView(ctx, view, overrides)
+
 	default:
 		return nil, fmt.Errorf("unknown special view: %s", view.Name)
 	}
 }
 
+// executeTasksView filters the task list items of all notes with the view's
+// filter DSL and groups them by note (the default) or due date.
+func (ve *ViewExecutor) executeTasksView(ctx context.Context, view *core.ViewDefinition, overrides *ViewDirectiveOverrides) (*ViewResults, error) {
+	filterPart, directivesPart := SplitViewQuery(view.Query)
+	directives, err := ParseDirectives(directivesPart)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse directives: %w", err)
+	}
+	directives = applyDirectiveOverrides(directives, overrides)
+	if directives.GroupBy == "" {
+		directives.GroupBy = TaskGroupNote
+	}
+
+	tasks, err := ve.noteService.Tasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if tasks, err = FilterTasks(tasks, filterPart, time.Now()); err != nil {
+		return nil, err
+	}
+
+	groups, err := GroupTasks(tasks, directives.GroupBy)
+	if err != nil {
+		return nil, err
+	}
+	return &ViewResults{TaskGroups: groups}, nil
+}
+
 // convertMapSliceToNotes converts special view results to Note slice
 func convertMapSliceToNotes(results []map[string]interface{}) []Note {
 	notes := make([]Note, len(results))
@@ -32,17 +32,14 @@ func (sve *SpecialViewExecutor) ExecuteBrokenLinksView(ctx context.Context) ([]m
 		return nil, fmt.Errorf("failed to get all notes: %w", err)
 	}
 
-	// Build a map of all existing note paths for quick lookup
-	existingPaths := make(map[string]bool)
-	for _, note := range notes {
-		existingPaths[note.File.Relative] = true
-	}
+	// Resolve links the same way backlinks do
+	graph := NewLinkGraph(notes)
 
 	// Track notes with broken links
 	notesWithBrokenLinks := make([]map[string]interface{}, 0)
 
 	for _, note := range notes {
-		brokenLinks := sve.findBrokenLinks(&note, existingPaths)
+		brokenLinks := sve.findBrokenLinks(&note, graph)
 		if len(brokenLinks) > 0 {
 			result := map[string]interface{}{
 				"file_path":     note.File.Filepath,
@@ -63,60 +60,18 @@ func (sve *SpecialViewExecutor) ExecuteBrokenLinksView(ctx context.Context) ([]m
 	return notesWithBrokenLinks, nil
 }
 
-// findBrokenLinks extracts all broken links from a note
-// Checks both frontmatter links and markdown body links
-func (sve *SpecialViewExecutor) findBrokenLinks(note *Note, existingPaths map[string]bool) []string {
+// findBrokenLinks returns the targets of a note's links that resolve to
+// no note, resolved like backlinks: relative markdown links, wikilinks by
+// path, ID, title or alias, and labelled links all count as working.
+func (sve *SpecialViewExecutor) findBrokenLinks(note *Note, graph *LinkGraph) []string {
 	brokenLinks := make([]string, 0)
 	foundLinks := make(map[string]bool) // Deduplicate
-
-	// 1. Check frontmatter links (data.links array)
-	if metadata, ok := note.Metadata["links"]; ok {
-		if linksArray, ok := metadata.([]interface{}); ok {
-			for _, linkItem := range linksArray {
-				if linkStr, ok := linkItem.(string); ok {
-					if !existingPaths[linkStr] && !foundLinks[linkStr] {
-						brokenLinks = append(brokenLinks, linkStr)
-						foundLinks[linkStr] = true
-					}
-				}
-			}
+	for _, link := range graph.Outgoing(note.File.Relative) {
+		if link.Resolved == "" && !foundLinks[link.Target] {
+			brokenLinks = append(brokenLinks, link.Target)
+			foundLinks[link.Target] = true
 		}
 	}
-
-	// 2. Check markdown body links: [text](path) syntax
-	mdLinkPattern := regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
-	for _, match := range mdLinkPattern.FindAllStringSubmatch(note.Content, -1) {
-		if len(match) >= 3 {
-			linkPath := strings.TrimSpace(match[2])
-			// Skip external URLs
-			if !strings.HasPrefix(linkPath, "http://") && !strings.HasPrefix(linkPath, "https://") && !strings.HasPrefix(linkPath, "#") {
-				// Normalize path (remove .md if present, paths are usually without extension in frontmatter)
-				normalizedPath := strings.TrimSuffix(linkPath, ".md")
-				if !existingPaths[linkPath] && !existingPaths[normalizedPath] && !foundLinks[linkPath] {
-					brokenLinks = append(brokenLinks, linkPath)
-					foundLinks[linkPath] = true
-				}
-			}
-		}
-	}
-
-	// 3. Check wiki-style links: [[wikilink]] syntax
-	wikiLinkPattern := regexp.MustCompile(`\[\[([^\]]+)\]\]`)
-	for _, match := range wikiLinkPattern.FindAllStringSubmatch(note.Content, -1) {
-		if len(match) >= 2 {
-			linkPath := strings.TrimSpace(match[1])
-			// Add .md extension for wiki links if not present
-			mdPath := linkPath
-			if !strings.HasSuffix(mdPath, ".md") {
-				mdPath = linkPath + ".md"
-			}
-			if !existingPaths[linkPath] && !existingPaths[mdPath] && !foundLinks[linkPath] {
-				brokenLinks = append(brokenLinks, linkPath)
-				foundLinks[linkPath] = true
-			}
-		}
-	}
-
 	return brokenLinks
 }
 
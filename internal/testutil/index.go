@@ -3,6 +3,7 @@ package testutil
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -78,6 +79,7 @@ func populateIndexFromNotebook(t *testing.T, idx search.Index, notebookDir strin
 		// Create a document from the markdown file
 		doc := search.Document{
 			Path:     relPath,
+			ID:       extractID(metadata),
 			Title:    getTitle(metadata, filepath.Base(relPath)),
 			Body:     body,
 			Lead:     extractLead(body),
@@ -86,6 +88,7 @@ func populateIndexFromNotebook(t *testing.T, idx search.Index, notebookDir strin
 			Created:  time.Now(),
 			Modified: time.Now(),
 			Checksum: "",
+			Tasks:    search.ParseTasks(content),
 		}
 
 		// Add to index
@@ -144,6 +147,18 @@ func getTitle(metadata map[string]any, defaultTitle string) string {
 	return ""
 }
 
+// extractID extracts the note id from metadata, which YAML parses as a
+// number when a timestamp id is unquoted
+func extractID(metadata map[string]any) string {
+	switch v := metadata["id"].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case int, int64, uint64:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
 // extractTags extracts tags from metadata (supports both "tag" and "tags" fields)
 func extractTags(metadata map[string]any) []string {
 	// Handle both "tag" and "tags" fields
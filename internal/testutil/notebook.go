@@ -5,6 +5,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/zenobi-us/jot/internal/services"
 )
 
 // NotebookConfig represents a notebook configuration file.
@@ -72,6 +74,29 @@ func CreateTestNote(t *testing.T, notebookDir, filename, content string) string
 	return notePath
 }
 
+// NewNoteService creates a notebook holding files, keyed by path under its
+// notes directory, and returns a note service over an index of them with
+// the notebook directory. Note paths in the service start with "notes/".
+func NewNoteService(t *testing.T, files map[string]string) (*services.NoteService, string) {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	notebookDir := CreateTestNotebook(t, tmpDir, "test-notebook")
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Join(notebookDir, "notes", filepath.Dir(filepath.FromSlash(name))), 0755); err != nil {
+			t.Fatalf("failed to create note directory: %v", err)
+		}
+		CreateTestNote(t, notebookDir, filepath.FromSlash(name), content)
+	}
+
+	cfg, err := services.NewConfigServiceWithPath(filepath.Join(tmpDir, "config.json"))
+	if err != nil {
+		t.Fatalf("failed to create config service: %v", err)
+	}
+	idx := CreateTestIndex(t, notebookDir)
+	return services.NewNoteService(cfg, idx, notebookDir), notebookDir
+}
+
 // CreateTestNoteWithFrontmatter creates a note with YAML frontmatter.
 func CreateTestNoteWithFrontmatter(t *testing.T, notebookDir, filename string, frontmatter map[string]interface{}, body string) string {
 	t.Helper()
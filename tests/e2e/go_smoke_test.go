@@ -7,8 +7,10 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 // testEnv holds shared test environment state.
@@ -481,6 +483,1149 @@ func TestCLI_NotesRemove_RemovesNote(t *testing.T) {
 	}
 }
 
+func TestCLI_NotesRemove_TrashAndRestore(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("trash-test")
+	notePath := env.createNote(notebookDir, "standup.md", "# Standup\n")
+	env.createNote(notebookDir, "plan.md", "See [[standup]].\n")
+
+	_, stderr, exitCode := env.runInDir(notebookDir, "notes", "rm", "--force", "standup")
+	if exitCode != 0 {
+		t.Fatalf("notes rm failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stderr, "still link to standup.md") || !strings.Contains(stderr, "plan.md") {
+		t.Errorf("expected a backlink warning, got: %s", stderr)
+	}
+
+	stdout, _, _ := env.runInDir(notebookDir, "notes", "list")
+	if strings.Contains(stdout, "standup") {
+		t.Errorf("trashed note should not be listed: %s", stdout)
+	}
+
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "trash", "list")
+	if exitCode != 0 || !strings.Contains(stdout, "standup.md") {
+		t.Errorf("trash list should show the note (exit %d): %s", exitCode, stdout)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "restore", "standup.md")
+	if exitCode != 0 {
+		t.Fatalf("notes restore failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(notePath); err != nil {
+		t.Errorf("note was not restored: %v", err)
+	}
+
+	env.runInDir(notebookDir, "notes", "rm", "--force", "standup")
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "trash", "purge", "--older-than", "30d")
+	if exitCode != 0 || !strings.Contains(stdout, "Purged 0 note(s)") {
+		t.Errorf("recent notes should survive purge (exit %d): %s", exitCode, stdout)
+	}
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "trash", "purge")
+	if exitCode != 0 || !strings.Contains(stdout, "Purged 1 note(s)") {
+		t.Errorf("purge without age should empty the trash (exit %d): %s", exitCode, stdout)
+	}
+}
+
+func TestCLI_NotesEdit_StampsModified(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("edit-test")
+	notePath := env.createNoteWithFrontmatter(notebookDir, "plan.md",
+		map[string]interface{}{"title": "Sprint Plan"}, "# Sprint Plan\n\nold text\n")
+
+	// A non-interactive "editor" that rewrites the file in place
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "sed -i s/old/new/")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "edit", "sprint plan")
+	if exitCode != 0 {
+		t.Fatalf("notes edit failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	if !strings.Contains(stdout, "Updated note: plan.md (+1 -1 lines)") {
+		t.Errorf("expected change report, got: %s", stdout)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatalf("failed to read note: %v", err)
+	}
+	if !strings.Contains(string(content), "new text") {
+		t.Errorf("expected edited body, got: %s", content)
+	}
+	if !strings.Contains(string(content), "modified:") {
+		t.Errorf("expected modified frontmatter, got: %s", content)
+	}
+
+	// Editing without changes leaves the note alone
+	t.Setenv("EDITOR", "true")
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "edit", "plan.md")
+	if exitCode != 0 || !strings.Contains(stdout, "No changes") {
+		t.Errorf("expected no changes, got exit %d: %s", exitCode, stdout)
+	}
+}
+
+func TestCLI_NotesShow_ModesAndFooters(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("show-test")
+	env.createNote(notebookDir, "standup.md", "---\ntitle: Standup\naliases: [daily-sync]\n---\n\nDaily notes.\n")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\n---\n\nSee [[daily-sync]].\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "show", "daily-sync", "--raw")
+	if exitCode != 0 {
+		t.Fatalf("notes show --raw failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if stdout != "---\ntitle: Standup\naliases: [daily-sync]\n---\n\nDaily notes.\n" {
+		t.Errorf("unexpected raw output: %q", stdout)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "show", "Standup", "--json")
+	if exitCode != 0 {
+		t.Fatalf("notes show --json failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	var detail struct {
+		Path      string `json:"path"`
+		Backlinks []struct {
+			Path string `json:"path"`
+		} `json:"backlinks"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &detail); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if detail.Path != "standup.md" || len(detail.Backlinks) != 1 || detail.Backlinks[0].Path != "plan.md" {
+		t.Errorf("unexpected detail: %+v", detail)
+	}
+
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "show", "standup.md", "--frontmatter-only")
+	if exitCode != 0 || stdout != "title: Standup\naliases: [daily-sync]\n" {
+		t.Errorf("unexpected frontmatter output (exit %d): %q", exitCode, stdout)
+	}
+}
+
+func TestCLI_NotesSetUnsetTag(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("fields-test")
+	notePath := env.createNote(notebookDir, "task.md", "---\ntitle: Task # keep\nowner: alice\ntags: [work]\n---\n\nBody.\n")
+
+	_, stderr, exitCode := env.runInDir(notebookDir, "notes", "set", "task.md", "status=done", "priority=2")
+	if exitCode != 0 {
+		t.Fatalf("notes set failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "unset", "Task", "owner")
+	if exitCode != 0 {
+		t.Fatalf("notes unset failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "tag", "add", "task.md", "#review")
+	if exitCode != 0 {
+		t.Fatalf("notes tag add failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Updated note: task.md") {
+		t.Errorf("unexpected tag output: %s", stdout)
+	}
+
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"title: Task # keep\n", "tags: [work, review]\n", "status: done\n", "priority: 2\n", "modified: "} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in note:\n%s", want, content)
+		}
+	}
+	if strings.Contains(string(content), "owner") {
+		t.Errorf("owner should be removed:\n%s", content)
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "notes", "search", "query", "--and", "data.status=done")
+	if !strings.Contains(stdout, "task.md") {
+		t.Errorf("updated field should be searchable: %s", stdout)
+	}
+}
+
+func TestCLI_BulkMutations(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("bulk-test")
+	env.createNote(notebookDir, "a.md", "---\ntitle: A\ntags: [sprint-41]\nstatus: done\n---\n\nA\n")
+	env.createNote(notebookDir, "b.md", "---\ntitle: B\ntags: [sprint-41]\nstatus: todo\n---\n\nB\n")
+	env.createNote(notebookDir, "c.md", "---\ntitle: C\ntags: [sprint-42]\n---\n\nSee [a](a.md).\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "set", "--where", "tag:sprint-41", "status=archived", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("bulk set --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Would update 2 of 2 note(s)") || !strings.Contains(stdout, "+status: archived") {
+		t.Errorf("unexpected dry run output:\n%s", stdout)
+	}
+	content, _ := os.ReadFile(filepath.Join(notebookDir, ".notes", "a.md"))
+	if strings.Contains(string(content), "archived") {
+		t.Fatal("dry run must not write")
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "set", "--where", "tag:sprint-41", "status=archived")
+	if exitCode != 0 || !strings.Contains(stdout, "Updated 2 of 2 note(s)") {
+		t.Fatalf("bulk set failed (exit %d): %s %s", exitCode, stdout, stderr)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "move", "--where", "status:archived", "archive")
+	if exitCode != 0 {
+		t.Fatalf("bulk move failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	for _, name := range []string{"a.md", "b.md"} {
+		if _, err := os.Stat(filepath.Join(notebookDir, ".notes", "archive", name)); err != nil {
+			t.Errorf("%s was not moved: %v", name, err)
+		}
+	}
+	content, _ = os.ReadFile(filepath.Join(notebookDir, ".notes", "c.md"))
+	if !strings.Contains(string(content), "[a](archive/a.md)") {
+		t.Errorf("links were not rewritten by bulk move: %s", content)
+	}
+
+	stdout, _, exitCode = env.runInDir(notebookDir, "notes", "remove", "--where", "tag:sprint-42", "--dry-run")
+	if exitCode != 0 || !strings.Contains(stdout, "Would remove 1 note(s):\n  c.md") {
+		t.Errorf("unexpected remove dry run (exit %d): %s", exitCode, stdout)
+	}
+}
+
+func TestCLI_NotesAdd_AppliesGroups(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("groups-test")
+	configPath := filepath.Join(notebookDir, ".jot.json")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	config["templates"] = map[string]any{"meeting": "# {{title}}\n\n## Agenda\n"}
+	config["groups"] = []any{
+		map[string]any{"name": "Default", "globs": []string{"**/*.md"}, "metadata": map[string]any{"status": "draft", "owner": "team"}},
+		map[string]any{"name": "Meetings", "globs": []string{"meetings/**/*.md"}, "metadata": map[string]any{"status": "scheduled"}, "template": "meeting"},
+	}
+	data, _ = json.Marshal(config)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode := env.runInDir(notebookDir, "notes", "add", "Standup", "meetings/", "--data", "owner=alice")
+	if exitCode != 0 {
+		t.Fatalf("notes add failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "meetings", "standup.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"status: scheduled\n", "owner: alice\n", "## Agenda"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in note:\n%s", want, content)
+		}
+	}
+
+	stdout, _, exitCode := env.runInDir(notebookDir, "notebook", "groups", "meetings/x.md", "--json")
+	if exitCode != 0 || !strings.Contains(stdout, `"template": "meeting"`) {
+		t.Errorf("unexpected groups output (exit %d): %s", exitCode, stdout)
+	}
+}
+
+func TestCLI_NotesAdd_RendersTemplateFiles(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("template-test")
+	templateDir := filepath.Join(notebookDir, ".jot", "templates")
+	if err := os.MkdirAll(templateDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"review.md": "---\nstatus: open\n---\n\n# {{ .Title }}\n\nOwner: {{ prompt \"Owner\" }}\nDue: {{today+7}}\n\n{{ include \"footer\" }}\n",
+		"footer.md": "_Filed in {{ .Notebook }} at {{ .Path }}_",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(templateDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, stderr, exitCode := env.runInDir(notebookDir, "notes", "add", "Q3 Review", "reviews/", "--template", "review")
+	if exitCode == 0 || !strings.Contains(stderr, "--data owner=") {
+		t.Fatalf("expected a missing prompt value to fail without a terminal (exit %d), stderr: %s", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "add", "Q3 Review", "reviews/", "--template", "review", "--data", "owner=alice")
+	if exitCode != 0 {
+		t.Fatalf("notes add failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "reviews", "q3-review.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	due := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+	for _, want := range []string{"status: open\n", "title: Q3 Review\n", "owner: alice\n", "# Q3 Review", "Owner: alice", "Due: " + due, "_Filed in template-test at reviews/q3-review.md_"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in note:\n%s", want, content)
+		}
+	}
+	if strings.Count(string(content), "---\n") != 2 {
+		t.Errorf("expected a single frontmatter block:\n%s", content)
+	}
+}
+
+func TestCLI_PeriodicNotes(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("periodic-test")
+	configPath := filepath.Join(notebookDir, ".jot.json")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	config["templates"] = map[string]any{"day": "# {{ .Title }}\n\nYesterday: [[{{yesterday}}]]\n"}
+	config["periodic"] = map[string]any{"daily": map[string]any{"template": "day"}}
+	data, _ = json.Marshal(config)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "daily", "--date", "2024-05-02", "-1")
+	if exitCode != 0 {
+		t.Fatalf("daily failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if strings.TrimSpace(stdout) != "journal/2024-05-01.md" || !strings.Contains(stderr, "Created note: journal/2024-05-01.md") {
+		t.Errorf("unexpected daily output: stdout %q, stderr %q", stdout, stderr)
+	}
+
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "journal", "2024-05-01.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"period: daily\n", `date: "2024-05-01"`, "created: \"2024-05-01T", "# 2024-05-01", "Yesterday: [[2024-04-30]]"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("expected %q in note:\n%s", want, content)
+		}
+	}
+
+	// Opening an existing note does not recreate it
+	_, stderr, _ = env.runInDir(notebookDir, "daily", "--date", "2024-05-01")
+	if strings.Contains(stderr, "Created note") {
+		t.Errorf("existing note should not be created again: %s", stderr)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "daily", "--date", "2024-05-09", "--prev")
+	if exitCode != 0 || strings.TrimSpace(stdout) != "journal/2024-05-01.md" {
+		t.Errorf("daily --prev: exit %d, stdout %q, stderr %s", exitCode, stdout, stderr)
+	}
+
+	stdout, _, exitCode = env.runInDir(notebookDir, "weekly", "--date", "2024-05-01")
+	if exitCode != 0 || strings.TrimSpace(stdout) != "journal/2024-W18.md" {
+		t.Errorf("weekly: exit %d, stdout %q", exitCode, stdout)
+	}
+}
+
+func TestCLI_NoteIDs(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("id-test")
+	env.createNote(notebookDir, "plan.md", "---\ncreated: 2024-05-01T09:30:00Z\n---\n\n# Plan\n")
+	env.createNote(notebookDir, "index.md", "# Index\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "backfill-ids", "--format", "timestamp", "--where", "path:plan.md", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("notes backfill-ids --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Would add IDs to 1 note(s)") || !strings.Contains(stdout, `+id: "20240501093000"`) {
+		t.Errorf("unexpected dry run output:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "backfill-ids", "--format", "timestamp", "--where", "path:plan.md")
+	if exitCode != 0 {
+		t.Fatalf("notes backfill-ids failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	// Links by id survive moving the target
+	env.createNote(notebookDir, "index.md", "# Index\n\nSee [[20240501093000]].\n")
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "mv", "plan.md", "projects/")
+	if exitCode != 0 {
+		t.Fatalf("notes mv failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "show", "20240501093000")
+	if exitCode != 0 {
+		t.Fatalf("notes show by id failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "# Plan") || !strings.Contains(stdout, "index.md") {
+		t.Errorf("expected the moved note with its backlink, got:\n%s", stdout)
+	}
+
+	// New notes get ids once the notebook sets id_format
+	configPath := filepath.Join(notebookDir, ".jot.json")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	config["id_format"] = "ulid"
+	data, _ = json.Marshal(config)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "add", "Fresh", "fresh.md")
+	if exitCode != 0 {
+		t.Fatalf("notes add failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "fresh.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`(?m)^id: [0-9A-Z]{26}$`).Match(content) {
+		t.Errorf("expected a ULID in the new note:\n%s", content)
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "notes", "backfill-ids")
+	if !strings.Contains(stdout, "Added IDs to 1 note(s)") {
+		t.Errorf("expected only index.md to be backfilled, got: %s", stdout)
+	}
+}
+
+func TestCLI_FrontmatterSchema(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("schema-test")
+	configPath := filepath.Join(notebookDir, ".jot.json")
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+	config["schema"] = map[string]any{
+		"status": map[string]any{"enum": []string{"todo", "doing", "done"}},
+		"due":    map[string]any{"type": "date"},
+	}
+	config["groups"] = []any{
+		map[string]any{"name": "Projects", "globs": []string{"projects/**"}, "metadata": map[string]any{},
+			"schema": map[string]any{"owner": map[string]any{"required": true}}},
+	}
+	data, _ = json.Marshal(config)
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	env.createNote(notebookDir, "ok.md", "---\nstatus: done\ndue: 2024-05-01\n---\n\n# Ok\n")
+	env.createNote(notebookDir, "bad.md", "---\nstatus: wip\n---\n\n# Bad\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notebook", "validate")
+	if exitCode == 0 {
+		t.Fatalf("notebook validate should fail, stdout: %s", stdout)
+	}
+	if !strings.Contains(stdout, "- bad.md\n  - status: must be one of todo, doing, done, got \"wip\"") || strings.Contains(stdout, "ok.md") {
+		t.Errorf("unexpected validate output:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "1 violation(s) in 1 of 2 note(s)") {
+		t.Errorf("unexpected validate error: %s", stderr)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "add", "Apollo", "projects/", "--data", "status=todo")
+	if exitCode == 0 || !strings.Contains(stderr, "owner") {
+		t.Errorf("notes add without a required field should fail, stderr: %s", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(notebookDir, ".notes", "projects", "apollo.md")); err == nil {
+		t.Error("invalid note must not be written")
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "add", "Apollo", "projects/", "--data", "owner=alice")
+	if exitCode != 0 {
+		t.Fatalf("notes add failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "set", "ok.md", "due=someday")
+	if exitCode == 0 || !strings.Contains(stderr, "must be a date") {
+		t.Errorf("notes set breaking the schema should fail, stderr: %s", stderr)
+	}
+
+	// Notes that are already invalid can still be edited
+	_, stderr, exitCode = env.runInDir(notebookDir, "notes", "set", "bad.md", "priority=1")
+	if exitCode != 0 {
+		t.Errorf("notes set on an already invalid note failed: %s", stderr)
+	}
+}
+
+func TestCLI_Lint(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("lint-test")
+	env.createNote(notebookDir, "index.md", "---\ntitle: Index\n---\n\nSee [[missing]].\n")
+	env.createNote(notebookDir, "draft.md", "---\ncreated: \"2024-05-01\"\n---\n\n# Draft\n")
+
+	stdout, _, exitCode := env.runInDir(notebookDir, "lint")
+	if exitCode == 0 {
+		t.Errorf("lint should fail with findings, got:\n%s", stdout)
+	}
+	for _, want := range []string{
+		"index.md:5: error broken-link:",
+		"draft.md:1: warning missing-title:",
+		"draft.md:2: warning date-format:",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in lint output:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "lint", "--format", "sarif", "--rule", "broken-link")
+	var sarif struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID string `json:"ruleId"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &sarif); err != nil {
+		t.Fatalf("invalid SARIF: %v\n%s", err, stdout)
+	}
+	if sarif.Version != "2.1.0" || len(sarif.Runs) != 1 || len(sarif.Runs[0].Results) != 1 || sarif.Runs[0].Results[0].RuleID != "broken-link" {
+		t.Errorf("unexpected SARIF: %s", stdout)
+	}
+
+	_, stderr, exitCode := env.runInDir(notebookDir, "lint", "--fix", "--fail-on", "error", "--rule", "missing-title", "--rule", "date-format")
+	if exitCode != 0 {
+		t.Fatalf("lint --fix failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "draft.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "title: Draft") || !strings.Contains(string(content), `created: "2024-05-01T00:00:00`) {
+		t.Errorf("lint --fix did not fix the note:\n%s", content)
+	}
+}
+
+func TestCLI_Tasks(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("tasks-test")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\n---\n\n- [ ] Write spec due:2024-05-01 @sam\n- [x] Kick off\n- [ ] Review @alex\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "tasks", "list", "--where", "owner:sam", "--format", "json")
+	if exitCode != 0 {
+		t.Fatalf("tasks list failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	var listed struct {
+		Tasks []struct {
+			ID   string `json:"id"`
+			Line int    `json:"line"`
+			Due  string `json:"due"`
+			Path string `json:"path"`
+		} `json:"tasks"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &listed); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(listed.Tasks) != 1 || listed.Tasks[0].Line != 5 || listed.Tasks[0].Due != "2024-05-01" || listed.Tasks[0].Path != "plan.md" {
+		t.Fatalf("unexpected tasks: %s", stdout)
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "tasks", "list")
+	if !strings.Contains(stdout, "Write spec") || !strings.Contains(stdout, "Review") || strings.Contains(stdout, "Kick off") {
+		t.Errorf("tasks list should show only open tasks:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "tasks", "done", listed.Tasks[0].ID)
+	if exitCode != 0 {
+		t.Fatalf("tasks done failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "plan.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- [x] Write spec due:2024-05-01 @sam") {
+		t.Errorf("tasks done did not tick the box:\n%s", content)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "view", "tasks", "--group", "due")
+	if exitCode != 0 {
+		t.Fatalf("notes view tasks failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "no due date") || !strings.Contains(stdout, "Review @alex") || strings.Contains(stdout, "Write spec") {
+		t.Errorf("unexpected tasks view:\n%s", stdout)
+	}
+}
+
+func TestCLI_Kanban(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("kanban-test")
+	configData := map[string]interface{}{
+		"name": "kanban-test",
+		"root": ".notes",
+		"board": map[string]interface{}{
+			"columns":    []string{"todo", "doing", "done"},
+			"wip_limits": map[string]int{"doing": 1},
+		},
+	}
+	configJSON, _ := json.Marshal(configData)
+	if err := os.WriteFile(filepath.Join(notebookDir, ".jot.json"), configJSON, 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	env.createNote(notebookDir, "launch.md", "---\ntitle: Launch\nstatus: todo\n---\n\nBody\n")
+	env.createNote(notebookDir, "docs.md", "---\ntitle: Docs\nstatus: doing\n---\n\nBody\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "kanban")
+	if exitCode != 0 {
+		t.Fatalf("kanban failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{"## todo (1)", "## doing (1/1)", "## done (0)", "Path: launch.md"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("kanban output missing %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Index(stdout, "## todo") > strings.Index(stdout, "## doing") {
+		t.Errorf("columns should follow the configured order:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "kanban", "move", "launch.md", "doing")
+	if exitCode == 0 || !strings.Contains(stderr, `column "doing" is at its WIP limit of 1`) {
+		t.Errorf("move into a full column should fail, exit %d, stderr: %s", exitCode, stderr)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "kanban", "move", "launch.md", "Doing", "--force")
+	if exitCode != 0 {
+		t.Fatalf("kanban move --force failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	content, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "launch.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "status: doing") {
+		t.Errorf("kanban move did not rewrite status:\n%s", content)
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "kanban", "--format", "json")
+	var board struct {
+		Field   string `json:"field"`
+		Columns []struct {
+			Name     string            `json:"name"`
+			WIPLimit int               `json:"wip_limit"`
+			Notes    []json.RawMessage `json:"notes"`
+		} `json:"columns"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &board); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if board.Field != "status" || len(board.Columns) != 3 || len(board.Columns[1].Notes) != 2 || board.Columns[1].WIPLimit != 1 {
+		t.Errorf("unexpected board: %s", stdout)
+	}
+
+	_, stderr, exitCode = env.runInDir(notebookDir, "kanban", "--view", "recent")
+	if exitCode == 0 || !strings.Contains(stderr, "has no group: directive") {
+		t.Errorf("a view without group: should be refused, exit %d, stderr: %s", exitCode, stderr)
+	}
+}
+
+func TestCLI_Git_AutoCommitHistoryDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("git-test")
+	configJSON, _ := json.Marshal(map[string]interface{}{
+		"name": "git-test",
+		"root": ".notes",
+		"git":  map[string]interface{}{"auto_commit": true},
+	})
+	if err := os.WriteFile(filepath.Join(notebookDir, ".jot.json"), configJSON, 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	gitCmd := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", notebookDir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+	gitCmd("init", "-q")
+	gitCmd("config", "user.name", "Sam")
+	gitCmd("config", "user.email", "sam@example.com")
+	gitCmd("add", ".")
+	gitCmd("commit", "-q", "-m", "init")
+
+	steps := [][]string{
+		{"notes", "add", "Launch Plan"},
+		{"notes", "set", "launch-plan.md", "status=todo"},
+		{"notes", "move", "launch-plan.md", "archive/"},
+	}
+	for _, args := range steps {
+		if _, stderr, exitCode := env.runInDir(notebookDir, args...); exitCode != 0 {
+			t.Fatalf("%v failed with exit code %d, stderr: %s", args, exitCode, stderr)
+		}
+	}
+
+	subjects := gitCmd("log", "--format=%s")
+	for _, want := range []string{`jot notes add "Launch Plan"`, "jot notes set launch-plan.md status=todo", "jot notes move launch-plan.md archive/"} {
+		if !strings.Contains(subjects, want) {
+			t.Errorf("git log missing auto-commit %q:\n%s", want, subjects)
+		}
+	}
+	if status := gitCmd("status", "--porcelain"); status != "" {
+		t.Errorf("auto-commit left changes behind:\n%s", status)
+	}
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "history", "archive/launch-plan.md")
+	if exitCode != 0 {
+		t.Fatalf("notes history failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout), "\n"); len(lines) != 3 || !strings.Contains(lines[2], "(as launch-plan.md)") {
+		t.Errorf("history should list 3 commits following the rename:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "diff", "archive/launch-plan.md", "HEAD~2")
+	if exitCode != 0 {
+		t.Fatalf("notes diff failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "--- launch-plan.md@") || !strings.Contains(stdout, "+status: todo") {
+		t.Errorf("diff against the first version should add the status:\n%s", stdout)
+	}
+
+	_, _, exitCode = env.runInDir(notebookDir, "notes", "set", "archive/launch-plan.md", "status=done", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("dry run failed with exit code %d", exitCode)
+	}
+	if count := strings.Count(gitCmd("log", "--format=%s"), "\n"); count != 4 {
+		t.Errorf("a dry run should not commit, got %d commits", count)
+	}
+}
+
+func TestCLI_Sync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	env := newTestEnv(t)
+
+	gitCmd := func(dir string, args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+		return string(out)
+	}
+
+	notebookDir := env.createNotebook("sync-test")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\ntags: [work]\n---\n\nBody\n")
+	remote := filepath.Join(env.tmpDir, "remote.git")
+	gitCmd(env.tmpDir, "init", "-q", "--bare", remote)
+	gitCmd(notebookDir, "init", "-q")
+	gitCmd(notebookDir, "config", "user.name", "Sam")
+	gitCmd(notebookDir, "config", "user.email", "sam@example.com")
+	gitCmd(notebookDir, "add", ".")
+	gitCmd(notebookDir, "commit", "-q", "-m", "init")
+	gitCmd(notebookDir, "remote", "add", "origin", remote)
+	gitCmd(notebookDir, "push", "-q", "-u", "origin", "HEAD")
+
+	env.createNote(notebookDir, "new.md", "# New\n")
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "sync", "-m", "Add new note")
+	if exitCode != 0 {
+		t.Fatalf("sync failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Committed local changes") || !strings.Contains(stdout, "Pushed to origin/") {
+		t.Errorf("unexpected sync output:\n%s", stdout)
+	}
+	if log := gitCmd(remote, "log", "--format=%s"); !strings.HasPrefix(log, "Add new note\n") {
+		t.Errorf("the commit was not pushed:\n%s", log)
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "sync", "--format", "json")
+	var result struct {
+		Committed bool     `json:"committed"`
+		Pulled    []string `json:"pulled"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if result.Committed || len(result.Pulled) != 0 {
+		t.Errorf("a second sync should do nothing: %s", stdout)
+	}
+}
+
+func TestCLI_ImportObsidian(t *testing.T) {
+	env := newTestEnv(t)
+
+	vault := filepath.Join(env.tmpDir, "vault")
+	writeVault := func(rel, content string) {
+		t.Helper()
+		p := filepath.Join(vault, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeVault(".obsidian/app.json", `{"attachmentFolderPath": "assets"}`)
+	writeVault("assets/diagram.png", "png")
+	writeVault("a/Plan.md", "Plan A\n")
+	writeVault("b/Plan.md", "Plan B\n")
+	writeVault("b/Launch.md", "---\naliases: Go live\n---\nSee [[Plan]] and [[Missing]] #launch\n![[diagram.png|300]]\n")
+
+	stdout, stderr, exitCode := env.runInDir(env.tmpDir, "import", "obsidian", vault, "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("import --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	for _, want := range []string{
+		"b/Launch.md: set tags, aliases, title, created, modified; tags #launch; 1 links rewritten; 1 attachment links",
+		"unresolved link [[Missing]]",
+		"Dry run: nothing was written.",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("dry run output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(vault, ".jot.json")); err == nil {
+		t.Fatal("dry run must not create a notebook")
+	}
+
+	target := filepath.Join(env.tmpDir, "notes")
+	stdout, stderr, exitCode = env.runInDir(env.tmpDir, "import", "obsidian", vault, "--to", target, "--name", "Team")
+	if exitCode != 0 {
+		t.Fatalf("import failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Imported into notebook 'Team'") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+	launch, err := os.ReadFile(filepath.Join(target, ".notes", "b", "Launch.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"  - launch\n", "  - Go live\n", "[[b/Plan|Plan]]", "![diagram.png](../assets/diagram.png)"} {
+		if !strings.Contains(string(launch), want) {
+			t.Errorf("imported note should contain %q, got:\n%s", want, launch)
+		}
+	}
+
+	// jot resolves the rewritten link to the note Obsidian showed
+	stdout, stderr, exitCode = env.runInDir(target, "notes", "show", "b/Plan.md", "--json")
+	if exitCode != 0 {
+		t.Fatalf("notes show failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, `"path": "b/Launch.md"`) {
+		t.Errorf("b/Plan.md should have a backlink from b/Launch.md, got:\n%s", stdout)
+	}
+}
+
+func TestCLI_ImportENEX(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("enex-test")
+	export := filepath.Join(env.tmpDir, "Work.enex")
+	enex := `<?xml version="1.0" encoding="UTF-8"?>
+<en-export>
+<note><title>Weekly Sync</title><created>20230105T101500Z</created><updated>20230106T120000Z</updated><tag>meetings</tag>
+<content><![CDATA[<en-note><div>Notes&nbsp;from <b>Monday</b></div><div><en-todo checked="false"/>Send minutes</div></en-note>]]></content>
+</note>
+</en-export>
+`
+	if err := os.WriteFile(export, []byte(enex), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "import", "enex", export, "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("import enex --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "new       evernote/work/weekly-sync.md") || !strings.Contains(stdout, "Dry run: nothing was written.") {
+		t.Errorf("unexpected dry run output:\n%s", stdout)
+	}
+	notePath := filepath.Join(notebookDir, ".notes", "evernote", "work", "weekly-sync.md")
+	if _, err := os.Stat(notePath); err == nil {
+		t.Fatal("dry run must not write the note")
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "import", "enex", export)
+	if exitCode != 0 {
+		t.Fatalf("import enex failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "1 new, 0 updated, 0 unchanged, 0 skipped") {
+		t.Errorf("unexpected import output:\n%s", stdout)
+	}
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"created: \"2023-01-05T10:15:00Z\"", "  - meetings\n", "source_id: evernote:", "Notes from **Monday**\n- [ ] Send minutes\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("imported note should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	stdout, _, _ = env.runInDir(notebookDir, "import", "enex", export, "--format", "json")
+	var plan struct {
+		Notes []struct {
+			Path   string `json:"path"`
+			Status string `json:"status"`
+		} `json:"notes"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &plan); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(plan.Notes) != 1 || plan.Notes[0].Status != "unchanged" {
+		t.Errorf("importing again should change nothing: %s", stdout)
+	}
+}
+
+func TestCLI_ImportRows(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("rows-test")
+	csvFile := filepath.Join(env.tmpDir, "customers.csv")
+	writeCSV := func(content string) {
+		if err := os.WriteFile(csvFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeCSV("id,name,zip,seats\n7,Acme Corp,02134,5\n")
+	args := []string{"import", "csv", csvFile, "--path", "customers/{{ .slug }}.md", "--title-column", "name", "--key", "id"}
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, args...)
+	if exitCode != 0 {
+		t.Fatalf("import csv failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "new       customers/acme-corp.md") {
+		t.Errorf("unexpected import output:\n%s", stdout)
+	}
+	notePath := filepath.Join(notebookDir, ".notes", "customers", "acme-corp.md")
+	content, err := os.ReadFile(notePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"id: 7\n", "seats: 5\n", "zip: \"02134\"\n", "title: Acme Corp\n", "# Acme Corp\n"} {
+		if !strings.Contains(string(content), want) {
+			t.Errorf("imported note should contain %q, got:\n%s", want, content)
+		}
+	}
+
+	writeCSV("id,name,zip,seats\n7,Acme Corp,02134,9\n")
+	stdout, stderr, exitCode = env.runInDir(notebookDir, append(args, "--dry-run")...)
+	if exitCode != 0 {
+		t.Fatalf("import csv --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "updated   customers/acme-corp.md") || !strings.Contains(stdout, "+seats: 9") {
+		t.Errorf("dry run should show the update:\n%s", stdout)
+	}
+	if content, _ := os.ReadFile(notePath); !strings.Contains(string(content), "seats: 5\n") {
+		t.Error("dry run must not change the note")
+	}
+
+	jsonFile := filepath.Join(env.tmpDir, "incidents.json")
+	if err := os.WriteFile(jsonFile, []byte(`[{"id": 3, "title": "Outage", "year": 2024}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, stderr, exitCode = env.runInDir(notebookDir, "import", "json", jsonFile, "--path", "incidents/{{ .year }}/{{ .id }}-{{ .slug }}.md")
+	if exitCode != 0 {
+		t.Fatalf("import json failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if _, err := os.Stat(filepath.Join(notebookDir, ".notes", "incidents", "2024", "3-outage.md")); err != nil {
+		t.Errorf("import json should create the note: %v", err)
+	}
+}
+
+func TestCLI_ExportRecords(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("records-test")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\ntags: [work]\nstatus: open\n---\n# Plan\n")
+	env.createNote(notebookDir, "diary.md", "---\ntitle: Diary\n---\n# Diary\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "export", "jsonl", "--fields", "path,title,status,tags")
+	if exitCode != 0 {
+		t.Fatalf("export jsonl failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	want := `{"path":"diary.md","title":"Diary","status":null,"tags":[]}` + "\n" +
+		`{"path":"plan.md","title":"Plan","status":"open","tags":["work"]}` + "\n"
+	if stdout != want {
+		t.Errorf("unexpected jsonl output:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "export", "csv", "--where", "tag:work", "--fields", "title,tags")
+	if exitCode != 0 {
+		t.Fatalf("export csv failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if want := "title,tags\nPlan,\"[\"\"work\"\"]\"\n"; stdout != want {
+		t.Errorf("unexpected csv output:\n%s", stdout)
+	}
+}
+
+func TestCLI_NotebookExportImport(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("bundle-test")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\ntags: [work]\n---\n# Plan\n")
+	env.createNote(notebookDir, "img/a.png", "png")
+
+	bundle := filepath.Join(env.tmpDir, "bundle.jotpack")
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notebook", "export", bundle)
+	if exitCode != 0 {
+		t.Fatalf("notebook export failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Notes: 1") || !strings.Contains(stdout, "Files: 2") {
+		t.Errorf("unexpected export output:\n%s", stdout)
+	}
+
+	target := filepath.Join(env.tmpDir, "restored")
+	stdout, stderr, exitCode = env.runInDir(env.tmpDir, "notebook", "import", bundle, target)
+	if exitCode != 0 {
+		t.Fatalf("notebook import failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Imported notebook") {
+		t.Errorf("unexpected import output:\n%s", stdout)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(target, "notes", "list")
+	if exitCode != 0 {
+		t.Fatalf("notes list failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "plan.md") {
+		t.Errorf("restored notebook should list its notes, got:\n%s", stdout)
+	}
+
+	_, stderr, exitCode = env.runInDir(env.tmpDir, "notebook", "import", bundle, target)
+	if exitCode == 0 || !strings.Contains(stderr, "is not empty") {
+		t.Errorf("importing into a non-empty directory should fail, got exit %d, stderr: %s", exitCode, stderr)
+	}
+}
+
+func TestCLI_ExportHTML(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("html-test")
+	env.createNote(notebookDir, "plan.md", "---\ntitle: Plan\ntags: [work]\n---\n# Plan\n\nSee [[Ideas]] and [[Diary]].\n")
+	env.createNote(notebookDir, "ideas.md", "---\ntitle: Ideas\n---\n# Ideas\n")
+	env.createNote(notebookDir, "diary.md", "---\ntitle: Diary\ntags: [private]\n---\n# Diary\n")
+
+	site := filepath.Join(env.tmpDir, "site")
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "export", "html", site, "--where", "-tag:private")
+	if exitCode != 0 {
+		t.Fatalf("export html failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Exported 2 notes, 1 tags") {
+		t.Errorf("unexpected export output:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(site, "diary.html")); err == nil {
+		t.Error("private notes must not be published")
+	}
+
+	plan, err := os.ReadFile(filepath.Join(site, "plan.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(plan), `<a href="ideas.html">Ideas</a> and Diary.`) {
+		t.Errorf("links should point to published pages only, got:\n%s", plan)
+	}
+	ideas, err := os.ReadFile(filepath.Join(site, "ideas.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(ideas), `<a href="plan.html">Plan</a>`) {
+		t.Errorf("ideas page should list its backlink, got:\n%s", ideas)
+	}
+	for _, file := range []string{"index.html", "_tags/work.html", "search-index.json", "style.css"} {
+		if _, err := os.Stat(filepath.Join(site, file)); err != nil {
+			t.Errorf("export should write %s: %v", file, err)
+		}
+	}
+}
+
+func TestCLI_NotesMove_RewritesLinks(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("move-test")
+	env.createNote(notebookDir, "index.md", "# Index\n\nSee [todo](todo.md) and [[todo]].\n")
+	env.createNote(notebookDir, "todo.md", "# Todo\n\nBack to [index](index.md).\n")
+
+	stdout, stderr, exitCode := env.runInDir(notebookDir, "notes", "move", "todo.md", "tasks/", "--dry-run")
+	if exitCode != 0 {
+		t.Fatalf("notes move --dry-run failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "+See [todo](tasks/todo.md) and [[todo]].") {
+		t.Errorf("dry run should show the link diff, got:\n%s", stdout)
+	}
+	if _, err := os.Stat(filepath.Join(notebookDir, ".notes", "todo.md")); err != nil {
+		t.Fatalf("dry run must not move the note: %v", err)
+	}
+
+	stdout, stderr, exitCode = env.runInDir(notebookDir, "notes", "mv", "todo.md", "tasks/")
+	if exitCode != 0 {
+		t.Fatalf("notes mv failed with exit code %d, stderr: %s", exitCode, stderr)
+	}
+	if !strings.Contains(stdout, "Moved note: todo.md -> tasks/todo.md") {
+		t.Errorf("unexpected output: %s", stdout)
+	}
+
+	index, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), "[todo](tasks/todo.md)") {
+		t.Errorf("link was not rewritten: %s", index)
+	}
+
+	moved, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "tasks", "todo.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(moved), "[index](../index.md)") {
+		t.Errorf("relative link in moved note was not adjusted: %s", moved)
+	}
+}
+
+func TestCLI_NotesMove_BulkCollisionMovesNothing(t *testing.T) {
+	env := newTestEnv(t)
+
+	notebookDir := env.createNotebook("move-bulk-test")
+	env.createNote(notebookDir, "a/todo.md", "---\ntags: [done]\n---\n# A\n")
+	env.createNote(notebookDir, "b/todo.md", "---\ntags: [done]\n---\n# B\n")
+	env.createNote(notebookDir, "index.md", "[a](a/todo.md) [b](b/todo.md)\n")
+
+	for _, args := range [][]string{
+		{"notes", "move", "--where", "tag:done", "archive/", "--dry-run"},
+		{"notes", "move", "--where", "tag:done", "archive/"},
+	} {
+		_, stderr, exitCode := env.runInDir(notebookDir, args...)
+		if exitCode == 0 || !strings.Contains(stderr, "would both move to archive/todo.md") {
+			t.Errorf("%v should fail before moving, got exit %d, stderr: %s", args, exitCode, stderr)
+		}
+	}
+	for _, file := range []string{"a/todo.md", "b/todo.md"} {
+		if _, err := os.Stat(filepath.Join(notebookDir, ".notes", file)); err != nil {
+			t.Errorf("%s must not be moved: %v", file, err)
+		}
+	}
+	index, err := os.ReadFile(filepath.Join(notebookDir, ".notes", "index.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(index) != "[a](a/todo.md) [b](b/todo.md)\n" {
+		t.Errorf("links must not be rewritten, got: %s", index)
+	}
+}
+
 // === Advanced Scenarios ===
 
 func TestCLI_NestedMarkdownFiles(t *testing.T) {